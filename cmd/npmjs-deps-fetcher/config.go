@@ -24,6 +24,16 @@ type config struct {
 	// NPM configures the client to communicate with the NPM registry.
 	NPM npm.ClientConfig `json:"npm"`
 
+	// CircuitBreaker configures the circuit breaker around the requests to the NPM registry.
+	CircuitBreaker npm.CircuitBreakerConfig `json:"circuitBreaker"`
+
+	// Dependents configures the reverse-dependency index of the fetched packages.
+	Dependents struct {
+		// MaxVersions is the maximum number of indexed package versions, the least recently
+		// fetched ones being evicted. The index is not bounded when it is zero or less.
+		MaxVersions int `json:"maxVersions"`
+	} `json:"dependents"`
+
 	// Server is the HTTP server related configuration.
 	Server struct {
 		// Addr is the bind address that the server will listen on.
@@ -35,6 +45,9 @@ type config struct {
 		// WriteTimeout is the maximum duration before timing out
 		// writes of the response.
 		WriteTimeout time.Duration `json:"writeTimeout"`
+		// MaxBatchSize is the maximum number of items of a batch request, like the packages
+		// of a batch resolution or the versions to check of a semver request.
+		MaxBatchSize int `json:"maxBatchSize"`
 	} `json:"server"`
 }
 
@@ -44,8 +57,22 @@ func parseConfig() (cfg *config, errs error) {
 	viper.SetConfigType("json")
 
 	viper.SetDefault("npm.timeout", "15s")
+	viper.SetDefault("npm.retry.maxAttempts", 3)
+	viper.SetDefault("npm.retry.initialBackoff", "200ms")
+	viper.SetDefault("npm.retry.maxBackoff", "5s")
+	viper.SetDefault("npm.retry.maxElapsed", "25s")
+	viper.SetDefault("npm.rateLimit.requestsPerSecond", 100)
+	viper.SetDefault("npm.rateLimit.burst", 100)
+	viper.SetDefault("circuitBreaker.failureRate", 0.5)
+	viper.SetDefault("circuitBreaker.windowSize", 20)
+	viper.SetDefault("circuitBreaker.minRequests", 10)
+	viper.SetDefault("circuitBreaker.openTimeout", "30s")
+	viper.SetDefault("circuitBreaker.halfOpenRequests", 1)
+	viper.SetDefault("circuitBreaker.cacheBytes", 64<<20)
+	viper.SetDefault("dependents.maxVersions", 50000)
 	viper.SetDefault("server.readHeaderTimeout", "10s")
 	viper.SetDefault("server.writeTimeout", "30s")
+	viper.SetDefault("server.maxBatchSize", 100)
 
 	if err := viper.ReadInConfig(); err != nil {
 		var errNotFound viper.ConfigFileNotFoundError
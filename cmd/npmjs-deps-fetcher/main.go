@@ -34,19 +34,38 @@ func run() error {
 	}))
 	slog.SetDefault(log)
 
-	client, err := npm.NewClient(cfg.NPM)
+	dependents := npm.NewDependentsIndex(cfg.Dependents.MaxVersions)
+	client, err := npm.NewRouter(cfg.NPM, cfg.CircuitBreaker, npm.ClientOptionDependentsIndex(dependents))
 	if err != nil {
 		return fmt.Errorf("create NPM client: %w", err)
 	}
 	resolver := npm.NewResolver(client)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("GET /healthcheck", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
-	mux.HandleFunc("GET /package/{packageName}/{packageVersion}", handler.PackageVersion(log.Handler(), resolver))
+	mux.HandleFunc("GET /healthcheck", handler.Healthcheck(log.Handler(), client))
+	mux.HandleFunc("GET /debug/ratelimit", handler.RateLimit(log.Handler(), client))
+	mux.HandleFunc("GET /package/{packageName}/dependents", handler.PackageDependents(log.Handler(), dependents))
+	mux.HandleFunc("GET /package/{packageName}/versions", handler.PackageVersions(log.Handler(), client))
+	mux.HandleFunc("GET /package/{packageName}/{packageVersion}", handler.PackageVersion(log.Handler(), resolver, resolver))
+	mux.HandleFunc("GET /package/{packageName}/{packageVersion}/diff/{targetVersion}", handler.PackageDiff(log.Handler(), resolver))
+	mux.HandleFunc("GET /package/{packageName}/{packageVersion}/outdated", handler.PackageOutdated(log.Handler(), resolver))
+	mux.HandleFunc("GET /package/{packageName}/{packageVersion}/progress", handler.PackageProgress(log.Handler(), resolver))
+	mux.HandleFunc("GET /package/{packageName}/{packageVersion}/why", handler.PackageWhy(log.Handler(), resolver))
+	mux.HandleFunc("POST /packages/resolve", handler.PackagesResolve(log.Handler(), resolver, cfg.Server.MaxBatchSize))
+	mux.HandleFunc("POST /semver/satisfies", handler.SemverSatisfies(log.Handler(), cfg.Server.MaxBatchSize))
+	mux.HandleFunc("POST /semver/satisfying", handler.SemverSatisfying(log.Handler(), cfg.Server.MaxBatchSize))
+	mux.HandleFunc("POST /semver/ranges", handler.SemverRanges(log.Handler(), cfg.Server.MaxBatchSize))
+	mux.HandleFunc("POST /semver/compare", handler.SemverCompare(log.Handler(), cfg.Server.MaxBatchSize))
+	mux.HandleFunc("POST /semver/sort", handler.SemverSort(log.Handler(), cfg.Server.MaxBatchSize))
+	mux.HandleFunc("POST /manifest", handler.Manifest(log.Handler(), resolver))
+	mux.HandleFunc("POST /manifest/outdated", handler.ManifestOutdated(log.Handler(), resolver))
+	mux.HandleFunc("POST /lockfile", handler.Lockfile(log.Handler()))
+	mux.HandleFunc("POST /lockfile/drift", handler.LockfileDrift(log.Handler(), resolver))
+	mux.HandleFunc("POST /lockfile/why", handler.LockfileWhy(log.Handler()))
 
 	srv := http.Server{
 		Addr:              cfg.Server.Addr,
-		Handler:           mux,
+		Handler:           handler.RequestID(mux),
 		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
 		WriteTimeout:      cfg.Server.WriteTimeout,
 	}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// maxBatchRequestSize is the maximum size in bytes of the request body of a batch endpoint.
+const maxBatchRequestSize = 1 << 20
+
+type (
+	// batchSizer is the request body of a batch endpoint, holding a list of items.
+	batchSizer interface {
+		// size returns the number of items of the batch.
+		size() int
+	}
+
+	// batchRequest is the request body of the batch resolution endpoint.
+	batchRequest struct {
+		Packages []batchPackage `json:"packages"`
+	}
+
+	// batchPackage is a package to resolve in a batch.
+	batchPackage struct {
+		Name       string `json:"name"`
+		Constraint string `json:"constraint"`
+	}
+
+	// batchResponse is the response body of the batch resolution endpoint.
+	batchResponse struct {
+		Results []batchResult `json:"results"`
+	}
+
+	// batchResult is the outcome of a package of a batch, with either its resolved graph or its error,
+	// described by the problem details of a failed single package request.
+	batchResult struct {
+		batchPackage
+		Graph   *npm.Graph `json:"graph,omitempty"`
+		Error   string     `json:"error,omitempty"`
+		Problem *problem   `json:"problem,omitempty"`
+	}
+)
+
+// PackagesResolve is the [http.HandlerFunc] for POST /packages/resolve.
+// It resolves the dependency graphs of a list of packages and version constraints concurrently,
+// responding with a result for every package, in the same order, holding either its graph or its error.
+// Batches of more than maxBatchSize packages are rejected.
+func PackagesResolve(logHandler slog.Handler, resolver BatchResolver, maxBatchSize int) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var batch batchRequest
+		if !decodeBatchRequest(w, req, log, &batch, maxBatchSize) {
+			return
+		}
+
+		resp := batchResponse{Results: make([]batchResult, len(batch.Packages))}
+		queries := make([]npm.PackageQuery, 0, len(batch.Packages))
+		indexes := make([]int, 0, len(batch.Packages))
+		for i, pkg := range batch.Packages {
+			resp.Results[i].batchPackage = pkg
+
+			if pkg.Name == "" {
+				resp.Results[i].fail(problem{typ: problemInvalidBody, Detail: "missing package name"})
+				continue
+			}
+			constraint, err := semver.NewConstraint(pkg.Constraint)
+			if err != nil {
+				resp.Results[i].fail(problem{
+					typ: problemInvalidConstraint, Detail: "invalid version constraint: " + err.Error(),
+					Package: pkg.Name, Range: pkg.Constraint,
+				})
+				continue
+			}
+
+			queries = append(queries, npm.PackageQuery{Name: pkg.Name, Constraint: constraint})
+			indexes = append(indexes, i)
+		}
+
+		for i, result := range resolver.ResolveBatch(ctx, queries) {
+			r := &resp.Results[indexes[i]]
+			if result.Err != nil {
+				log.Debug("batch package resolution error", slog.String("name", queries[i].Name),
+					slog.String("error", result.Err.Error()))
+				r.fail(resolutionProblem(result.Err, r.Name, r.Constraint))
+				r.Error = result.Err.Error()
+				continue
+			}
+			r.Graph = result.Graph
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Error("batch encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+			return
+		}
+	}
+}
+
+func (b *batchRequest) size() int { return len(b.Packages) }
+
+// fail records the problem of the result, its detail being the error of the result.
+func (r *batchResult) fail(p problem) {
+	p = p.typed()
+	r.Problem, r.Error = &p, p.Detail
+}
+
+// decodeBatchRequest decodes the JSON request body of a batch endpoint, writing an error response
+// and returning false when it is invalid, or when it holds more than maxBatchSize items.
+func decodeBatchRequest(w http.ResponseWriter, req *http.Request, log *slog.Logger, batch batchSizer, maxBatchSize int) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxBatchRequestSize)).Decode(batch)
+	if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+		log.Debug("batch request too large", slog.Int64("limit", maxBytesErr.Limit))
+		writeProblem(w, req, log, problem{typ: problemBodyTooLarge, Detail: "batch too large"})
+		return false
+	}
+
+	if err != nil {
+		log.Debug("invalid batch request", slog.String("error", err.Error()))
+		writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: "invalid batch request: " + err.Error()})
+		return false
+	}
+
+	if batch.size() > maxBatchSize {
+		log.Debug("batch too large", slog.Int("size", batch.size()), slog.Int("limit", maxBatchSize))
+		writeProblem(w, req, log, problem{typ: problemBodyTooLarge, Detail: "batch too large"})
+		return false
+	}
+
+	return true
+}
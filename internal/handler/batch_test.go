@@ -0,0 +1,128 @@
+package handler_test
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+func TestPackagesResolve(t *testing.T) {
+	testCases := []struct {
+		name               string
+		setup              func(testing.TB) (*http.Request, handler.BatchResolver)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "invalid batch request",
+			setup: func(tb testing.TB) (*http.Request, handler.BatchResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/packages/resolve", strings.NewReader(`{"packages":{}}`))
+
+				return req, mockshandler.NewMockBatchResolver(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"invalid batch request: json: cannot unmarshal object into Go struct field batchRequest.packages of type []handler.batchPackage\",\"code\":\"invalid-body\"}\n",
+		},
+		{
+			name: "batch too large",
+			setup: func(tb testing.TB) (*http.Request, handler.BatchResolver) {
+				tb.Helper()
+
+				body := `{"packages":[{"name":"a","constraint":"*"},{"name":"b","constraint":"*"},{"name":"c","constraint":"*"},{"name":"d","constraint":"*"}]}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/packages/resolve", strings.NewReader(body))
+
+				return req, mockshandler.NewMockBatchResolver(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:body-too-large\",\"title\":\"Request body too large\",\"status\":413,\"detail\":\"batch too large\",\"code\":\"body-too-large\"}\n",
+		},
+		{
+			name: "batch request body too large",
+			setup: func(tb testing.TB) (*http.Request, handler.BatchResolver) {
+				tb.Helper()
+
+				body := `{"packages":[{"name":"` + strings.Repeat("a", 1<<20) + `"}]}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/packages/resolve", strings.NewReader(body))
+
+				return req, mockshandler.NewMockBatchResolver(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:body-too-large\",\"title\":\"Request body too large\",\"status\":413,\"detail\":\"batch too large\",\"code\":\"body-too-large\"}\n",
+		},
+		{
+			name: "per package results",
+			setup: func(tb testing.TB) (*http.Request, handler.BatchResolver) {
+				tb.Helper()
+
+				body := `{"packages":[{"name":"foo","constraint":"^1.0.0"},{"name":"bar","constraint":"latest"},` +
+					`{"name":"baz","constraint":"2.x"}]}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/packages/resolve", strings.NewReader(body))
+
+				resolver := mockshandler.NewMockBatchResolver(gomock.NewController(tb))
+				resolver.EXPECT().ResolveBatch(gomock.Any(), gomock.Len(2)).DoAndReturn(
+					func(_ any, queries []npm.PackageQuery) []npm.BatchResult {
+						assert.Equal(tb, "foo", queries[0].Name)
+						assert.Equal(tb, "^1.0.0", queries[0].Constraint.String())
+						assert.Equal(tb, "baz", queries[1].Name)
+						assert.Equal(tb, "2.x", queries[1].Constraint.String())
+
+						return []npm.BatchResult{
+							{Graph: &npm.Graph{
+								Root:  "foo@1.2.0",
+								Nodes: map[string]*npm.Node{"foo@1.2.0": {Name: "foo", Version: "1.2.0"}},
+							}},
+							{Err: fmt.Errorf("resolve highest version of qux@^9.0.0: %w", &semverutil.NoMatchingVersionError{
+								Package: "qux", Range: "^9.0.0", Constraint: "^9.0.0", Candidates: 2,
+							})},
+						}
+					})
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"results":[` +
+				`{"name":"foo","constraint":"^1.0.0","graph":{"root":"foo@1.2.0","nodes":{"foo@1.2.0":{"name":"foo","version":"1.2.0"}}}},` +
+				`{"name":"bar","constraint":"latest","error":"invalid version constraint: improper constraint: latest",` +
+				`"problem":{"type":"urn:npmjs-deps-fetcher:problem:invalid-constraint","title":"Invalid version constraint",` +
+				`"status":400,"detail":"invalid version constraint: improper constraint: latest","code":"invalid-constraint",` +
+				`"package":"bar","range":"latest"}},` +
+				`{"name":"baz","constraint":"2.x","error":"resolve highest version of qux@^9.0.0: no compatible versions found ` +
+				`for ^9.0.0 among 2 versions","problem":{"type":"urn:npmjs-deps-fetcher:problem:no-matching-version",` +
+				`"title":"No matching version","status":404,"detail":"resolve highest version of qux@^9.0.0: no compatible ` +
+				`versions found for ^9.0.0 among 2 versions","code":"no-matching-version","package":"qux","range":"^9.0.0"}}]}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, resolver := tc.setup(t)
+
+			h := handler.PackagesResolve(slog.DiscardHandler, resolver, 3)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+// dependentsResponse is the response body of the dependents endpoint.
+type dependentsResponse struct {
+	Dependents []npm.Dependent `json:"dependents"`
+}
+
+// PackageDependents is the [http.HandlerFunc] for GET /package/{package}/dependents.
+// The optional range query parameter only keeps the dependents declaring a version range
+// intersecting it, like "4.17.20" for the dependents that could install this version.
+func PackageDependents(logHandler slog.Handler, index DependentsIndex) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		pkgName := req.PathValue("packageName")
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var dependents []npm.Dependent
+		if r := req.URL.Query().Get("range"); r != "" {
+			rng, err := semverutil.ParseRange(r)
+			if err != nil {
+				log.Debug("invalid range", slog.String("error", err.Error()))
+				writeProblem(w, req, log, problem{
+					typ: problemInvalidRange, Detail: err.Error(), Package: pkgName, Range: r,
+				})
+				return
+			}
+			dependents = index.DependentsInRange(pkgName, rng)
+		} else {
+			dependents = index.Dependents(pkgName)
+		}
+
+		if err := json.NewEncoder(w).Encode(dependentsResponse{Dependents: dependents}); err != nil {
+			log.Error("dependents encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestPackageDependents(t *testing.T) {
+	testCases := []struct {
+		name               string
+		setup              func(testing.TB) (*http.Request, handler.DependentsIndex)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "invalid range",
+			setup: func(tb testing.TB) (*http.Request, handler.DependentsIndex) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/lodash/dependents?range=latest", http.NoBody)
+				req.SetPathValue("packageName", "lodash")
+				return req, mockshandler.NewMockDependentsIndex(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-range\",\"title\":\"Invalid version range\",\"status\":400,\"detail\":\"range latest: invalid version \\\"latest\\\": strconv.ParseUint: parsing \\\"latest\\\": invalid syntax\",\"code\":\"invalid-range\",\"package\":\"lodash\",\"range\":\"latest\"}\n",
+		},
+		{
+			name: "all dependents",
+			setup: func(tb testing.TB) (*http.Request, handler.DependentsIndex) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/lodash/dependents", http.NoBody)
+				req.SetPathValue("packageName", "lodash")
+				index := mockshandler.NewMockDependentsIndex(gomock.NewController(tb))
+				index.EXPECT().Dependents("lodash").Return([]npm.Dependent{})
+				return req, index
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "{\"dependents\":[]}\n",
+		},
+		{
+			name: "dependents in range",
+			setup: func(tb testing.TB) (*http.Request, handler.DependentsIndex) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/lodash/dependents?range=4.17.20", http.NoBody)
+				req.SetPathValue("packageName", "lodash")
+				index := mockshandler.NewMockDependentsIndex(gomock.NewController(tb))
+				index.EXPECT().DependentsInRange("lodash", gomock.Any()).Return([]npm.Dependent{
+					{Name: "express", Version: "4.18.0", Constraint: "^4.17.0"},
+				})
+				return req, index
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "{\"dependents\":[{\"name\":\"express\",\"version\":\"4.18.0\",\"constraint\":\"^4.17.0\"}]}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, index := tc.setup(t)
+
+			h := handler.PackageDependents(slog.DiscardHandler, index)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
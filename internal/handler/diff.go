@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// diffPathLimit caps the number of dependency paths reported for every change of the diff endpoint.
+const diffPathLimit = 10
+
+// diffResponse is the response body of the diff endpoint.
+type diffResponse struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Changes []npm.Change `json:"changes"`
+}
+
+// PackageDiff is the [http.HandlerFunc] for GET /package/{package}/{version}/diff/{targetVersion}.
+// It compares the dependency graphs of the package resolved at both versions.
+func PackageDiff(logHandler slog.Handler, resolver GraphResolver) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		pkgName := req.PathValue("packageName")
+		pkgVersion, targetVersion := req.PathValue("packageVersion"), req.PathValue("targetVersion")
+
+		w.Header().Set("Content-Type", "application/json")
+
+		constraint, err := semver.NewConstraint(pkgVersion)
+		if err != nil {
+			log.Debug("invalid version constraint", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{
+				typ: problemInvalidConstraint, Detail: err.Error(), Package: pkgName, Range: pkgVersion,
+			})
+			return
+		}
+
+		targetConstraint, err := semver.NewConstraint(targetVersion)
+		if err != nil {
+			log.Debug("invalid target version constraint", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{
+				typ: problemInvalidConstraint, Detail: err.Error(), Package: pkgName, Range: targetVersion,
+			})
+			return
+		}
+
+		graphs, err := resolver.ResolvePackageGraphs(ctx, pkgName, constraint, targetConstraint)
+		if err != nil {
+			writeResolutionProblem(w, req, log, err, pkgName, "")
+			return
+		}
+
+		from, to := graphs[0], graphs[1]
+		resp := diffResponse{
+			From:    from.RootNode().Version,
+			To:      to.RootNode().Version,
+			Changes: npm.DiffGraphs(from, to, diffPathLimit),
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Error("diff encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+			return
+		}
+	}
+}
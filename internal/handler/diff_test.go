@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestPackageDiff(t *testing.T) {
+	testCases := []struct {
+		name               string
+		targetVersion      string
+		setup              func(testing.TB) handler.GraphResolver
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:          "invalid target version constraint",
+			targetVersion: "latest",
+			setup: func(tb testing.TB) handler.GraphResolver {
+				tb.Helper()
+				return mockshandler.NewMockGraphResolver(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-constraint\",\"title\":\"Invalid version constraint\",\"status\":400,\"detail\":\"improper constraint: latest\",\"code\":\"invalid-constraint\",\"package\":\"app\",\"range\":\"latest\"}\n",
+		},
+		{
+			name:          "package not found",
+			targetVersion: "2.0.0",
+			setup: func(tb testing.TB) handler.GraphResolver {
+				tb.Helper()
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(tb))
+				resolver.EXPECT().ResolvePackageGraphs(gomock.Any(), "app", gomock.Any(), gomock.Any()).Return(nil, npm.ErrPackageNotFound)
+				return resolver
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:package-not-found\",\"title\":\"Package not found\",\"status\":404,\"detail\":\"package not found\",\"code\":\"package-not-found\",\"package\":\"app\"}\n",
+		},
+		{
+			name:          "resolution error",
+			targetVersion: "2.0.0",
+			setup: func(tb testing.TB) handler.GraphResolver {
+				tb.Helper()
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(tb))
+				resolver.EXPECT().ResolvePackageGraphs(gomock.Any(), "app", gomock.Any(), gomock.Any()).Return(nil, errors.New("something bad happened"))
+				return resolver
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:internal-error\",\"title\":\"Internal server error\",\"status\":500,\"code\":\"internal-error\",\"package\":\"app\"}\n",
+		},
+		{
+			name:          "successful diff",
+			targetVersion: "2.0.0",
+			setup: func(tb testing.TB) handler.GraphResolver {
+				tb.Helper()
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(tb))
+				resolver.EXPECT().ResolvePackageGraphs(gomock.Any(), "app", gomock.Any(), gomock.Any()).Return([]*npm.Graph{
+					npm.NewGraph(&npm.Node{Name: "app", Version: "1.0.0"}),
+					{
+						Root: "app@2.0.0",
+						Nodes: map[string]*npm.Node{
+							"app@2.0.0": {
+								Name: "app", Version: "2.0.0",
+								Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"}},
+							},
+							"foo@1.2.0": {Name: "foo", Version: "1.2.0"},
+						},
+					},
+				}, nil)
+				return resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"from":"1.0.0","to":"2.0.0","changes":[{"kind":"added","name":"foo","to":"1.2.0",` +
+				`"paths":[[{"name":"app","version":"2.0.0"},{"name":"foo","version":"1.2.0","constraint":"^1.0.0"}]]}]}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/diff/"+tc.targetVersion, http.NoBody)
+			req.SetPathValue("packageName", "app")
+			req.SetPathValue("packageVersion", "1.0.0")
+			req.SetPathValue("targetVersion", tc.targetVersion)
+
+			h := handler.PackageDiff(slog.DiscardHandler, tc.setup(t))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
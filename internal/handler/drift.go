@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// driftManifestOptions selects every dependency type of the manifest, since a lockfile records them all.
+var driftManifestOptions = npm.ManifestOptions{Dev: true, Optional: true}
+
+// driftResponse is the response body of the lockfile drift endpoint.
+type driftResponse struct {
+	Drifts  []npm.Drift             `json:"drifts"`
+	Skipped []npm.SkippedDependency `json:"skipped,omitempty"`
+}
+
+// LockfileDrift is the [http.HandlerFunc] for POST /lockfile/drift.
+// The request is a multipart form holding the manifest and lockfile files of a project. All the dependency types
+// of the manifest are resolved, since they are all locked. Every optional dependency is resolved on its own,
+// and reported as skipped when it cannot be resolved, like npm install ignores it.
+func LockfileDrift(logHandler slog.Handler, resolver GraphResolver) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		req.Body = http.MaxBytesReader(w, req.Body, maxManifestSize+maxLockfileSize)
+		if err := req.ParseMultipartForm(maxManifestSize); err != nil {
+			if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+				log.Debug("drift upload too large", slog.Int64("limit", maxBytesErr.Limit))
+				writeProblem(w, req, log, problem{typ: problemBodyTooLarge, Detail: "upload too large"})
+				return
+			}
+			log.Debug("invalid multipart form", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: "invalid multipart form: " + err.Error()})
+			return
+		}
+
+		manifestFile, _, err := req.FormFile("manifest")
+		if err != nil {
+			log.Debug("missing manifest", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: "missing manifest: " + err.Error()})
+			return
+		}
+		defer manifestFile.Close()
+
+		lockfileFile, _, err := req.FormFile("lockfile")
+		if err != nil {
+			log.Debug("missing lockfile", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: "missing lockfile: " + err.Error()})
+			return
+		}
+		defer lockfileFile.Close()
+
+		manifest, err := npm.ParseManifest(manifestFile, driftManifestOptions)
+		if err != nil {
+			log.Debug("invalid manifest", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: err.Error()})
+			return
+		}
+
+		locked, err := npm.ParseLockfile(lockfileFile)
+		if errors.Is(err, npm.ErrUnsupportedLockfile) {
+			log.Debug("unsupported lockfile", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemUnsupportedLockfile, Detail: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Debug("invalid lockfile", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: err.Error()})
+			return
+		}
+
+		root, optionals := manifest.Package(driftManifestOptions), manifest.Optionals(driftManifestOptions)
+		required := *root
+		required.Dependencies = maps.Clone(root.Dependencies)
+		maps.DeleteFunc(required.Dependencies, func(name, _ string) bool { _, ok := optionals[name]; return ok })
+
+		fresh, err := resolver.ResolveGraph(ctx, &required)
+		if err != nil {
+			writeResolutionProblem(w, req, log, err, "", "")
+			return
+		}
+
+		fresh.Skipped = manifest.Skipped(driftManifestOptions)
+		for _, name := range slices.Sorted(maps.Keys(optionals)) {
+			optional := &npm.Package{Name: root.Name, Version: root.Version, Dependencies: map[string]string{name: optionals[name]}}
+			graph, err := resolver.ResolveGraph(ctx, optional)
+			if err != nil {
+				if p := resolutionProblem(err, name, optionals[name]).typed(); p.Status < http.StatusInternalServerError {
+					log.Debug("optional dependency resolution error", slog.String("name", name), slog.String("error", err.Error()))
+					fresh.Skipped = append(fresh.Skipped, npm.SkippedDependency{Name: name, Spec: optionals[name], Error: p.Detail})
+					continue
+				}
+				writeResolutionProblem(w, req, log, err, "", "")
+				return
+			}
+			fresh.Merge(graph)
+		}
+		slices.SortFunc(fresh.Skipped, func(a, b npm.SkippedDependency) int { return cmp.Compare(a.Name, b.Name) })
+
+		if err := json.NewEncoder(w).Encode(driftResponse{Drifts: npm.DetectDrift(locked, fresh), Skipped: fresh.Skipped}); err != nil {
+			log.Error("drift encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+			return
+		}
+	}
+}
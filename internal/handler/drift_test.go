@@ -0,0 +1,239 @@
+package handler_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+const (
+	driftManifest = `{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0"},"devDependencies":{"bar":"^2.0.0"}}`
+	driftLockfile = `{"name":"app","version":"1.0.0","lockfileVersion":3,"packages":{` +
+		`"":{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0"},"devDependencies":{"bar":"^2.0.0"}},` +
+		`"node_modules/foo":{"version":"1.0.0"},"node_modules/bar":{"version":"2.0.0"}}}`
+)
+
+func newMultipartRequest(tb testing.TB, files map[string]string) *http.Request {
+	tb.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for field, content := range files {
+		fw, err := mw.CreateFormFile(field, field)
+		require.NoError(tb, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(tb, err)
+	}
+	require.NoError(tb, mw.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/lockfile/drift", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestLockfileDrift(t *testing.T) {
+	testCases := []struct {
+		name               string
+		setup              func(testing.TB) (*http.Request, handler.GraphResolver)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "invalid multipart form",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/lockfile/drift", bytes.NewReader([]byte(driftManifest)))
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"invalid multipart form: request Content-Type isn't multipart/form-data\",\"code\":\"invalid-body\"}\n",
+		},
+		{
+			name: "missing lockfile",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := newMultipartRequest(tb, map[string]string{"manifest": driftManifest})
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"missing lockfile: http: no such file\",\"code\":\"invalid-body\"}\n",
+		},
+		{
+			name: "unsupported lockfile",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := newMultipartRequest(tb, map[string]string{"manifest": driftManifest, "lockfile": "bun.lockb"})
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:unsupported-lockfile\",\"title\":\"Unsupported lockfile\",\"status\":415,\"detail\":\"unsupported lockfile: unknown lockfile format\",\"code\":\"unsupported-lockfile\"}\n",
+		},
+		{
+			name: "resolve graph failed",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := newMultipartRequest(tb, map[string]string{"manifest": driftManifest, "lockfile": driftLockfile})
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), gomock.Any()).Return(nil, errors.New("something bad happened"))
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:internal-error\",\"title\":\"Internal server error\",\"status\":500,\"code\":\"internal-error\"}\n",
+		},
+		{
+			name: "drift detected",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := newMultipartRequest(tb, map[string]string{"manifest": driftManifest, "lockfile": driftLockfile})
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{
+					Name:         "app",
+					Version:      "1.0.0",
+					Dependencies: map[string]string{"foo": "^1.0.0", "bar": "^2.0.0"},
+				}).Return(&npm.Graph{
+					Root: "app@1.0.0",
+					Nodes: map[string]*npm.Node{
+						"app@1.0.0": {
+							Name: "app", Version: "1.0.0",
+							Dependencies: map[string]npm.Edge{
+								"foo": {Constraint: "^1.0.0", Node: "foo@1.3.0"},
+								"bar": {Constraint: "^2.0.0", Node: "bar@2.0.0"},
+							},
+						},
+						"foo@1.3.0": {Name: "foo", Version: "1.3.0"},
+						"bar@2.0.0": {Name: "bar", Version: "2.0.0"},
+					},
+				}, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"drifts":[{"kind":"outdated","name":"foo","constraint":"^1.0.0","locked":"1.0.0","resolved":"1.3.0"}]}` + "\n",
+		},
+		{
+			name: "skipped dependencies",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				manifest := `{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0","baz":"file:../baz"}}`
+				lockfile := `{"name":"app","version":"1.0.0","lockfileVersion":3,"packages":{` +
+					`"":{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0","baz":"file:../baz"}},` +
+					`"node_modules/foo":{"version":"1.0.0"},"node_modules/baz":{"version":"0.1.0"}}}`
+				req := newMultipartRequest(tb, map[string]string{"manifest": manifest, "lockfile": lockfile})
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{
+					Name:         "app",
+					Version:      "1.0.0",
+					Dependencies: map[string]string{"foo": "^1.0.0"},
+				}).Return(&npm.Graph{
+					Root: "app@1.0.0",
+					Nodes: map[string]*npm.Node{
+						"app@1.0.0": {
+							Name: "app", Version: "1.0.0",
+							Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"}},
+						},
+						"foo@1.0.0": {Name: "foo", Version: "1.0.0"},
+					},
+				}, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"drifts":[],"skipped":[{"name":"baz","spec":"file:../baz"}]}` + "\n",
+		},
+		{
+			name: "optional dependencies",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				manifest := `{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0"},` +
+					`"optionalDependencies":{"bar":"^2.0.0","baz":"^3.0.0"}}`
+				lockfile := `{"name":"app","version":"1.0.0","lockfileVersion":3,"packages":{` +
+					`"":{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0"},"optionalDependencies":{"bar":"^2.0.0","baz":"^3.0.0"}},` +
+					`"node_modules/foo":{"version":"1.0.0"},"node_modules/bar":{"version":"2.0.0","optional":true},` +
+					`"node_modules/baz":{"version":"3.0.0","optional":true}}}`
+				req := newMultipartRequest(tb, map[string]string{"manifest": manifest, "lockfile": lockfile})
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{
+					Name: "app", Version: "1.0.0", Dependencies: map[string]string{"foo": "^1.0.0"},
+				}).Return(&npm.Graph{
+					Root: "app@1.0.0",
+					Nodes: map[string]*npm.Node{
+						"app@1.0.0": {
+							Name: "app", Version: "1.0.0",
+							Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"}},
+						},
+						"foo@1.0.0": {Name: "foo", Version: "1.0.0"},
+					},
+				}, nil)
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{
+					Name: "app", Version: "1.0.0", Dependencies: map[string]string{"bar": "^2.0.0"},
+				}).Return(nil, fmt.Errorf("resolve highest version of bar@^2.0.0: %w", &semverutil.NoMatchingVersionError{
+					Package: "bar", Range: "^2.0.0", Constraint: "^2.0.0", Candidates: 1,
+				}))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{
+					Name: "app", Version: "1.0.0", Dependencies: map[string]string{"baz": "^3.0.0"},
+				}).Return(&npm.Graph{
+					Root: "app@1.0.0",
+					Nodes: map[string]*npm.Node{
+						"app@1.0.0": {
+							Name: "app", Version: "1.0.0",
+							Dependencies: map[string]npm.Edge{"baz": {Constraint: "^3.0.0", Node: "baz@3.1.0"}},
+						},
+						"baz@3.1.0": {Name: "baz", Version: "3.1.0"},
+					},
+				}, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "{\"drifts\":[{\"kind\":\"outdated\",\"name\":\"baz\",\"constraint\":\"^3.0.0\",\"locked\":\"3.0.0\",\"resolved\":\"3.1.0\"}],\"skipped\":[{\"name\":\"bar\",\"spec\":\"^2.0.0\",\"error\":\"resolve highest version of bar@^2.0.0: no compatible versions found for ^2.0.0 among 1 versions\"}]}\n",
+		},
+		{
+			name: "optional dependency registry unavailable",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				manifest := `{"name":"app","version":"1.0.0","optionalDependencies":{"bar":"^2.0.0"}}`
+				req := newMultipartRequest(tb, map[string]string{"manifest": manifest, "lockfile": driftLockfile})
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{
+					Name: "app", Version: "1.0.0", Dependencies: map[string]string{},
+				}).Return(&npm.Graph{Root: "app@1.0.0", Nodes: map[string]*npm.Node{"app@1.0.0": {Name: "app", Version: "1.0.0"}}}, nil)
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{
+					Name: "app", Version: "1.0.0", Dependencies: map[string]string{"bar": "^2.0.0"},
+				}).Return(nil, fmt.Errorf("fetch package meta bar: %w", npm.ErrRegistryUnavailable))
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusBadGateway,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:registry-unavailable\",\"title\":\"Registry unavailable\",\"status\":502,\"detail\":\"fetch package meta bar: registry unavailable\",\"code\":\"registry-unavailable\"}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, resolver := tc.setup(t)
+
+			h := handler.LockfileDrift(slog.DiscardHandler, resolver)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	"github.com/snyk/npmjs-deps-fetcher/internal/render"
+	"github.com/snyk/npmjs-deps-fetcher/internal/sbom"
+)
+
+const (
+	// mediaTypeJSON is the media type of the default JSON responses.
+	mediaTypeJSON = "application/json"
+	// mediaTypeCycloneDX is the media type of CycloneDX JSON BOMs.
+	mediaTypeCycloneDX = "application/vnd.cyclonedx+json"
+	// mediaTypeSPDX is the media type of SPDX JSON documents.
+	mediaTypeSPDX = "application/spdx+json"
+	// mediaTypeDOT is the media type of Graphviz DOT graphs.
+	mediaTypeDOT = "text/vnd.graphviz"
+	// mediaTypeMermaid is the media type of Mermaid diagrams.
+	mediaTypeMermaid = "text/vnd.mermaid"
+	// mediaTypeGraphML is the media type of GraphML documents.
+	mediaTypeGraphML = "application/graphml+xml"
+)
+
+// format is a value of the format query parameter, with its media type.
+type format struct {
+	name      string
+	mediaType string
+}
+
+// packageFormats maps the values of the format query parameter of the package endpoint to their media type,
+// the first one being the default.
+var packageFormats = []format{
+	{name: "json", mediaType: mediaTypeJSON},
+	{name: "cyclonedx", mediaType: mediaTypeCycloneDX},
+	{name: "spdx", mediaType: mediaTypeSPDX},
+	{name: "dot", mediaType: mediaTypeDOT},
+	{name: "mermaid", mediaType: mediaTypeMermaid},
+	{name: "graphml", mediaType: mediaTypeGraphML},
+	{name: "ndjson", mediaType: mediaTypeNDJSON},
+}
+
+// graphFormats are the formats of the endpoints responding with an already resolved graph,
+// which cannot be streamed while it is resolved.
+var graphFormats = slices.DeleteFunc(slices.Clone(packageFormats), func(f format) bool {
+	return f.mediaType == mediaTypeNDJSON
+})
+
+// acceptedMediaType is a media range of an Accept header, with its quality value.
+type acceptedMediaType struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiatePackageFormat selects the media type of a package endpoint response, from the format query parameter
+// or else from the Accept header. It reports false when none of the requested formats is supported.
+func negotiatePackageFormat(req *http.Request) (string, bool) {
+	return negotiateFormat(req, packageFormats)
+}
+
+// negotiateGraphFormat selects the media type of a resolved graph response, like negotiatePackageFormat
+// without the streamed format.
+func negotiateGraphFormat(req *http.Request) (string, bool) {
+	return negotiateFormat(req, graphFormats)
+}
+
+// negotiateFormat selects the media type of a response among the formats, from the format query parameter
+// or else from the Accept header, the first format being the default.
+func negotiateFormat(req *http.Request, formats []format) (string, bool) {
+	if name := req.URL.Query().Get("format"); name != "" {
+		for _, f := range formats {
+			if f.name == name {
+				return f.mediaType, true
+			}
+		}
+		return "", false
+	}
+
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return formats[0].mediaType, true
+	}
+
+	for _, accepted := range parseAccept(accept) {
+		for _, f := range formats {
+			if accepted.mediaType == f.mediaType || accepted.mediaType == "*/*" ||
+				accepted.mediaType == strings.Split(f.mediaType, "/")[0]+"/*" {
+				return f.mediaType, true
+			}
+		}
+	}
+	return "", false
+}
+
+// parseAccept parses the media ranges of an Accept header, sorted by decreasing quality
+// and without the ones that are not acceptable.
+func parseAccept(accept string) []acceptedMediaType {
+	var accepted []acceptedMediaType
+	for mediaRange := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			accepted = append(accepted, acceptedMediaType{mediaType: mediaType, quality: quality})
+		}
+	}
+
+	slices.SortStableFunc(accepted, func(a, b acceptedMediaType) int { return cmp.Compare(b.quality, a.quality) })
+	return accepted
+}
+
+// writeGraph writes the resolved graph in the negotiated media type.
+func writeGraph(w http.ResponseWriter, req *http.Request, log *slog.Logger, graph *npm.Graph, mediaType string, opts render.Options) {
+	var (
+		b   bytes.Buffer
+		err error
+	)
+	switch mediaType {
+	case mediaTypeCycloneDX:
+		err = json.NewEncoder(&b).Encode(sbom.NewCycloneDX(graph, time.Now()))
+	case mediaTypeSPDX:
+		err = json.NewEncoder(&b).Encode(sbom.NewSPDX(graph, time.Now()))
+	case mediaTypeDOT:
+		err = render.DOT(&b, graph, opts)
+	case mediaTypeMermaid:
+		err = render.Mermaid(&b, graph, opts)
+	case mediaTypeGraphML:
+		err = render.GraphML(&b, graph, opts)
+	default:
+		err = json.NewEncoder(&b).Encode(graph)
+	}
+
+	if err != nil {
+		log.Error("graph encoding error", slog.Any("error", err))
+		writeProblem(w, req, log, problem{typ: problemInternal})
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	if _, err := b.WriteTo(w); err != nil {
+		log.Error("graph writing error", slog.Any("error", err))
+	}
+}
+
+// parseRenderOptions parses the depth query parameter of the graph visualization formats.
+func parseRenderOptions(req *http.Request) (render.Options, error) {
+	var opts render.Options
+	if d := req.URL.Query().Get("depth"); d != "" {
+		depth, err := strconv.Atoi(d)
+		if err != nil || depth < 1 {
+			return render.Options{}, errors.New("depth query parameter must be a positive integer")
+		}
+		opts.MaxDepth = depth
+	}
+	return opts, nil
+}
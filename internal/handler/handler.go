@@ -3,14 +3,13 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"errors"
-	"fmt"
 	"log/slog"
 	"net/http"
 
 	"github.com/Masterminds/semver/v3"
 
 	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
 )
 
 //go:generate go tool mockgen -destination=mocks/handler.go -source=handler.go -package mockshandler
@@ -21,8 +20,54 @@ type PackageResolver interface {
 	ResolvePackage(ctx context.Context, name string, constraint *semver.Constraints) (*npm.Package, error)
 }
 
+// OutdatedResolver reports the outdated direct dependencies of an [npm.Package] or a project.
+type OutdatedResolver interface {
+	ResolvePackageOutdated(ctx context.Context, name string, constraint *semver.Constraints) ([]npm.Outdated, error)
+	ResolveOutdated(ctx context.Context, deps, current map[string]string) ([]npm.Outdated, error)
+}
+
+// GraphResolver resolves the transitive dependencies of an [npm.Package] into an [npm.Graph],
+// either based on its name and one or several version constraints, or on its declared dependencies.
+type GraphResolver interface {
+	ResolvePackageGraph(ctx context.Context, name string, constraint *semver.Constraints) (*npm.Graph, error)
+	ResolvePackageGraphs(ctx context.Context, name string, constraints ...*semver.Constraints) ([]*npm.Graph, error)
+	ResolveGraph(ctx context.Context, root *npm.Package) (*npm.Graph, error)
+}
+
+// BatchResolver resolves the transitive dependencies of several NPM packages into an [npm.Graph] each,
+// reporting the failure of every package separately.
+type BatchResolver interface {
+	ResolveBatch(ctx context.Context, queries []npm.PackageQuery) []npm.BatchResult
+}
+
+// PackageMetaFetcher fetches the [npm.PackageMeta] metadata of an NPM package.
+type PackageMetaFetcher interface {
+	FetchPackageMeta(ctx context.Context, name string) (*npm.PackageMeta, error)
+}
+
+// RegistryHealth reports the state of the circuit breakers around the requests to the NPM registries.
+type RegistryHealth interface {
+	Status() npm.CircuitBreakerStatus
+}
+
+// RegistryRateLimit reports the activity of the rate limiter of the requests to every NPM registry host.
+type RegistryRateLimit interface {
+	RateLimitStats() map[string]npm.RateLimitStats
+}
+
+// DependentsIndex looks up the [npm.Dependent] package versions of an NPM package,
+// among the ones fetched from the registry.
+type DependentsIndex interface {
+	Dependents(name string) []npm.Dependent
+	DependentsInRange(name string, rng semverutil.Range) []npm.Dependent
+}
+
 // PackageVersion is the [http.HandlerFunc] for GET /package/{package}/{version}.
-func PackageVersion(logHandler slog.Handler, resolver PackageResolver) http.HandlerFunc {
+// It responds with the package and its resolved direct dependencies by default, or with an SBOM or a visualization
+// of its resolved graph, selected with the format query parameter or the Accept header. The depth query parameter
+// collapses the nodes of the visualizations below the given depth. The application/x-ndjson format streams
+// every node of the graph as soon as it is resolved.
+func PackageVersion(logHandler slog.Handler, resolver PackageResolver, graphResolver GraphResolver) http.HandlerFunc {
 	log := slog.New(logHandler)
 
 	return func(w http.ResponseWriter, req *http.Request) {
@@ -31,37 +76,59 @@ func PackageVersion(logHandler slog.Handler, resolver PackageResolver) http.Hand
 
 		w.Header().Set("Content-Type", "application/json")
 
+		mediaType, ok := negotiatePackageFormat(req)
+		if !ok {
+			log.Debug("unsupported format", slog.String("format", req.URL.Query().Get("format")),
+				slog.String("accept", req.Header.Get("Accept")))
+			writeProblem(w, req, log, problem{typ: problemUnsupportedFormat, Detail: "none of the requested formats is supported"})
+			return
+		}
+
+		renderOpts, err := parseRenderOptions(req)
+		if err != nil {
+			log.Debug("invalid render options", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: err.Error()})
+			return
+		}
+
 		constraint, err := semver.NewConstraint(pkgVersion)
 		if err != nil {
 			log.Debug("invalid version constraint", slog.String("error", err.Error()))
-			writeError(w, log, http.StatusBadRequest, "invalid version constraint")
+			writeProblem(w, req, log, problem{
+				typ: problemInvalidConstraint, Detail: err.Error(), Package: pkgName, Range: pkgVersion,
+			})
 			return
 		}
 
-		deps, err := resolver.ResolvePackage(ctx, pkgName, constraint)
-		if errors.Is(err, npm.ErrPackageNotFound) {
-			log.Debug("package not found", slog.String("name", pkgName), slog.String("version", pkgVersion))
-			writeError(w, log, http.StatusNotFound, "package not found")
+		if mediaType == mediaTypeNDJSON {
+			streamPackageGraph(w, req, log, graphResolver, pkgName, constraint)
 			return
 		}
 
+		var (
+			deps  *npm.Package
+			graph *npm.Graph
+		)
+		if mediaType == mediaTypeJSON {
+			deps, err = resolver.ResolvePackage(ctx, pkgName, constraint)
+		} else {
+			graph, err = graphResolver.ResolvePackageGraph(ctx, pkgName, constraint)
+		}
+
 		if err != nil {
-			log.Error("deps resolution error", slog.String("error", err.Error()))
-			writeError(w, log, http.StatusInternalServerError, "internal server error")
+			writeResolutionProblem(w, req, log, err, pkgName, pkgVersion)
+			return
+		}
+
+		if graph != nil {
+			writeGraph(w, req, log, graph, mediaType, renderOpts)
 			return
 		}
 
 		if err := json.NewEncoder(w).Encode(deps); err != nil {
 			log.Error("deps encoding error", slog.Any("error", err))
-			writeError(w, log, http.StatusInternalServerError, "internal server error")
+			writeProblem(w, req, log, problem{typ: problemInternal})
 			return
 		}
 	}
 }
-
-func writeError(w http.ResponseWriter, log *slog.Logger, statusCode int, msg string) {
-	w.WriteHeader(statusCode)
-	if _, err := fmt.Fprintln(w, `{"error":"`+msg+`"}`); err != nil {
-		log.Error("failed to write error", slog.String("error", err.Error()))
-	}
-}
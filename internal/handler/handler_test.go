@@ -1,7 +1,10 @@
 package handler_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -15,6 +18,8 @@ import (
 	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
 	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
 	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	"github.com/snyk/npmjs-deps-fetcher/internal/sbom"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
 )
 
 func TestPackageVersion(t *testing.T) {
@@ -36,7 +41,7 @@ func TestPackageVersion(t *testing.T) {
 				return req, mockshandler.NewMockPackageResolver(gomock.NewController(t))
 			},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedBody:       "{\"error\":\"invalid version constraint\"}\n",
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-constraint\",\"title\":\"Invalid version constraint\",\"status\":400,\"detail\":\"improper constraint: latest\",\"code\":\"invalid-constraint\",\"package\":\"foo\",\"range\":\"latest\"}\n",
 		},
 		{
 			name: "package not found",
@@ -53,7 +58,190 @@ func TestPackageVersion(t *testing.T) {
 				return req, resolver
 			},
 			expectedStatusCode: http.StatusNotFound,
-			expectedBody:       "{\"error\":\"package not found\"}\n",
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:package-not-found\",\"title\":\"Package not found\",\"status\":404,\"detail\":\"package not found\",\"code\":\"package-not-found\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
+		},
+		{
+			name: "version not found",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					&npm.VersionNotFoundError{Name: "foo", Version: "1.0.1"})
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:version-not-found\",\"title\":\"Version not found\",\"status\":404,\"detail\":\"version 1.0.1 of foo not found\",\"code\":\"version-not-found\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
+		},
+		{
+			name: "no matching version",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					fmt.Errorf("resolve highest version: %w", &semverutil.NoMatchingVersionError{Constraint: "1.0.1", Candidates: 3}))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:no-matching-version\",\"title\":\"No matching version\",\"status\":404,\"detail\":\"resolve highest version: no compatible versions found for 1.0.1 among 3 versions\",\"code\":\"no-matching-version\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
+		},
+		{
+			name: "no matching transitive version",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					fmt.Errorf("resolve highest version: %w", &semverutil.NoMatchingVersionError{
+						Package: "bar", Range: "^9.0.0", Constraint: "^9.0.0", Candidates: 2,
+					}))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:no-matching-version\",\"title\":\"No matching version\",\"status\":404,\"detail\":\"resolve highest version: no compatible versions found for ^9.0.0 among 2 versions\",\"code\":\"no-matching-version\",\"package\":\"bar\",\"range\":\"^9.0.0\"}\n",
+		},
+		{
+			name: "invalid dependency spec",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					&npm.InvalidDependencySpecError{Dependent: "foo@1.0.1", Name: "bar", Spec: "latest", Err: errors.New("improper constraint: latest")})
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-dependency-spec\",\"title\":\"Invalid dependency specification\",\"status\":422,\"detail\":\"invalid version constraint \\\"latest\\\" of bar in foo@1.0.1: improper constraint: latest\",\"code\":\"invalid-dependency-spec\",\"package\":\"bar\",\"range\":\"latest\"}\n",
+		},
+		{
+			name: "registry unavailable",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					fmt.Errorf("fetch package meta foo: %w: connection refused", npm.ErrRegistryUnavailable))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusBadGateway,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:registry-unavailable\",\"title\":\"Registry unavailable\",\"status\":502,\"detail\":\"fetch package meta foo: registry unavailable: connection refused\",\"code\":\"registry-unavailable\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
+		},
+		{
+			name: "registry rejected",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					fmt.Errorf("fetch package meta foo: %w", &npm.RegistryError{StatusCode: http.StatusForbidden, URL: "https://registry.npmjs.org/foo"}))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusBadGateway,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:registry-rejected\",\"title\":\"Registry rejected the request\",\"status\":502,\"detail\":\"fetch package meta foo: registry response 403 for \\\"https://registry.npmjs.org/foo\\\": Forbidden\",\"code\":\"registry-rejected\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
+		},
+		{
+			name: "registry rate limited",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					fmt.Errorf("fetch package meta foo: %w", &npm.RegistryError{StatusCode: http.StatusTooManyRequests, URL: "https://registry.npmjs.org/foo"}))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:registry-rate-limited\",\"title\":\"Registry rate limited\",\"status\":503,\"detail\":\"fetch package meta foo: registry response 429 for \\\"https://registry.npmjs.org/foo\\\": Too Many Requests\",\"code\":\"registry-rate-limited\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
+		},
+		{
+			name: "rate limited",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					fmt.Errorf("fetch package meta foo: %w: rate limit of registry.npmjs.org: turn in 2s exceeds the deadline: %w",
+						npm.ErrRateLimited, context.DeadlineExceeded))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:registry-rate-limited\",\"title\":\"Registry rate limited\",\"status\":503,\"detail\":\"fetch package meta foo: rate limited: rate limit of registry.npmjs.org: turn in 2s exceeds the deadline: context deadline exceeded\",\"code\":\"registry-rate-limited\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
+		},
+		{
+			name: "registry timeout",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					fmt.Errorf("fetch package meta foo: %w: i/o timeout", npm.ErrRegistryTimeout))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusGatewayTimeout,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:registry-timeout\",\"title\":\"Registry timeout\",\"status\":504,\"detail\":\"fetch package meta foo: registry timeout: i/o timeout\",\"code\":\"registry-timeout\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
+		},
+		{
+			name: "registry circuit open",
+			setup: func(tb testing.TB) (*http.Request, handler.PackageResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/1.0.1", http.NoBody)
+				req.SetPathValue("packageName", "foo")
+				req.SetPathValue("packageVersion", "1.0.1")
+
+				resolver := mockshandler.NewMockPackageResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "foo", gomock.Any()).Return(nil,
+					fmt.Errorf("fetch package meta foo: %w: fetch foo", npm.ErrCircuitOpen))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:registry-circuit-open\",\"title\":\"Registry circuit open\",\"status\":503,\"detail\":\"fetch package meta foo: registry circuit open: fetch foo\",\"code\":\"registry-circuit-open\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
 		},
 		{
 			name: "resolve deps failed",
@@ -70,7 +258,7 @@ func TestPackageVersion(t *testing.T) {
 				return req, resolver
 			},
 			expectedStatusCode: http.StatusInternalServerError,
-			expectedBody:       "{\"error\":\"internal server error\"}\n",
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:internal-error\",\"title\":\"Internal server error\",\"status\":500,\"code\":\"internal-error\",\"package\":\"foo\",\"range\":\"1.0.1\"}\n",
 		},
 		{
 			name: "resolve deps succeeded",
@@ -103,7 +291,140 @@ func TestPackageVersion(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			req, resolver := tc.setup(t)
 
-			h := handler.PackageVersion(slog.DiscardHandler, resolver)
+			h := handler.PackageVersion(slog.DiscardHandler, resolver, nil)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
+
+func TestPackageVersion_Format(t *testing.T) {
+	testCases := []struct {
+		name                string
+		url                 string
+		accept              string
+		setup               func(testing.TB) (handler.PackageResolver, handler.GraphResolver)
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:   "unsupported format query parameter",
+			url:    "http://localhost:8080/package/app/1.0.0?format=xml",
+			accept: "application/vnd.cyclonedx+json",
+			setup: func(tb testing.TB) (handler.PackageResolver, handler.GraphResolver) {
+				tb.Helper()
+				ctrl := gomock.NewController(tb)
+				return mockshandler.NewMockPackageResolver(ctrl), mockshandler.NewMockGraphResolver(ctrl)
+			},
+			expectedStatusCode:  http.StatusNotAcceptable,
+			expectedContentType: "application/problem+json",
+			expectedBody:        "{\"type\":\"urn:npmjs-deps-fetcher:problem:unsupported-format\",\"title\":\"Unsupported format\",\"status\":406,\"detail\":\"none of the requested formats is supported\",\"code\":\"unsupported-format\"}\n",
+		},
+		{
+			name:   "unsupported accept header",
+			url:    "http://localhost:8080/package/app/1.0.0",
+			accept: "text/html, application/xml;q=0.9",
+			setup: func(tb testing.TB) (handler.PackageResolver, handler.GraphResolver) {
+				tb.Helper()
+				ctrl := gomock.NewController(tb)
+				return mockshandler.NewMockPackageResolver(ctrl), mockshandler.NewMockGraphResolver(ctrl)
+			},
+			expectedStatusCode:  http.StatusNotAcceptable,
+			expectedContentType: "application/problem+json",
+			expectedBody:        "{\"type\":\"urn:npmjs-deps-fetcher:problem:unsupported-format\",\"title\":\"Unsupported format\",\"status\":406,\"detail\":\"none of the requested formats is supported\",\"code\":\"unsupported-format\"}\n",
+		},
+		{
+			name:   "invalid depth",
+			url:    "http://localhost:8080/package/app/1.0.0?format=dot&depth=0",
+			accept: "",
+			setup: func(tb testing.TB) (handler.PackageResolver, handler.GraphResolver) {
+				tb.Helper()
+				ctrl := gomock.NewController(tb)
+				return mockshandler.NewMockPackageResolver(ctrl), mockshandler.NewMockGraphResolver(ctrl)
+			},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedContentType: "application/problem+json",
+			expectedBody:        "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-parameter\",\"title\":\"Invalid query parameter\",\"status\":400,\"detail\":\"depth query parameter must be a positive integer\",\"code\":\"invalid-parameter\"}\n",
+		},
+		{
+			name:   "graph visualization collapsed below depth",
+			url:    "http://localhost:8080/package/app/1.0.0?depth=1",
+			accept: "text/vnd.mermaid",
+			setup: func(tb testing.TB) (handler.PackageResolver, handler.GraphResolver) {
+				tb.Helper()
+				ctrl := gomock.NewController(tb)
+				graphResolver := mockshandler.NewMockGraphResolver(ctrl)
+				graphResolver.EXPECT().ResolvePackageGraph(gomock.Any(), "app", gomock.Any()).Return(&npm.Graph{
+					Root: "app@1.0.0",
+					Nodes: map[string]*npm.Node{
+						"app@1.0.0": {
+							Name: "app", Version: "1.0.0",
+							Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"}},
+						},
+						"foo@1.2.0": {
+							Name: "foo", Version: "1.2.0",
+							Dependencies: map[string]npm.Edge{"bar": {Constraint: "^2.0.0", Node: "bar@2.0.0"}},
+						},
+						"bar@2.0.0": {Name: "bar", Version: "2.0.0"},
+					},
+				}, nil)
+				return mockshandler.NewMockPackageResolver(ctrl), graphResolver
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/vnd.mermaid",
+			expectedBody: "graph TD\n  n0[\"app@1.0.0\"]\n  n1[\"foo@1.2.0 (+1 collapsed)\"]\n  n0 -->|\"^1.0.0\"| n1\n" +
+				"  classDef root font-weight:bold\n  class n0 root\n  classDef collapsed stroke-dasharray:5 5\n  class n1 collapsed\n",
+		},
+		{
+			name:   "default format for any media type",
+			url:    "http://localhost:8080/package/app/1.0.0",
+			accept: "text/html, */*;q=0.8",
+			setup: func(tb testing.TB) (handler.PackageResolver, handler.GraphResolver) {
+				tb.Helper()
+				ctrl := gomock.NewController(tb)
+				resolver := mockshandler.NewMockPackageResolver(ctrl)
+				resolver.EXPECT().ResolvePackage(gomock.Any(), "app", gomock.Any()).Return(&npm.Package{Name: "app", Version: "1.0.0"}, nil)
+				return resolver, mockshandler.NewMockGraphResolver(ctrl)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        "{\"name\":\"app\",\"version\":\"1.0.0\"}\n",
+		},
+		{
+			name:   "graph resolution error",
+			url:    "http://localhost:8080/package/app/1.0.0?format=cyclonedx",
+			accept: "application/json",
+			setup: func(tb testing.TB) (handler.PackageResolver, handler.GraphResolver) {
+				tb.Helper()
+				ctrl := gomock.NewController(tb)
+				graphResolver := mockshandler.NewMockGraphResolver(ctrl)
+				graphResolver.EXPECT().ResolvePackageGraph(gomock.Any(), "app", gomock.Any()).Return(nil, npm.ErrPackageNotFound)
+				return mockshandler.NewMockPackageResolver(ctrl), graphResolver
+			},
+			expectedStatusCode:  http.StatusNotFound,
+			expectedContentType: "application/problem+json",
+			expectedBody:        "{\"type\":\"urn:npmjs-deps-fetcher:problem:package-not-found\",\"title\":\"Package not found\",\"status\":404,\"detail\":\"package not found\",\"code\":\"package-not-found\",\"package\":\"app\",\"range\":\"1.0.0\"}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver, graphResolver := tc.setup(t)
+			req := httptest.NewRequest(http.MethodGet, tc.url, http.NoBody)
+			req.SetPathValue("packageName", "app")
+			req.SetPathValue("packageVersion", "1.0.0")
+			req.Header.Set("Accept", tc.accept)
+
+			h := handler.PackageVersion(slog.DiscardHandler, resolver, graphResolver)
 			w := httptest.NewRecorder()
 
 			h.ServeHTTP(w, req)
@@ -113,7 +434,71 @@ func TestPackageVersion(t *testing.T) {
 			require.NoError(t, err)
 
 			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedContentType, resp.Header.Get("Content-Type"))
 			assert.Equal(t, tc.expectedBody, string(body))
 		})
 	}
 }
+
+func TestPackageVersion_CycloneDX(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	graphResolver := mockshandler.NewMockGraphResolver(ctrl)
+	graphResolver.EXPECT().ResolvePackageGraph(gomock.Any(), "app", gomock.Any()).Return(&npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"}},
+			},
+			"foo@1.2.0": {Name: "foo", Version: "1.2.0", License: "MIT"},
+		},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0", http.NoBody)
+	req.SetPathValue("packageName", "app")
+	req.SetPathValue("packageVersion", "1.0.0")
+	req.Header.Set("Accept", "application/vnd.cyclonedx+json")
+
+	h := handler.PackageVersion(slog.DiscardHandler, mockshandler.NewMockPackageResolver(ctrl), graphResolver)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	resp := w.Result()
+	var bom sbom.CycloneDXBOM
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&bom))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/vnd.cyclonedx+json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "1.5", bom.SpecVersion)
+	assert.Equal(t, []sbom.CycloneDXComponent{{
+		Type: "library", BOMRef: "pkg:npm/foo@1.2.0", Name: "foo", Version: "1.2.0", PURL: "pkg:npm/foo@1.2.0",
+		Licenses: []sbom.CycloneDXLicenseChoice{{Expression: "MIT"}},
+	}}, bom.Components)
+}
+
+func TestPackageVersion_SPDX(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	graphResolver := mockshandler.NewMockGraphResolver(ctrl)
+	graphResolver.EXPECT().ResolvePackageGraph(gomock.Any(), "app", gomock.Any()).Return(npm.NewGraph(&npm.Node{Name: "app", Version: "1.0.0"}), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0?format=spdx", http.NoBody)
+	req.SetPathValue("packageName", "app")
+	req.SetPathValue("packageVersion", "1.0.0")
+
+	h := handler.PackageVersion(slog.DiscardHandler, mockshandler.NewMockPackageResolver(ctrl), graphResolver)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	resp := w.Result()
+	var doc sbom.SPDXDocument
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/spdx+json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, []sbom.SPDXRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Package-app-1.0.0"},
+	}, doc.Relationships)
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// healthResponse is the response body of the health endpoint.
+type healthResponse struct {
+	// Status is ok, or degraded while a registry circuit is not closed.
+	Status   string                   `json:"status"`
+	Registry npm.CircuitBreakerStatus `json:"registry"`
+}
+
+// Healthcheck is the [http.HandlerFunc] for GET /healthcheck.
+// It responds with the state of the circuit breakers around the registry requests, the server being
+// degraded while a circuit is not closed. The server stays healthy while a registry is failing.
+func Healthcheck(logHandler slog.Handler, registry RegistryHealth) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		resp := healthResponse{Status: "ok", Registry: registry.Status()}
+		if resp.Registry.State != npm.CircuitClosed {
+			resp.Status = "degraded"
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Error("health encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestHealthcheck(t *testing.T) {
+	openedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	testCases := []struct {
+		name         string
+		setup        func(testing.TB) handler.RegistryHealth
+		expectedBody string
+	}{
+		{
+			name: "closed circuit",
+			setup: func(tb testing.TB) handler.RegistryHealth {
+				registry := mockshandler.NewMockRegistryHealth(gomock.NewController(tb))
+				registry.EXPECT().Status().Return(npm.CircuitBreakerStatus{State: npm.CircuitClosed, Requests: 12, Failures: 1})
+				return registry
+			},
+			expectedBody: `{"status":"ok","registry":{"state":"closed","requests":12,"failures":1}}`,
+		},
+		{
+			name: "open circuit",
+			setup: func(tb testing.TB) handler.RegistryHealth {
+				registry := mockshandler.NewMockRegistryHealth(gomock.NewController(tb))
+				registry.EXPECT().Status().Return(npm.CircuitBreakerStatus{
+					State: npm.CircuitOpen, Requests: 20, Failures: 15, OpenedAt: &openedAt,
+				})
+				return registry
+			},
+			expectedBody: `{"status":"degraded","registry":{"state":"open","requests":20,"failures":15,"openedAt":"2025-01-02T03:04:05Z"}}`,
+		},
+		{
+			name: "half-open circuit",
+			setup: func(tb testing.TB) handler.RegistryHealth {
+				registry := mockshandler.NewMockRegistryHealth(gomock.NewController(tb))
+				registry.EXPECT().Status().Return(npm.CircuitBreakerStatus{State: npm.CircuitHalfOpen, OpenedAt: &openedAt})
+				return registry
+			},
+			expectedBody: `{"status":"degraded","registry":{"state":"half-open","requests":0,"failures":0,"openedAt":"2025-01-02T03:04:05Z"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := handler.Healthcheck(slog.DiscardHandler, tc.setup(t))
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/healthcheck", http.NoBody)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			assert.JSONEq(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// maxLockfileSize is the maximum size in bytes of an uploaded lockfile.
+const maxLockfileSize = 32 << 20
+
+// Lockfile is the [http.HandlerFunc] for POST /lockfile.
+// The dependency graph is read from the uploaded lockfile, without contacting the registry, and written
+// in the formats of the package endpoint, except the streamed one.
+func Lockfile(logHandler slog.Handler) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		mediaType, ok := negotiateGraphFormat(req)
+		if !ok {
+			log.Debug("unsupported format", slog.String("format", req.URL.Query().Get("format")),
+				slog.String("accept", req.Header.Get("Accept")))
+			writeProblem(w, req, log, problem{typ: problemUnsupportedFormat, Detail: "none of the requested formats is supported"})
+			return
+		}
+
+		renderOpts, err := parseRenderOptions(req)
+		if err != nil {
+			log.Debug("invalid render options", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: err.Error()})
+			return
+		}
+
+		graph, err := npm.ParseLockfile(http.MaxBytesReader(w, req.Body, maxLockfileSize))
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			log.Debug("lockfile too large", slog.Int64("limit", maxBytesErr.Limit))
+			writeProblem(w, req, log, problem{typ: problemBodyTooLarge, Detail: "lockfile too large"})
+			return
+		}
+
+		if errors.Is(err, npm.ErrUnsupportedLockfile) {
+			log.Debug("unsupported lockfile", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemUnsupportedLockfile, Detail: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Debug("invalid lockfile", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: err.Error()})
+			return
+		}
+
+		writeGraph(w, req, log, graph, mediaType, renderOpts)
+	}
+}
@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+)
+
+func TestLockfile(t *testing.T) {
+	testCases := []struct {
+		name                string
+		query               string
+		body                string
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:               "unsupported format",
+			query:              "?format=ndjson",
+			body:               `{}`,
+			expectedStatusCode: http.StatusNotAcceptable,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:unsupported-format\",\"title\":\"Unsupported format\",\"status\":406,\"detail\":\"none of the requested formats is supported\",\"code\":\"unsupported-format\"}\n",
+		},
+		{
+			name:               "unsupported lockfile",
+			body:               `{"lockfileVersion":9}`,
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:unsupported-lockfile\",\"title\":\"Unsupported lockfile\",\"status\":415,\"detail\":\"unsupported lockfile: package-lock.json lockfileVersion 9\",\"code\":\"unsupported-lockfile\"}\n",
+		},
+		{
+			name:               "invalid lockfile",
+			body:               `{"lockfileVersion":3,"packages":{"":{"dependencies":{"foo":"^1.0.0"}}}}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"invalid lockfile: dependency foo of \\\"\\\" is not locked\",\"code\":\"invalid-body\"}\n",
+		},
+		{
+			name:               "lockfile too large",
+			body:               `{"name":"` + strings.Repeat("a", 32<<20) + `"}`,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:body-too-large\",\"title\":\"Request body too large\",\"status\":413,\"detail\":\"lockfile too large\",\"code\":\"body-too-large\"}\n",
+		},
+		{
+			name: "lockfile parsed",
+			body: `{"name":"app","version":"1.0.0","lockfileVersion":3,"packages":{` +
+				`"":{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0"}},` +
+				`"node_modules/foo":{"version":"1.4.0","integrity":"sha512-foo"}}}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"root":"app@1.0.0","nodes":{"app@1.0.0":{"name":"app","version":"1.0.0","dependencies":{` +
+				`"foo":{"constraint":"^1.0.0","node":"foo@1.4.0"}}},"foo@1.4.0":{"name":"foo","version":"1.4.0","integrity":"sha512-foo"}}}` + "\n",
+		},
+		{
+			name:  "lockfile graph format",
+			query: "?format=dot",
+			body: `{"name":"app","version":"1.0.0","lockfileVersion":3,"packages":{` +
+				`"":{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0"}},` +
+				`"node_modules/foo":{"version":"1.4.0","integrity":"sha512-foo"}}}`,
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/vnd.graphviz",
+			expectedBody:        "digraph dependencies {\n  node [shape=box];\n  \"app@1.0.0\" [label=\"app@1.0.0\", style=bold];\n  \"foo@1.4.0\" [label=\"foo@1.4.0\"];\n  \"app@1.0.0\" -> \"foo@1.4.0\" [label=\"^1.0.0\"];\n}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/lockfile"+tc.query, strings.NewReader(tc.body))
+
+			h := handler.Lockfile(slog.DiscardHandler)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// maxManifestSize is the maximum size in bytes of an uploaded package.json manifest.
+const maxManifestSize = 1 << 20
+
+// Manifest is the [http.HandlerFunc] for POST /manifest.
+// The optional include query parameter is a comma separated list of the extra
+// dependency types to resolve, among "dev" and "optional". The resolved graph is
+// written in the formats of the package endpoint, except the streamed one.
+func Manifest(logHandler slog.Handler, resolver GraphResolver) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		mediaType, ok := negotiateGraphFormat(req)
+		if !ok {
+			log.Debug("unsupported format", slog.String("format", req.URL.Query().Get("format")),
+				slog.String("accept", req.Header.Get("Accept")))
+			writeProblem(w, req, log, problem{typ: problemUnsupportedFormat, Detail: "none of the requested formats is supported"})
+			return
+		}
+
+		renderOpts, err := parseRenderOptions(req)
+		if err != nil {
+			log.Debug("invalid render options", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: err.Error()})
+			return
+		}
+
+		opts, err := parseManifestOptions(req.URL.Query().Get("include"))
+		if err != nil {
+			log.Debug("invalid include parameter", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: "invalid include parameter: " + err.Error()})
+			return
+		}
+
+		manifest, err := npm.ParseManifest(http.MaxBytesReader(w, req.Body, maxManifestSize), opts)
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			log.Debug("manifest too large", slog.Int64("limit", maxBytesErr.Limit))
+			writeProblem(w, req, log, problem{typ: problemBodyTooLarge, Detail: "manifest too large"})
+			return
+		}
+
+		if err != nil {
+			log.Debug("invalid manifest", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: err.Error()})
+			return
+		}
+
+		graph, err := resolver.ResolveGraph(ctx, manifest.Package(opts))
+		if err != nil {
+			writeResolutionProblem(w, req, log, err, "", "")
+			return
+		}
+		graph.Skipped = manifest.Skipped(opts)
+
+		writeGraph(w, req, log, graph, mediaType, renderOpts)
+	}
+}
+
+func parseManifestOptions(include string) (npm.ManifestOptions, error) {
+	var opts npm.ManifestOptions
+	if include == "" {
+		return opts, nil
+	}
+
+	for _, depType := range strings.Split(include, ",") {
+		switch strings.TrimSpace(depType) {
+		case "dev":
+			opts.Dev = true
+		case "optional":
+			opts.Optional = true
+		default:
+			return opts, errors.New("unknown dependency type: " + depType)
+		}
+	}
+
+	return opts, nil
+}
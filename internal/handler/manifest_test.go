@@ -0,0 +1,223 @@
+package handler_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestManifest(t *testing.T) {
+	testCases := []struct {
+		name                string
+		setup               func(testing.TB) (*http.Request, handler.GraphResolver)
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name: "invalid include parameter",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest?include=peer", strings.NewReader(`{}`))
+
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-parameter\",\"title\":\"Invalid query parameter\",\"status\":400,\"detail\":\"invalid include parameter: unknown dependency type: peer\",\"code\":\"invalid-parameter\"}\n",
+		},
+		{
+			name: "unsupported format",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest?format=ndjson", strings.NewReader(`{}`))
+
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusNotAcceptable,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:unsupported-format\",\"title\":\"Unsupported format\",\"status\":406,\"detail\":\"none of the requested formats is supported\",\"code\":\"unsupported-format\"}\n",
+		},
+		{
+			name: "invalid depth parameter",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest?format=dot&depth=0", strings.NewReader(`{}`))
+
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-parameter\",\"title\":\"Invalid query parameter\",\"status\":400,\"detail\":\"depth query parameter must be a positive integer\",\"code\":\"invalid-parameter\"}\n",
+		},
+		{
+			name: "invalid manifest",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest", strings.NewReader(`{"dependencies":{"_foo":"^1.0.0"}}`))
+
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"invalid manifest: dependency \\\"_foo\\\": package name cannot start with a period or an underscore\",\"code\":\"invalid-body\"}\n",
+		},
+		{
+			name: "skipped dependencies",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				body := `{"dependencies":{"foo":"^1.0.0","bar":"workspace:*"},"devDependencies":{"_baz":"git+https://github.com/baz/baz.git"}}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest", strings.NewReader(body))
+
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{Name: "root", Dependencies: map[string]string{"foo": "^1.0.0"}}).
+					Return(&npm.Graph{Root: "root@", Nodes: map[string]*npm.Node{"root@": {Name: "root"}}}, nil)
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "{\"root\":\"root@\",\"nodes\":{\"root@\":{\"name\":\"root\",\"version\":\"\"}},\"skipped\":[{\"name\":\"bar\",\"spec\":\"workspace:*\"}]}\n",
+		},
+		{
+			name: "manifest too large",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				body := `{"name":"` + strings.Repeat("a", 1<<20) + `"}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest", strings.NewReader(body))
+
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:body-too-large\",\"title\":\"Request body too large\",\"status\":413,\"detail\":\"manifest too large\",\"code\":\"body-too-large\"}\n",
+		},
+		{
+			name: "package not found",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest", strings.NewReader(`{"dependencies":{"foo":"^1.0.0"}}`))
+
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), gomock.Any()).Return(nil, npm.ErrPackageNotFound)
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:package-not-found\",\"title\":\"Package not found\",\"status\":404,\"detail\":\"package not found\",\"code\":\"package-not-found\"}\n",
+		},
+		{
+			name: "resolve graph failed",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest", strings.NewReader(`{"dependencies":{"foo":"^1.0.0"}}`))
+
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), gomock.Any()).Return(nil, errors.New("something bad happened"))
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:internal-error\",\"title\":\"Internal server error\",\"status\":500,\"code\":\"internal-error\"}\n",
+		},
+		{
+			name: "resolve graph succeeded",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				body := `{"name":"app","version":"0.1.0","dependencies":{"foo":"^1.0.0"},"devDependencies":{"bar":"^2.0.0"}}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest?include=dev", strings.NewReader(body))
+
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), &npm.Package{
+					Name:         "app",
+					Version:      "0.1.0",
+					Dependencies: map[string]string{"foo": "^1.0.0", "bar": "^2.0.0"},
+				}).Return(&npm.Graph{
+					Root: "app@0.1.0",
+					Nodes: map[string]*npm.Node{
+						"app@0.1.0": {
+							Name:    "app",
+							Version: "0.1.0",
+							Dependencies: map[string]npm.Edge{
+								"bar": {Constraint: "^2.0.0", Node: "bar@2.0.1"},
+								"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"},
+							},
+						},
+						"bar@2.0.1": {Name: "bar", Version: "2.0.1"},
+						"foo@1.2.0": {Name: "foo", Version: "1.2.0"},
+					},
+				}, nil)
+
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"root":"app@0.1.0","nodes":{"app@0.1.0":{"name":"app","version":"0.1.0","dependencies":{` +
+				`"bar":{"constraint":"^2.0.0","node":"bar@2.0.1"},"foo":{"constraint":"^1.0.0","node":"foo@1.2.0"}}},` +
+				`"bar@2.0.1":{"name":"bar","version":"2.0.1"},"foo@1.2.0":{"name":"foo","version":"1.2.0"}}}` + "\n",
+		},
+		{
+			name: "graph format",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+
+				body := `{"name":"app","version":"0.1.0","dependencies":{"foo":"^1.0.0"}}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest", strings.NewReader(body))
+				req.Header.Set("Accept", "application/x-ndjson, text/vnd.mermaid;q=0.5")
+
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveGraph(gomock.Any(), gomock.Any()).Return(&npm.Graph{
+					Root: "app@0.1.0",
+					Nodes: map[string]*npm.Node{
+						"app@0.1.0": {
+							Name:         "app",
+							Version:      "0.1.0",
+							Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"}},
+						},
+						"foo@1.2.0": {Name: "foo", Version: "1.2.0"},
+					},
+				}, nil)
+
+				return req, resolver
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/vnd.mermaid",
+			expectedBody:        "graph TD\n  n0[\"app@0.1.0\"]\n  n1[\"foo@1.2.0\"]\n  n0 -->|\"^1.0.0\"| n1\n  classDef root font-weight:bold\n  class n0 root\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, resolver := tc.setup(t)
+
+			h := handler.Manifest(slog.DiscardHandler, resolver)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			if tc.expectedContentType != "" {
+				assert.Equal(t, tc.expectedContentType, resp.Header.Get("Content-Type"))
+			}
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
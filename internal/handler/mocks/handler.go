@@ -15,6 +15,7 @@ import (
 
 	semver "github.com/Masterminds/semver/v3"
 	npm "github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	semver0 "github.com/snyk/npmjs-deps-fetcher/internal/semver"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -56,3 +57,336 @@ func (mr *MockPackageResolverMockRecorder) ResolvePackage(ctx, name, constraint
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePackage", reflect.TypeOf((*MockPackageResolver)(nil).ResolvePackage), ctx, name, constraint)
 }
+
+// MockOutdatedResolver is a mock of OutdatedResolver interface.
+type MockOutdatedResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutdatedResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockOutdatedResolverMockRecorder is the mock recorder for MockOutdatedResolver.
+type MockOutdatedResolverMockRecorder struct {
+	mock *MockOutdatedResolver
+}
+
+// NewMockOutdatedResolver creates a new mock instance.
+func NewMockOutdatedResolver(ctrl *gomock.Controller) *MockOutdatedResolver {
+	mock := &MockOutdatedResolver{ctrl: ctrl}
+	mock.recorder = &MockOutdatedResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutdatedResolver) EXPECT() *MockOutdatedResolverMockRecorder {
+	return m.recorder
+}
+
+// ResolveOutdated mocks base method.
+func (m *MockOutdatedResolver) ResolveOutdated(ctx context.Context, deps, current map[string]string) ([]npm.Outdated, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveOutdated", ctx, deps, current)
+	ret0, _ := ret[0].([]npm.Outdated)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveOutdated indicates an expected call of ResolveOutdated.
+func (mr *MockOutdatedResolverMockRecorder) ResolveOutdated(ctx, deps, current any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveOutdated", reflect.TypeOf((*MockOutdatedResolver)(nil).ResolveOutdated), ctx, deps, current)
+}
+
+// ResolvePackageOutdated mocks base method.
+func (m *MockOutdatedResolver) ResolvePackageOutdated(ctx context.Context, name string, constraint *semver.Constraints) ([]npm.Outdated, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolvePackageOutdated", ctx, name, constraint)
+	ret0, _ := ret[0].([]npm.Outdated)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolvePackageOutdated indicates an expected call of ResolvePackageOutdated.
+func (mr *MockOutdatedResolverMockRecorder) ResolvePackageOutdated(ctx, name, constraint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePackageOutdated", reflect.TypeOf((*MockOutdatedResolver)(nil).ResolvePackageOutdated), ctx, name, constraint)
+}
+
+// MockGraphResolver is a mock of GraphResolver interface.
+type MockGraphResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockGraphResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockGraphResolverMockRecorder is the mock recorder for MockGraphResolver.
+type MockGraphResolverMockRecorder struct {
+	mock *MockGraphResolver
+}
+
+// NewMockGraphResolver creates a new mock instance.
+func NewMockGraphResolver(ctrl *gomock.Controller) *MockGraphResolver {
+	mock := &MockGraphResolver{ctrl: ctrl}
+	mock.recorder = &MockGraphResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGraphResolver) EXPECT() *MockGraphResolverMockRecorder {
+	return m.recorder
+}
+
+// ResolveGraph mocks base method.
+func (m *MockGraphResolver) ResolveGraph(ctx context.Context, root *npm.Package) (*npm.Graph, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveGraph", ctx, root)
+	ret0, _ := ret[0].(*npm.Graph)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveGraph indicates an expected call of ResolveGraph.
+func (mr *MockGraphResolverMockRecorder) ResolveGraph(ctx, root any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveGraph", reflect.TypeOf((*MockGraphResolver)(nil).ResolveGraph), ctx, root)
+}
+
+// ResolvePackageGraph mocks base method.
+func (m *MockGraphResolver) ResolvePackageGraph(ctx context.Context, name string, constraint *semver.Constraints) (*npm.Graph, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolvePackageGraph", ctx, name, constraint)
+	ret0, _ := ret[0].(*npm.Graph)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolvePackageGraph indicates an expected call of ResolvePackageGraph.
+func (mr *MockGraphResolverMockRecorder) ResolvePackageGraph(ctx, name, constraint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePackageGraph", reflect.TypeOf((*MockGraphResolver)(nil).ResolvePackageGraph), ctx, name, constraint)
+}
+
+// ResolvePackageGraphs mocks base method.
+func (m *MockGraphResolver) ResolvePackageGraphs(ctx context.Context, name string, constraints ...*semver.Constraints) ([]*npm.Graph, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, name}
+	for _, a := range constraints {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ResolvePackageGraphs", varargs...)
+	ret0, _ := ret[0].([]*npm.Graph)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolvePackageGraphs indicates an expected call of ResolvePackageGraphs.
+func (mr *MockGraphResolverMockRecorder) ResolvePackageGraphs(ctx, name any, constraints ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, name}, constraints...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePackageGraphs", reflect.TypeOf((*MockGraphResolver)(nil).ResolvePackageGraphs), varargs...)
+}
+
+// MockBatchResolver is a mock of BatchResolver interface.
+type MockBatchResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchResolverMockRecorder
+	isgomock struct{}
+}
+
+// MockBatchResolverMockRecorder is the mock recorder for MockBatchResolver.
+type MockBatchResolverMockRecorder struct {
+	mock *MockBatchResolver
+}
+
+// NewMockBatchResolver creates a new mock instance.
+func NewMockBatchResolver(ctrl *gomock.Controller) *MockBatchResolver {
+	mock := &MockBatchResolver{ctrl: ctrl}
+	mock.recorder = &MockBatchResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchResolver) EXPECT() *MockBatchResolverMockRecorder {
+	return m.recorder
+}
+
+// ResolveBatch mocks base method.
+func (m *MockBatchResolver) ResolveBatch(ctx context.Context, queries []npm.PackageQuery) []npm.BatchResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveBatch", ctx, queries)
+	ret0, _ := ret[0].([]npm.BatchResult)
+	return ret0
+}
+
+// ResolveBatch indicates an expected call of ResolveBatch.
+func (mr *MockBatchResolverMockRecorder) ResolveBatch(ctx, queries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveBatch", reflect.TypeOf((*MockBatchResolver)(nil).ResolveBatch), ctx, queries)
+}
+
+// MockPackageMetaFetcher is a mock of PackageMetaFetcher interface.
+type MockPackageMetaFetcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPackageMetaFetcherMockRecorder
+	isgomock struct{}
+}
+
+// MockPackageMetaFetcherMockRecorder is the mock recorder for MockPackageMetaFetcher.
+type MockPackageMetaFetcherMockRecorder struct {
+	mock *MockPackageMetaFetcher
+}
+
+// NewMockPackageMetaFetcher creates a new mock instance.
+func NewMockPackageMetaFetcher(ctrl *gomock.Controller) *MockPackageMetaFetcher {
+	mock := &MockPackageMetaFetcher{ctrl: ctrl}
+	mock.recorder = &MockPackageMetaFetcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPackageMetaFetcher) EXPECT() *MockPackageMetaFetcherMockRecorder {
+	return m.recorder
+}
+
+// FetchPackageMeta mocks base method.
+func (m *MockPackageMetaFetcher) FetchPackageMeta(ctx context.Context, name string) (*npm.PackageMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchPackageMeta", ctx, name)
+	ret0, _ := ret[0].(*npm.PackageMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchPackageMeta indicates an expected call of FetchPackageMeta.
+func (mr *MockPackageMetaFetcherMockRecorder) FetchPackageMeta(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchPackageMeta", reflect.TypeOf((*MockPackageMetaFetcher)(nil).FetchPackageMeta), ctx, name)
+}
+
+// MockRegistryHealth is a mock of RegistryHealth interface.
+type MockRegistryHealth struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegistryHealthMockRecorder
+	isgomock struct{}
+}
+
+// MockRegistryHealthMockRecorder is the mock recorder for MockRegistryHealth.
+type MockRegistryHealthMockRecorder struct {
+	mock *MockRegistryHealth
+}
+
+// NewMockRegistryHealth creates a new mock instance.
+func NewMockRegistryHealth(ctrl *gomock.Controller) *MockRegistryHealth {
+	mock := &MockRegistryHealth{ctrl: ctrl}
+	mock.recorder = &MockRegistryHealthMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRegistryHealth) EXPECT() *MockRegistryHealthMockRecorder {
+	return m.recorder
+}
+
+// Status mocks base method.
+func (m *MockRegistryHealth) Status() npm.CircuitBreakerStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status")
+	ret0, _ := ret[0].(npm.CircuitBreakerStatus)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockRegistryHealthMockRecorder) Status() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockRegistryHealth)(nil).Status))
+}
+
+// MockRegistryRateLimit is a mock of RegistryRateLimit interface.
+type MockRegistryRateLimit struct {
+	ctrl     *gomock.Controller
+	recorder *MockRegistryRateLimitMockRecorder
+	isgomock struct{}
+}
+
+// MockRegistryRateLimitMockRecorder is the mock recorder for MockRegistryRateLimit.
+type MockRegistryRateLimitMockRecorder struct {
+	mock *MockRegistryRateLimit
+}
+
+// NewMockRegistryRateLimit creates a new mock instance.
+func NewMockRegistryRateLimit(ctrl *gomock.Controller) *MockRegistryRateLimit {
+	mock := &MockRegistryRateLimit{ctrl: ctrl}
+	mock.recorder = &MockRegistryRateLimitMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRegistryRateLimit) EXPECT() *MockRegistryRateLimitMockRecorder {
+	return m.recorder
+}
+
+// RateLimitStats mocks base method.
+func (m *MockRegistryRateLimit) RateLimitStats() map[string]npm.RateLimitStats {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RateLimitStats")
+	ret0, _ := ret[0].(map[string]npm.RateLimitStats)
+	return ret0
+}
+
+// RateLimitStats indicates an expected call of RateLimitStats.
+func (mr *MockRegistryRateLimitMockRecorder) RateLimitStats() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RateLimitStats", reflect.TypeOf((*MockRegistryRateLimit)(nil).RateLimitStats))
+}
+
+// MockDependentsIndex is a mock of DependentsIndex interface.
+type MockDependentsIndex struct {
+	ctrl     *gomock.Controller
+	recorder *MockDependentsIndexMockRecorder
+	isgomock struct{}
+}
+
+// MockDependentsIndexMockRecorder is the mock recorder for MockDependentsIndex.
+type MockDependentsIndexMockRecorder struct {
+	mock *MockDependentsIndex
+}
+
+// NewMockDependentsIndex creates a new mock instance.
+func NewMockDependentsIndex(ctrl *gomock.Controller) *MockDependentsIndex {
+	mock := &MockDependentsIndex{ctrl: ctrl}
+	mock.recorder = &MockDependentsIndexMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDependentsIndex) EXPECT() *MockDependentsIndexMockRecorder {
+	return m.recorder
+}
+
+// Dependents mocks base method.
+func (m *MockDependentsIndex) Dependents(name string) []npm.Dependent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Dependents", name)
+	ret0, _ := ret[0].([]npm.Dependent)
+	return ret0
+}
+
+// Dependents indicates an expected call of Dependents.
+func (mr *MockDependentsIndexMockRecorder) Dependents(name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dependents", reflect.TypeOf((*MockDependentsIndex)(nil).Dependents), name)
+}
+
+// DependentsInRange mocks base method.
+func (m *MockDependentsIndex) DependentsInRange(name string, rng semver0.Range) []npm.Dependent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DependentsInRange", name, rng)
+	ret0, _ := ret[0].([]npm.Dependent)
+	return ret0
+}
+
+// DependentsInRange indicates an expected call of DependentsInRange.
+func (mr *MockDependentsIndexMockRecorder) DependentsInRange(name, rng any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DependentsInRange", reflect.TypeOf((*MockDependentsIndex)(nil).DependentsInRange), name, rng)
+}
@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"text/tabwriter"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// mediaTypeText is the media type of the human-readable table of the outdated endpoints.
+const mediaTypeText = "text/plain"
+
+// outdatedFormats are the formats of the outdated endpoints, a JSON report by default or a table.
+var outdatedFormats = []format{
+	{name: "json", mediaType: mediaTypeJSON},
+	{name: "table", mediaType: mediaTypeText},
+}
+
+// outdatedResponse is the JSON response body of the outdated endpoints.
+type outdatedResponse struct {
+	Outdated []npm.Outdated          `json:"outdated"`
+	Skipped  []npm.SkippedDependency `json:"skipped,omitempty"`
+}
+
+// PackageOutdated is the [http.HandlerFunc] for GET /package/{package}/{version}/outdated.
+func PackageOutdated(logHandler slog.Handler, resolver OutdatedResolver) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		pkgName, pkgVersion := req.PathValue("packageName"), req.PathValue("packageVersion")
+
+		w.Header().Set("Content-Type", "application/json")
+
+		mediaType, ok := negotiateFormat(req, outdatedFormats)
+		if !ok {
+			log.Debug("unsupported format", slog.String("format", req.URL.Query().Get("format")),
+				slog.String("accept", req.Header.Get("Accept")))
+			writeProblem(w, req, log, problem{typ: problemUnsupportedFormat, Detail: "none of the requested formats is supported"})
+			return
+		}
+
+		constraint, err := semver.NewConstraint(pkgVersion)
+		if err != nil {
+			log.Debug("invalid version constraint", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{
+				typ: problemInvalidConstraint, Detail: err.Error(), Package: pkgName, Range: pkgVersion,
+			})
+			return
+		}
+
+		outdated, err := resolver.ResolvePackageOutdated(ctx, pkgName, constraint)
+		if err != nil {
+			writeResolutionProblem(w, req, log, err, pkgName, pkgVersion)
+			return
+		}
+
+		writeOutdated(w, req, log, mediaType, outdatedResponse{Outdated: outdated})
+	}
+}
+
+// ManifestOutdated is the [http.HandlerFunc] for POST /manifest/outdated.
+// The request body is either a package.json manifest, or a multipart form holding the manifest and, optionally,
+// the lockfile of the project to read the current versions from. The include query parameter selects the
+// dependency types like for the manifest endpoint.
+func ManifestOutdated(logHandler slog.Handler, resolver OutdatedResolver) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		mediaType, ok := negotiateFormat(req, outdatedFormats)
+		if !ok {
+			log.Debug("unsupported format", slog.String("format", req.URL.Query().Get("format")),
+				slog.String("accept", req.Header.Get("Accept")))
+			writeProblem(w, req, log, problem{typ: problemUnsupportedFormat, Detail: "none of the requested formats is supported"})
+			return
+		}
+
+		opts, err := parseManifestOptions(req.URL.Query().Get("include"))
+		if err != nil {
+			log.Debug("invalid include parameter", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: "invalid include parameter: " + err.Error()})
+			return
+		}
+
+		req.Body = http.MaxBytesReader(w, req.Body, maxManifestSize+maxLockfileSize)
+		manifestFile, lockfileFile, err := manifestUpload(req)
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			log.Debug("outdated upload too large", slog.Int64("limit", maxBytesErr.Limit))
+			writeProblem(w, req, log, problem{typ: problemBodyTooLarge, Detail: "upload too large"})
+			return
+		}
+
+		if err != nil {
+			log.Debug("invalid upload", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: "invalid upload: " + err.Error()})
+			return
+		}
+
+		manifest, err := npm.ParseManifest(manifestFile, opts)
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			log.Debug("manifest too large", slog.Int64("limit", maxBytesErr.Limit))
+			writeProblem(w, req, log, problem{typ: problemBodyTooLarge, Detail: "manifest too large"})
+			return
+		}
+
+		if err != nil {
+			log.Debug("invalid manifest", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: err.Error()})
+			return
+		}
+
+		deps := manifest.Package(opts).Dependencies
+		current := map[string]string{}
+		if lockfileFile != nil {
+			locked, err := npm.ParseLockfile(lockfileFile)
+			if err != nil {
+				log.Debug("invalid lockfile", slog.String("error", err.Error()))
+				writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: err.Error()})
+				return
+			}
+			current = npm.CurrentVersions(locked, deps)
+		}
+
+		outdated, err := resolver.ResolveOutdated(ctx, deps, current)
+		if err != nil {
+			writeResolutionProblem(w, req, log, err, "", "")
+			return
+		}
+
+		writeOutdated(w, req, log, mediaType, outdatedResponse{Outdated: outdated, Skipped: manifest.Skipped(opts)})
+	}
+}
+
+// manifestUpload returns the uploaded manifest and, for multipart forms, the optional lockfile.
+func manifestUpload(req *http.Request) (manifest, lockfile io.Reader, err error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return req.Body, nil, nil
+	}
+
+	if err := req.ParseMultipartForm(maxManifestSize); err != nil {
+		return nil, nil, fmt.Errorf("parse multipart form: %w", err)
+	}
+
+	manifestFile, _, err := req.FormFile("manifest")
+	if err != nil {
+		return nil, nil, fmt.Errorf("manifest form file: %w", err)
+	}
+
+	lockfileFile, _, err := req.FormFile("lockfile")
+	if errors.Is(err, http.ErrMissingFile) {
+		return manifestFile, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("lockfile form file: %w", err)
+	}
+
+	return manifestFile, lockfileFile, nil
+}
+
+// writeOutdated writes the outdated report in the negotiated media type, as JSON or as a human-readable table.
+func writeOutdated(w http.ResponseWriter, req *http.Request, log *slog.Logger, mediaType string, resp outdatedResponse) {
+	if mediaType != mediaTypeText {
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Error("outdated encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaTypeText+"; charset=utf-8")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Package\tCurrent\tWanted\tLatest\tGap")
+	for _, o := range resp.Outdated {
+		gap := o.LatestGap
+		if gap == "" {
+			gap = o.WantedGap
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", o.Name, o.Current, o.Wanted, o.Latest, gap)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Error("outdated table writing error", slog.Any("error", err))
+	}
+}
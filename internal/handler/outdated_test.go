@@ -0,0 +1,261 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+var fooOutdated = []npm.Outdated{
+	{
+		Name: "foo", Constraint: "^1.0.0", Current: "1.0.0", Wanted: "1.2.0", Latest: "2.1.0",
+		WantedGap: semverutil.GapMinor, LatestGap: semverutil.GapMajor,
+	},
+}
+
+func TestPackageOutdated(t *testing.T) {
+	testCases := []struct {
+		name               string
+		setup              func(testing.TB) (*http.Request, handler.OutdatedResolver)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "invalid version constraint",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/latest/outdated", http.NoBody)
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "latest")
+				return req, mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-constraint\",\"title\":\"Invalid version constraint\",\"status\":400,\"detail\":\"improper constraint: latest\",\"code\":\"invalid-constraint\",\"package\":\"app\",\"range\":\"latest\"}\n",
+		},
+		{
+			name: "package not found",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/outdated", http.NoBody)
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				resolver := mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackageOutdated(gomock.Any(), "app", gomock.Any()).Return(nil, npm.ErrPackageNotFound)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:package-not-found\",\"title\":\"Package not found\",\"status\":404,\"detail\":\"package not found\",\"code\":\"package-not-found\",\"package\":\"app\",\"range\":\"1.0.0\"}\n",
+		},
+		{
+			name: "outdated table",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/outdated?format=table", http.NoBody)
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				resolver := mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackageOutdated(gomock.Any(), "app", gomock.Any()).Return(fooOutdated, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: "Package  Current  Wanted  Latest  Gap\n" +
+				"foo      1.0.0    1.2.0   2.1.0   major\n",
+		},
+		{
+			name: "outdated table negotiated",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/outdated", http.NoBody)
+				req.Header.Set("Accept", "application/json;q=0.5, text/*")
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				resolver := mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackageOutdated(gomock.Any(), "app", gomock.Any()).Return(fooOutdated, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: "Package  Current  Wanted  Latest  Gap\n" +
+				"foo      1.0.0    1.2.0   2.1.0   major\n",
+		},
+		{
+			name: "outdated json negotiated",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/outdated", http.NoBody)
+				req.Header.Set("Accept", "text/plain;q=0, */*")
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				resolver := mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackageOutdated(gomock.Any(), "app", gomock.Any()).Return(fooOutdated, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"outdated":[{"name":"foo","constraint":"^1.0.0","current":"1.0.0","wanted":"1.2.0","latest":"2.1.0",` +
+				`"wantedGap":"minor","latestGap":"major"}]}` + "\n",
+		},
+		{
+			name: "unsupported format",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/outdated", http.NoBody)
+				req.Header.Set("Accept", "application/xml")
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				return req, mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusNotAcceptable,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:unsupported-format\",\"title\":\"Unsupported format\",\"status\":406,\"detail\":\"none of the requested formats is supported\",\"code\":\"unsupported-format\"}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, resolver := tc.setup(t)
+
+			h := handler.PackageOutdated(slog.DiscardHandler, resolver)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
+
+func TestManifestOutdated(t *testing.T) {
+	testCases := []struct {
+		name               string
+		setup              func(testing.TB) (*http.Request, handler.OutdatedResolver)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "invalid manifest",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest/outdated", strings.NewReader(`[]`))
+				return req, mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"invalid manifest: decoding: json: cannot unmarshal array into Go value of type npm.Manifest\",\"code\":\"invalid-body\"}\n",
+		},
+		{
+			name: "manifest without lockfile",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest/outdated", strings.NewReader(driftManifest))
+				resolver := mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveOutdated(gomock.Any(), map[string]string{"foo": "^1.0.0"}, map[string]string{}).Return(fooOutdated, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"outdated":[{"name":"foo","constraint":"^1.0.0","current":"1.0.0","wanted":"1.2.0","latest":"2.1.0",` +
+				`"wantedGap":"minor","latestGap":"major"}]}` + "\n",
+		},
+		{
+			name: "manifest with lockfile",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := newMultipartRequest(tb, map[string]string{"manifest": driftManifest, "lockfile": driftLockfile})
+				req.URL.RawQuery = "include=dev"
+				resolver := mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveOutdated(gomock.Any(),
+					map[string]string{"foo": "^1.0.0", "bar": "^2.0.0"},
+					map[string]string{"foo": "1.0.0", "bar": "2.0.0"},
+				).Return([]npm.Outdated{}, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "{\"outdated\":[]}\n",
+		},
+		{
+			name: "manifest with lockfile without direct dependencies",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := newMultipartRequest(tb, map[string]string{
+					"manifest": `{"name":"app","version":"1.0.0","dependencies":{"foo":"^1.0.0","bar":"^1.0.0"}}`,
+					"lockfile": "# yarn lockfile v1\n\n" +
+						"bar@^1.0.0:\n  version \"1.0.0\"\n  dependencies:\n    foo \"^1.0.0\"\n\n" +
+						"foo@^1.0.0:\n  version \"1.1.0\"\n",
+				})
+				resolver := mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveOutdated(gomock.Any(),
+					map[string]string{"foo": "^1.0.0", "bar": "^1.0.0"},
+					map[string]string{"foo": "1.1.0", "bar": "1.0.0"},
+				).Return([]npm.Outdated{}, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "{\"outdated\":[]}\n",
+		},
+		{
+			name: "manifest with skipped dependencies",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				body := `{"dependencies":{"foo":"^1.0.0","bar":"npm:baz@^1.0.0"}}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest/outdated", strings.NewReader(body))
+				resolver := mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolveOutdated(gomock.Any(), map[string]string{"foo": "^1.0.0"}, map[string]string{}).Return([]npm.Outdated{}, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"outdated":[],"skipped":[{"name":"bar","spec":"npm:baz@^1.0.0"}]}` + "\n",
+		},
+		{
+			name: "manifest too large",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				body := `{"name":"` + strings.Repeat("a", 33<<20) + `"}`
+				req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/manifest/outdated", strings.NewReader(body))
+				return req, mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:body-too-large\",\"title\":\"Request body too large\",\"status\":413,\"detail\":\"manifest too large\",\"code\":\"body-too-large\"}\n",
+		},
+		{
+			name: "upload too large",
+			setup: func(tb testing.TB) (*http.Request, handler.OutdatedResolver) {
+				tb.Helper()
+				req := newMultipartRequest(tb, map[string]string{"manifest": driftManifest, "lockfile": strings.Repeat("a", 33<<20)})
+				return req, mockshandler.NewMockOutdatedResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:body-too-large\",\"title\":\"Request body too large\",\"status\":413,\"detail\":\"upload too large\",\"code\":\"body-too-large\"}\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, resolver := tc.setup(t)
+
+			h := handler.ManifestOutdated(slog.DiscardHandler, resolver)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+const (
+	// mediaTypeProblem is the media type of the RFC 9457 problem details of the error responses.
+	mediaTypeProblem = "application/problem+json"
+	// problemTypePrefix is the prefix of the problem type URIs, followed by the problem code.
+	problemTypePrefix = "urn:npmjs-deps-fetcher:problem:"
+	// requestIDHeader is the header holding the ID of a request, provided by the client or generated.
+	requestIDHeader = "X-Request-ID"
+)
+
+// requestIDRegexp matches the request IDs provided by the clients that are kept.
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type (
+	// problemType is a kind of error, with a stable machine-readable code, its HTTP status and a short title.
+	problemType struct {
+		code   string
+		status int
+		title  string
+	}
+
+	// problem is an RFC 9457 problem details object, the body of the error responses.
+	problem struct {
+		typ problemType
+
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		// Detail is a human-readable explanation of this occurrence of the problem.
+		Detail string `json:"detail,omitempty"`
+		// Code is the stable machine-readable code of the problem, the suffix of its type.
+		Code string `json:"code"`
+		// Package is the name of the package the problem relates to.
+		Package string `json:"package,omitempty"`
+		// Range is the version or version range the problem relates to.
+		Range string `json:"range,omitempty"`
+		// RequestID is the ID of the request, also sent in the X-Request-ID response header.
+		RequestID string `json:"requestId,omitempty"`
+	}
+
+	// requestIDKey is the context key of the request ID.
+	requestIDKey struct{}
+)
+
+var (
+	problemInternal = problemType{
+		code: "internal-error", status: http.StatusInternalServerError, title: "Internal server error",
+	}
+	problemPackageNotFound = problemType{
+		code: "package-not-found", status: http.StatusNotFound, title: "Package not found",
+	}
+	problemVersionNotFound = problemType{
+		code: "version-not-found", status: http.StatusNotFound, title: "Version not found",
+	}
+	problemNoMatchingVersion = problemType{
+		code: "no-matching-version", status: http.StatusNotFound, title: "No matching version",
+	}
+	problemInvalidDependencySpec = problemType{
+		code: "invalid-dependency-spec", status: http.StatusUnprocessableEntity, title: "Invalid dependency specification",
+	}
+	problemRegistryUnavailable = problemType{
+		code: "registry-unavailable", status: http.StatusBadGateway, title: "Registry unavailable",
+	}
+	problemRegistryRejected = problemType{
+		code: "registry-rejected", status: http.StatusBadGateway, title: "Registry rejected the request",
+	}
+	problemRegistryRateLimited = problemType{
+		code: "registry-rate-limited", status: http.StatusServiceUnavailable, title: "Registry rate limited",
+	}
+	problemRegistryTimeout = problemType{
+		code: "registry-timeout", status: http.StatusGatewayTimeout, title: "Registry timeout",
+	}
+	problemRegistryCircuitOpen = problemType{
+		code: "registry-circuit-open", status: http.StatusServiceUnavailable, title: "Registry circuit open",
+	}
+	problemInvalidConstraint = problemType{
+		code: "invalid-constraint", status: http.StatusBadRequest, title: "Invalid version constraint",
+	}
+	problemInvalidRange = problemType{
+		code: "invalid-range", status: http.StatusBadRequest, title: "Invalid version range",
+	}
+	problemInvalidParameter = problemType{
+		code: "invalid-parameter", status: http.StatusBadRequest, title: "Invalid query parameter",
+	}
+	problemInvalidBody = problemType{
+		code: "invalid-body", status: http.StatusBadRequest, title: "Invalid request body",
+	}
+	problemBodyTooLarge = problemType{
+		code: "body-too-large", status: http.StatusRequestEntityTooLarge, title: "Request body too large",
+	}
+	problemUnsupportedLockfile = problemType{
+		code: "unsupported-lockfile", status: http.StatusUnsupportedMediaType, title: "Unsupported lockfile",
+	}
+	problemUnsupportedFormat = problemType{
+		code: "unsupported-format", status: http.StatusNotAcceptable, title: "Unsupported format",
+	}
+)
+
+// RequestID is a middleware attaching an ID to every request, from the X-Request-ID request header
+// when it is a valid ID, or else generated. The ID is sent back in the X-Request-ID response header,
+// and in the problem details of the error responses.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(requestIDHeader)
+		if !requestIDRegexp.MatchString(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), requestIDKey{}, id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// writeProblem writes the problem details of an error response, completed with its type and the request ID.
+func writeProblem(w http.ResponseWriter, req *http.Request, log *slog.Logger, p problem) {
+	p = p.typed()
+	p.RequestID, _ = req.Context().Value(requestIDKey{}).(string)
+
+	w.Header().Set("Content-Type", mediaTypeProblem)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Error("failed to write error", slog.String("error", err.Error()))
+	}
+}
+
+// typed returns the problem completed with the members of its type.
+func (p problem) typed() problem {
+	p.Type = problemTypePrefix + p.typ.code
+	p.Title = p.typ.title
+	p.Status = p.typ.status
+	p.Code = p.typ.code
+	return p
+}
+
+// writeResolutionProblem writes the problem details of the failed resolution of a package version range,
+// or of a project when the package is empty.
+func writeResolutionProblem(w http.ResponseWriter, req *http.Request, log *slog.Logger, err error, pkgName, rng string) {
+	p := resolutionProblem(err, pkgName, rng)
+
+	attrs := []any{slog.String("name", pkgName), slog.String("range", rng), slog.String("error", err.Error())}
+	switch p.typ.status {
+	case http.StatusInternalServerError:
+		log.Error("deps resolution error", attrs...)
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		log.Warn("registry error", attrs...)
+	default:
+		log.Debug("deps resolution failure", attrs...)
+	}
+	writeProblem(w, req, log, p)
+}
+
+// resolutionProblem returns the problem details of the failed resolution of a package version range, or of
+// a project when the package is empty. The problem relates to the package and the range at fault, like
+// a transitive dependency without matching version, or else to the requested ones. The detail of the
+// internal errors is left out.
+func resolutionProblem(err error, pkgName, rng string) problem {
+	p := problem{typ: problemInternal, Package: pkgName, Range: rng}
+	var (
+		versionNotFoundErr *npm.VersionNotFoundError
+		packageNotFoundErr *npm.PackageNotFoundError
+		noMatchingErr      *semverutil.NoMatchingVersionError
+		invalidSpecErr     *npm.InvalidDependencySpecError
+		registryErr        *npm.RegistryError
+	)
+	switch {
+	case errors.As(err, &versionNotFoundErr):
+		p.typ = problemVersionNotFound
+		p.Package, p.Range = versionNotFoundErr.Name, versionNotFoundErr.Version
+	case errors.As(err, &packageNotFoundErr):
+		p.typ = problemPackageNotFound
+		if packageNotFoundErr.Name != pkgName {
+			p.Package, p.Range = packageNotFoundErr.Name, ""
+		}
+	case errors.Is(err, npm.ErrPackageNotFound):
+		p.typ = problemPackageNotFound
+	case errors.As(err, &noMatchingErr):
+		p.typ = problemNoMatchingVersion
+		if noMatchingErr.Package != "" {
+			p.Package, p.Range = noMatchingErr.Package, noMatchingErr.Range
+		}
+	case errors.As(err, &invalidSpecErr):
+		p.typ = problemInvalidDependencySpec
+		p.Package, p.Range = invalidSpecErr.Name, invalidSpecErr.Spec
+	case errors.Is(err, npm.ErrCircuitOpen):
+		p.typ = problemRegistryCircuitOpen
+	case errors.Is(err, npm.ErrRateLimited):
+		p.typ = problemRegistryRateLimited
+	case errors.Is(err, npm.ErrRegistryTimeout):
+		p.typ = problemRegistryTimeout
+	case errors.Is(err, npm.ErrRegistryUnavailable):
+		p.typ = problemRegistryUnavailable
+	case errors.As(err, &registryErr) && registryErr.StatusCode == http.StatusTooManyRequests:
+		p.typ = problemRegistryRateLimited
+	case errors.As(err, &registryErr) && registryErr.StatusCode >= 400 && registryErr.StatusCode < 500:
+		p.typ = problemRegistryRejected
+	}
+
+	if p.typ != problemInternal {
+		p.Detail = err.Error()
+	}
+	return p
+}
@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+)
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		name              string
+		requestID         string
+		expectedRequestID string
+	}{
+		{
+			name:              "provided request ID",
+			requestID:         "req-42.a:b_c",
+			expectedRequestID: "req-42.a:b_c",
+		},
+		{
+			name: "generated request ID",
+		},
+		{
+			name:      "invalid request ID replaced",
+			requestID: "req 42\"",
+		},
+		{
+			name:      "too long request ID replaced",
+			requestID: strings.Repeat("a", 129),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			h := handler.RequestID(handler.PackageDependents(slog.DiscardHandler, mockshandler.NewMockDependentsIndex(ctrl)))
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/lodash/dependents?range=latest", http.NoBody)
+			req.SetPathValue("packageName", "lodash")
+			if tc.requestID != "" {
+				req.Header.Set("X-Request-ID", tc.requestID)
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			requestID := resp.Header.Get("X-Request-ID")
+			if tc.expectedRequestID != "" {
+				assert.Equal(t, tc.expectedRequestID, requestID)
+			} else {
+				assert.Regexp(t, "^[0-9a-f]{32}$", requestID)
+			}
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			assert.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+
+			var problem map[string]any
+			require.NoError(t, json.Unmarshal(body, &problem))
+			assert.Equal(t, requestID, problem["requestId"])
+			assert.Equal(t, "urn:npmjs-deps-fetcher:problem:invalid-range", problem["type"])
+			assert.Equal(t, "invalid-range", problem["code"])
+			assert.InDelta(t, http.StatusBadRequest, problem["status"], 0)
+		})
+	}
+}
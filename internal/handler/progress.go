@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// mediaTypeEventStream is the media type of Server-Sent Events streams.
+const mediaTypeEventStream = "text/event-stream"
+
+type (
+	// progress is the state of a resolution, sent with every progress event.
+	progress struct {
+		// Resolved is the number of distinct resolved nodes.
+		Resolved int `json:"resolved"`
+		// Pending is the number of started registry fetches that are not done yet.
+		Pending int `json:"pending"`
+	}
+
+	// progressFetch is the data of the fetch-start and fetch-done events.
+	progressFetch struct {
+		Name  string `json:"name"`
+		Error string `json:"error,omitempty"`
+		progress
+	}
+
+	// progressNode is the data of the node events.
+	progressNode struct {
+		npm.NodeResolution
+		progress
+	}
+
+	// progressError is the data of the error event.
+	progressError struct {
+		Error string `json:"error"`
+	}
+
+	// progressDone is the data of the done event, the last event of the stream.
+	progressDone struct {
+		Nodes int `json:"nodes"`
+		Edges int `json:"edges"`
+	}
+)
+
+// PackageProgress is the [http.HandlerFunc] for GET /package/{package}/{version}/progress.
+// It resolves the dependency graph of the package while streaming its progress as Server-Sent Events:
+// fetch-start and fetch-done for every registry fetch, node for every resolved node, error if the resolution
+// fails, and done once it is over. The resolution is cancelled when the client disconnects.
+func PackageProgress(logHandler slog.Handler, resolver GraphResolver) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		pkgName, pkgVersion := req.PathValue("packageName"), req.PathValue("packageVersion")
+
+		constraint, err := semver.NewConstraint(pkgVersion)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			log.Debug("invalid version constraint", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{
+				typ: problemInvalidConstraint, Detail: err.Error(), Package: pkgName, Range: pkgVersion,
+			})
+			return
+		}
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+
+		w.Header().Set("Cache-Control", "no-cache")
+		lw := newLineWriter(w, log, mediaTypeEventStream)
+		lw.cancel = cancel
+
+		streamProgress(ctx, lw, log, resolver, pkgName, constraint)
+	}
+}
+
+// streamProgress resolves the graph of the package, writing the progress events.
+func streamProgress(ctx context.Context, lw *lineWriter, log *slog.Logger, resolver GraphResolver,
+	pkgName string, constraint *semver.Constraints,
+) {
+	var (
+		state progress
+		done  progressDone
+	)
+
+	ctx = npm.WithResolveTrace(ctx, &npm.ResolveTrace{
+		FetchStart: func(name string) {
+			lw.mu.Lock()
+			defer lw.mu.Unlock()
+
+			state.Pending++
+			lw.writeEvent("fetch-start", progressFetch{Name: name, progress: state})
+		},
+		FetchDone: func(name string, err error) {
+			lw.mu.Lock()
+			defer lw.mu.Unlock()
+
+			state.Pending--
+			data := progressFetch{Name: name, progress: state}
+			if err != nil {
+				data.Error = err.Error()
+			}
+			lw.writeEvent("fetch-done", data)
+		},
+		NodeResolved: func(resolution npm.NodeResolution) {
+			lw.mu.Lock()
+			defer lw.mu.Unlock()
+
+			if !resolution.Deduplicated {
+				state.Resolved++
+				done.Nodes++
+			}
+			if resolution.Parent != "" {
+				done.Edges++
+			}
+			lw.writeEvent("node", progressNode{NodeResolution: resolution, progress: state})
+		},
+	})
+
+	_, err := resolver.ResolvePackageGraph(ctx, pkgName, constraint)
+	if ctx.Err() != nil {
+		log.Debug("progress stream cancelled", slog.String("name", pkgName), slog.Any("error", context.Cause(ctx)))
+		return
+	}
+
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	if err != nil {
+		log.Error("streamed deps resolution error", slog.String("error", err.Error()))
+		lw.writeEvent("error", progressError{Error: err.Error()})
+	}
+	lw.writeEvent("done", done)
+}
+
+// writeEvent encodes the data of a Server-Sent Event and flushes it to the client, with the lock held.
+func (lw *lineWriter) writeEvent(event string, data any) {
+	lw.write(func() error {
+		if _, err := fmt.Fprintf(lw.w, "event: %s\ndata: ", event); err != nil {
+			return err
+		}
+		if err := lw.enc.Encode(data); err != nil {
+			return err
+		}
+		_, err := fmt.Fprintln(lw.w)
+		return err
+	})
+}
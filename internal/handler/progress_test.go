@@ -0,0 +1,180 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	mocksnpm "github.com/snyk/npmjs-deps-fetcher/internal/npm/mocks"
+)
+
+// progressEvent is a parsed Server-Sent Event.
+type progressEvent struct {
+	event string
+	data  map[string]any
+}
+
+func TestPackageProgress(t *testing.T) {
+	testCases := []struct {
+		name               string
+		pkgVersion         string
+		setup              func(testing.TB, context.CancelFunc) npm.PackageFetcher
+		expectedStatusCode int
+		expectedEvents     []progressEvent
+		expectedBody       string
+	}{
+		{
+			name:       "invalid version constraint",
+			pkgVersion: "invalid",
+			setup: func(tb testing.TB, _ context.CancelFunc) npm.PackageFetcher {
+				tb.Helper()
+				return mocksnpm.NewMockPackageFetcher(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-constraint\",\"title\":\"Invalid version constraint\",\"status\":400,\"detail\":\"improper constraint: invalid\",\"code\":\"invalid-constraint\",\"package\":\"app\",\"range\":\"invalid\"}\n",
+		},
+		{
+			name:       "package not found",
+			pkgVersion: "1.0.0",
+			setup: func(tb testing.TB, _ context.CancelFunc) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").Return(nil, npm.ErrPackageNotFound)
+				return fetcher
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedEvents: []progressEvent{
+				{event: "fetch-start", data: map[string]any{"name": "app", "resolved": 0.0, "pending": 1.0}},
+				{event: "fetch-done", data: map[string]any{"name": "app", "error": "package not found", "resolved": 0.0, "pending": 0.0}},
+				{event: "error", data: map[string]any{"error": "fetch package meta app: package not found"}},
+				{event: "done", data: map[string]any{"nodes": 0.0, "edges": 0.0}},
+			},
+		},
+		{
+			name:       "resolution progress",
+			pkgVersion: "1.0.0",
+			setup: func(tb testing.TB, _ context.CancelFunc) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").Return(&npm.PackageMeta{
+					Name:     "app",
+					Versions: map[string]npm.Package{"1.0.0": {Name: "app", Version: "1.0.0"}},
+				}, nil)
+				fetcher.EXPECT().FetchPackage(gomock.Any(), "app", "1.0.0").Return(&npm.Package{
+					Name: "app", Version: "1.0.0", Dependencies: map[string]string{"foo": "^1.0.0"},
+				}, nil)
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+					Name:     "foo",
+					Versions: map[string]npm.Package{"1.2.0": {Name: "foo", Version: "1.2.0"}},
+				}, nil)
+				return fetcher
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedEvents: []progressEvent{
+				{event: "fetch-start", data: map[string]any{"name": "app", "resolved": 0.0, "pending": 1.0}},
+				{event: "fetch-done", data: map[string]any{"name": "app", "resolved": 0.0, "pending": 0.0}},
+				{event: "fetch-start", data: map[string]any{"name": "app", "resolved": 0.0, "pending": 1.0}},
+				{event: "fetch-done", data: map[string]any{"name": "app", "resolved": 0.0, "pending": 0.0}},
+				{event: "node", data: map[string]any{"name": "app", "version": "1.0.0", "resolved": 1.0, "pending": 0.0}},
+				{event: "fetch-start", data: map[string]any{"name": "foo", "resolved": 1.0, "pending": 1.0}},
+				{event: "fetch-done", data: map[string]any{"name": "foo", "resolved": 1.0, "pending": 0.0}},
+				{event: "node", data: map[string]any{
+					"name": "foo", "version": "1.2.0", "parent": "app@1.0.0", "constraint": "^1.0.0", "resolved": 2.0, "pending": 0.0,
+				}},
+				{event: "done", data: map[string]any{"nodes": 2.0, "edges": 1.0}},
+			},
+		},
+		{
+			name:       "client disconnected",
+			pkgVersion: "1.0.0",
+			setup: func(tb testing.TB, cancel context.CancelFunc) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").DoAndReturn(
+					func(ctx context.Context, _ string) (*npm.PackageMeta, error) {
+						cancel()
+						<-ctx.Done()
+						return nil, ctx.Err()
+					})
+				return fetcher
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedEvents: []progressEvent{
+				{event: "fetch-start", data: map[string]any{"name": "app", "resolved": 0.0, "pending": 1.0}},
+				{event: "fetch-done", data: map[string]any{"name": "app", "error": "context canceled", "resolved": 0.0, "pending": 0.0}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+
+			req := httptest.NewRequestWithContext(ctx, http.MethodGet,
+				"http://localhost:8080/package/app/"+tc.pkgVersion+"/progress", http.NoBody)
+			req.SetPathValue("packageName", "app")
+			req.SetPathValue("packageVersion", tc.pkgVersion)
+
+			h := handler.PackageProgress(slog.DiscardHandler, npm.NewResolver(tc.setup(t, cancel)))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+
+			if tc.expectedEvents == nil {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedBody, string(body))
+				return
+			}
+
+			assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+			assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+			assert.True(t, w.Flushed)
+			assert.Equal(t, tc.expectedEvents, parseEvents(t, resp.Body))
+		})
+	}
+}
+
+// parseEvents parses the events of a Server-Sent Events stream, with a single data line each.
+func parseEvents(tb testing.TB, r io.Reader) []progressEvent {
+	tb.Helper()
+
+	var (
+		events []progressEvent
+		event  progressEvent
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			events = append(events, event)
+			event = progressEvent{}
+		case strings.HasPrefix(line, "event: "):
+			event.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(tb, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event.data))
+		default:
+			require.NoError(tb, errors.New("unexpected line "+line))
+		}
+	}
+	require.NoError(tb, scanner.Err())
+	return events
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+// RateLimit is the [http.HandlerFunc] for GET /debug/ratelimit.
+// It responds with the activity of the rate limiter of the registry requests, by registry host.
+func RateLimit(logHandler slog.Handler, registry RegistryRateLimit) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(registry.RateLimitStats()); err != nil {
+			log.Error("rate limit encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+			return
+		}
+	}
+}
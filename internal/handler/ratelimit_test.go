@@ -0,0 +1,61 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestRateLimit(t *testing.T) {
+	testCases := []struct {
+		name         string
+		stats        map[string]npm.RateLimitStats
+		expectedBody string
+	}{
+		{
+			name:         "no requests",
+			stats:        map[string]npm.RateLimitStats{},
+			expectedBody: `{}`,
+		},
+		{
+			name: "requests by host",
+			stats: map[string]npm.RateLimitStats{
+				"registry.npmjs.org": {Requests: 12, Queued: 3, Waiting: 1, QueuedSeconds: 0.5},
+				"npm.acme.internal":  {Requests: 2, Rejected: 1},
+			},
+			expectedBody: `{"registry.npmjs.org":{"requests":12,"queued":3,"waiting":1,"rejected":0,"queuedSeconds":0.5},` +
+				`"npm.acme.internal":{"requests":2,"queued":0,"waiting":0,"rejected":1,"queuedSeconds":0}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := mockshandler.NewMockRegistryRateLimit(gomock.NewController(t))
+			registry.EXPECT().RateLimitStats().Return(tc.stats)
+			h := handler.RateLimit(slog.DiscardHandler, registry)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/debug/ratelimit", http.NoBody)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			assert.JSONEq(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+type (
+	// semverBatch is the request body of the semver endpoints, holding the items to evaluate.
+	semverBatch[T any] struct {
+		Items []T `json:"items"`
+	}
+
+	// semverResults is the response body of the semver endpoints, with a result for every item, in the same order.
+	semverResults[T any] struct {
+		Results []T `json:"results"`
+	}
+
+	// satisfiesItem is a version to check against a range.
+	satisfiesItem struct {
+		Version string `json:"version"`
+		Range   string `json:"range"`
+	}
+
+	// satisfiesResult reports whether a version satisfies a range.
+	satisfiesResult struct {
+		satisfiesItem
+		Satisfies bool   `json:"satisfies"`
+		Error     string `json:"error,omitempty"`
+	}
+
+	// satisfyingItem is a list of versions to match against a range.
+	satisfyingItem struct {
+		Range    string   `json:"range"`
+		Versions []string `json:"versions"`
+	}
+
+	// satisfyingResult holds the lowest and the highest versions of a list satisfying a range,
+	// omitted when no version satisfies it.
+	satisfyingResult struct {
+		Range string `json:"range"`
+		Min   string `json:"min,omitempty"`
+		Max   string `json:"max,omitempty"`
+		Error string `json:"error,omitempty"`
+	}
+
+	// rangeItem is a range to validate.
+	rangeItem struct {
+		Range string `json:"range"`
+	}
+
+	// rangeResult reports whether a range is valid, with its normalized form. The error of a valid range
+	// reports why it could not be normalized.
+	rangeResult struct {
+		Range      string `json:"range"`
+		Valid      bool   `json:"valid"`
+		Normalized string `json:"normalized,omitempty"`
+		Error      string `json:"error,omitempty"`
+	}
+
+	// compareItem is a pair of versions to compare.
+	compareItem struct {
+		A string `json:"a"`
+		B string `json:"b"`
+	}
+
+	// compareResult is the comparison of a pair of versions, -1, 0 or 1 when a is lower, equal or higher than b.
+	compareResult struct {
+		compareItem
+		Result int    `json:"result"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	// sortItem is a list of versions to sort.
+	sortItem struct {
+		Versions   []string `json:"versions"`
+		Descending bool     `json:"descending,omitempty"`
+	}
+
+	// sortResult is a sorted list of versions.
+	sortResult struct {
+		Versions []string `json:"versions"`
+		Error    string   `json:"error,omitempty"`
+	}
+)
+
+func (b *semverBatch[T]) size() int { return len(b.Items) }
+
+// SemverSatisfies is the [http.HandlerFunc] for POST /semver/satisfies.
+// It reports whether every version satisfies its range.
+func SemverSatisfies(logHandler slog.Handler, maxBatchSize int) http.HandlerFunc {
+	return semverHandler(logHandler, maxBatchSize, func(item satisfiesItem) satisfiesResult {
+		result := satisfiesResult{satisfiesItem: item}
+
+		constraint, err := semver.NewConstraint(item.Range)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		v, err := semver.StrictNewVersion(item.Version)
+		if err != nil {
+			result.Error = fmt.Sprintf("version %s: %s", item.Version, err)
+			return result
+		}
+
+		result.Satisfies = constraint.Check(v)
+		return result
+	})
+}
+
+// SemverSatisfying is the [http.HandlerFunc] for POST /semver/satisfying.
+// It responds with the lowest and the highest versions of every list that satisfy its range.
+func SemverSatisfying(logHandler slog.Handler, maxBatchSize int) http.HandlerFunc {
+	return semverHandler(logHandler, maxBatchSize, func(item satisfyingItem) satisfyingResult {
+		result := satisfyingResult{Range: item.Range}
+
+		constraint, err := semver.NewConstraint(item.Range)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+
+		if result.Min, result.Max, err = semverutil.SatisfyingRange(constraint, slices.Values(item.Versions)); err != nil {
+			result.Error = err.Error()
+		}
+		return result
+	})
+}
+
+// SemverRanges is the [http.HandlerFunc] for POST /semver/ranges.
+// It validates every range and normalizes it into its comparator sets.
+func SemverRanges(logHandler slog.Handler, maxBatchSize int) http.HandlerFunc {
+	return semverHandler(logHandler, maxBatchSize, func(item rangeItem) rangeResult {
+		result := rangeResult{Range: item.Range}
+
+		if _, err := semver.NewConstraint(item.Range); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Valid = true
+
+		rng, err := semverutil.ParseRange(item.Range)
+		if err != nil {
+			result.Error = "cannot normalize: " + err.Error()
+			return result
+		}
+		result.Normalized = rng.String()
+		return result
+	})
+}
+
+// SemverCompare is the [http.HandlerFunc] for POST /semver/compare.
+// It compares every pair of versions by semver precedence.
+func SemverCompare(logHandler slog.Handler, maxBatchSize int) http.HandlerFunc {
+	return semverHandler(logHandler, maxBatchSize, func(item compareItem) compareResult {
+		result := compareResult{compareItem: item}
+
+		var err error
+		if result.Result, err = semverutil.CompareVersions(item.A, item.B); err != nil {
+			result.Error = err.Error()
+		}
+		return result
+	})
+}
+
+// SemverSort is the [http.HandlerFunc] for POST /semver/sort.
+// It sorts every list of versions by semver precedence, in increasing order unless descending is set.
+func SemverSort(logHandler slog.Handler, maxBatchSize int) http.HandlerFunc {
+	return semverHandler(logHandler, maxBatchSize, func(item sortItem) sortResult {
+		result := sortResult{Versions: slices.Clone(item.Versions)}
+		if result.Versions == nil {
+			result.Versions = []string{}
+		}
+
+		if err := semverutil.SortVersions(result.Versions); err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		if item.Descending {
+			slices.Reverse(result.Versions)
+		}
+		return result
+	})
+}
+
+// semverHandler is the [http.HandlerFunc] of a semver endpoint, which evaluates every item of the request body.
+// The evaluation errors are reported in the result of their item, so they do not fail the whole batch.
+func semverHandler[Item, Result any](logHandler slog.Handler, maxBatchSize int, eval func(Item) Result) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var batch semverBatch[Item]
+		if !decodeBatchRequest(w, req, log, &batch, maxBatchSize) {
+			return
+		}
+
+		resp := semverResults[Result]{Results: make([]Result, 0, len(batch.Items))}
+		for _, item := range batch.Items {
+			resp.Results = append(resp.Results, eval(item))
+		}
+
+		// Ranges are full of comparison operators, which are kept readable.
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(resp); err != nil {
+			log.Error("semver encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+			return
+		}
+	}
+}
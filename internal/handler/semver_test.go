@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+)
+
+func TestSemver(t *testing.T) {
+	testCases := []struct {
+		name               string
+		handler            func(slog.Handler, int) http.HandlerFunc
+		body               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "invalid request",
+			handler:            handler.SemverSatisfies,
+			body:               `{"items":{}}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"invalid batch request: json: cannot unmarshal object into Go struct field semverBatch[github.com/snyk/npmjs-deps-fetcher/internal/handler.satisfiesItem].items of type []handler.satisfiesItem\",\"code\":\"invalid-body\"}\n",
+		},
+		{
+			name:               "too many items",
+			handler:            handler.SemverCompare,
+			body:               `{"items":[{"a":"1.0.0","b":"1.0.0"},{"a":"1.0.0","b":"1.0.0"},{"a":"1.0.0","b":"1.0.0"},{"a":"1.0.0","b":"1.0.0"}]}`,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:body-too-large\",\"title\":\"Request body too large\",\"status\":413,\"detail\":\"batch too large\",\"code\":\"body-too-large\"}\n",
+		},
+		{
+			name:    "satisfies",
+			handler: handler.SemverSatisfies,
+			body: `{"items":[{"version":"1.2.3","range":"^1.0.0"},{"version":"2.0.0-beta.1","range":"^1.0.0"},` +
+				`{"version":"1.2","range":"^1.0.0"}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"results":[{"version":"1.2.3","range":"^1.0.0","satisfies":true},` +
+				`{"version":"2.0.0-beta.1","range":"^1.0.0","satisfies":false},` +
+				`{"version":"1.2","range":"^1.0.0","satisfies":false,"error":"version 1.2: Invalid Semantic Version"}]}` + "\n",
+		},
+		{
+			name:    "satisfying",
+			handler: handler.SemverSatisfying,
+			body: `{"items":[{"range":"~1.2.0","versions":["1.2.0","1.2.9","1.3.0"]},{"range":">=3","versions":["1.2.0"]},` +
+				`{"range":"latest","versions":["1.2.0"]}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"results":[{"range":"~1.2.0","min":"1.2.0","max":"1.2.9"},{"range":">=3"},` +
+				`{"range":"latest","error":"improper constraint: latest"}]}` + "\n",
+		},
+		{
+			name:               "ranges",
+			handler:            handler.SemverRanges,
+			body:               `{"items":[{"range":"^1.2 || 3.x"},{"range":"latest"},{"range":"=>1.0.0"}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"results":[{"range":"^1.2 || 3.x","valid":true,"normalized":">=1.2.0 <2.0.0 || >=3.0.0 <4.0.0"},` +
+				`{"range":"latest","valid":false,"error":"improper constraint: latest"},` +
+				`{"range":"=>1.0.0","valid":true,"error":"cannot normalize: range =>1.0.0: invalid version \">1.0.0\": ` +
+				`strconv.ParseUint: parsing \">1\": invalid syntax"}]}` + "\n",
+		},
+		{
+			name:               "compare",
+			handler:            handler.SemverCompare,
+			body:               `{"items":[{"a":"1.2.3","b":"1.10.0"},{"a":"1.2.3","b":"1.2.3-rc.1"},{"a":"1.2.3","b":"latest"}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"results":[{"a":"1.2.3","b":"1.10.0","result":-1},{"a":"1.2.3","b":"1.2.3-rc.1","result":1},` +
+				`{"a":"1.2.3","b":"latest","result":0,"error":"version latest: Invalid Semantic Version"}]}` + "\n",
+		},
+		{
+			name:               "sort",
+			handler:            handler.SemverSort,
+			body:               `{"items":[{"versions":["1.10.0","1.2.0","1.2.0-rc.1"]},{"versions":["1.10.0","1.2.0"],"descending":true},{}]}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"results":[{"versions":["1.2.0-rc.1","1.2.0","1.10.0"]},{"versions":["1.10.0","1.2.0"]},` +
+				`{"versions":[]}]}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/semver", strings.NewReader(tc.body))
+
+			h := tc.handler(slog.DiscardHandler, 3)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+const (
+	// mediaTypeNDJSON is the media type of newline delimited JSON streams.
+	mediaTypeNDJSON = "application/x-ndjson"
+	// streamWriteTimeout is the write deadline of every line of a streamed response, which replaces
+	// the server write timeout of the whole response.
+	streamWriteTimeout = 30 * time.Second
+)
+
+type (
+	// streamNode is a line of a streamed resolution, for a resolved node.
+	streamNode struct {
+		Type string `json:"type"`
+		npm.NodeResolution
+	}
+
+	// streamSummary is the last line of a streamed resolution.
+	streamSummary struct {
+		Type   string   `json:"type"`
+		Nodes  int      `json:"nodes"`
+		Edges  int      `json:"edges"`
+		Errors []string `json:"errors"`
+	}
+
+	// lineWriter writes the lines of a streamed response, flushing every line.
+	lineWriter struct {
+		mu          sync.Mutex
+		w           http.ResponseWriter
+		rc          *http.ResponseController
+		enc         *json.Encoder
+		log         *slog.Logger
+		contentType string
+		// cancel, when set, is called when a line cannot be written, like when the client disconnected.
+		cancel context.CancelFunc
+		// started reports whether the response status and the first line were written.
+		started bool
+	}
+)
+
+// streamPackageGraph resolves the graph of the package, writing every resolved node as a line of
+// a newline delimited JSON stream as soon as it is resolved, then a summary line with the totals and the errors.
+// Errors happening before the root node is resolved, like an unknown package, are regular error responses.
+func streamPackageGraph(w http.ResponseWriter, req *http.Request, log *slog.Logger, resolver GraphResolver,
+	pkgName string, constraint *semver.Constraints,
+) {
+	lw := newLineWriter(w, log, mediaTypeNDJSON)
+	summary := streamSummary{Type: "summary", Errors: []string{}}
+
+	ctx := npm.WithResolveTrace(req.Context(), &npm.ResolveTrace{
+		NodeResolved: func(resolution npm.NodeResolution) {
+			lw.mu.Lock()
+			if !resolution.Deduplicated {
+				summary.Nodes++
+			}
+			if resolution.Parent != "" {
+				summary.Edges++
+			}
+			lw.mu.Unlock()
+
+			lw.writeLine(streamNode{Type: "node", NodeResolution: resolution})
+		},
+	})
+
+	_, err := resolver.ResolvePackageGraph(ctx, pkgName, constraint)
+
+	lw.mu.Lock()
+	started, final := lw.started, summary
+	lw.mu.Unlock()
+
+	switch {
+	case err == nil:
+	case !started:
+		writeResolutionProblem(w, req, log, err, pkgName, constraint.String())
+		return
+	default:
+		log.Error("streamed deps resolution error", slog.String("error", err.Error()))
+		final.Errors = append(final.Errors, err.Error())
+	}
+
+	lw.writeLine(final)
+}
+
+func newLineWriter(w http.ResponseWriter, log *slog.Logger, contentType string) *lineWriter {
+	return &lineWriter{w: w, rc: http.NewResponseController(w), enc: json.NewEncoder(w), log: log, contentType: contentType}
+}
+
+// writeLine encodes the value as a line and flushes it to the client.
+func (lw *lineWriter) writeLine(v any) {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+
+	lw.write(func() error { return lw.enc.Encode(v) })
+}
+
+// write starts the response if needed, then writes and flushes a line, with the lock held.
+func (lw *lineWriter) write(encode func() error) {
+	if !lw.started {
+		lw.w.Header().Set("Content-Type", lw.contentType)
+		lw.w.WriteHeader(http.StatusOK)
+		lw.started = true
+	}
+
+	if err := lw.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		lw.log.Debug("stream write deadline error", slog.String("error", err.Error()))
+	}
+	if err := encode(); err != nil {
+		lw.log.Debug("stream line encoding error", slog.String("error", err.Error()))
+		if lw.cancel != nil {
+			lw.cancel()
+		}
+		return
+	}
+	if err := lw.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		lw.log.Debug("stream flush error", slog.String("error", err.Error()))
+	}
+}
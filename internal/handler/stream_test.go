@@ -0,0 +1,126 @@
+package handler_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	mocksnpm "github.com/snyk/npmjs-deps-fetcher/internal/npm/mocks"
+)
+
+func TestPackageVersion_NDJSON(t *testing.T) {
+	testCases := []struct {
+		name               string
+		setup              func(testing.TB) npm.PackageFetcher
+		expectedStatusCode int
+		expectedLines      []map[string]any
+		expectedBody       string
+	}{
+		{
+			name: "package not found",
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").Return(nil, npm.ErrPackageNotFound)
+				return fetcher
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:package-not-found\",\"title\":\"Package not found\",\"status\":404,\"detail\":\"fetch package meta app: package not found\",\"code\":\"package-not-found\",\"package\":\"app\",\"range\":\"1.0.0\"}\n",
+		},
+		{
+			name: "error after the root node",
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").Return(&npm.PackageMeta{
+					Name:     "app",
+					Versions: map[string]npm.Package{"1.0.0": {Name: "app", Version: "1.0.0"}},
+				}, nil)
+				fetcher.EXPECT().FetchPackage(gomock.Any(), "app", "1.0.0").Return(&npm.Package{
+					Name: "app", Version: "1.0.0", Dependencies: map[string]string{"foo": "^1.0.0"},
+				}, nil)
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(nil, errors.New("something bad happened"))
+				return fetcher
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedLines: []map[string]any{
+				{"type": "node", "name": "app", "version": "1.0.0"},
+				{"type": "summary", "nodes": 1.0, "edges": 0.0, "errors": []any{"fetch package meta foo: something bad happened"}},
+			},
+		},
+		{
+			name: "streamed nodes",
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").Return(&npm.PackageMeta{
+					Name:     "app",
+					Versions: map[string]npm.Package{"1.0.0": {Name: "app", Version: "1.0.0"}},
+				}, nil)
+				fetcher.EXPECT().FetchPackage(gomock.Any(), "app", "1.0.0").Return(&npm.Package{
+					Name: "app", Version: "1.0.0", Dependencies: map[string]string{"foo": "^1.0.0"},
+				}, nil)
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+					Name:     "foo",
+					Versions: map[string]npm.Package{"1.2.0": {Name: "foo", Version: "1.2.0"}},
+				}, nil)
+				return fetcher
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedLines: []map[string]any{
+				{"type": "node", "name": "app", "version": "1.0.0"},
+				{"type": "node", "name": "foo", "version": "1.2.0", "parent": "app@1.0.0", "constraint": "^1.0.0"},
+				{"type": "summary", "nodes": 2.0, "edges": 1.0, "errors": []any{}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0", http.NoBody)
+			req.SetPathValue("packageName", "app")
+			req.SetPathValue("packageVersion", "1.0.0")
+			req.Header.Set("Accept", "application/x-ndjson")
+
+			resolver := npm.NewResolver(tc.setup(t))
+			h := handler.PackageVersion(slog.DiscardHandler, mockshandler.NewMockPackageResolver(gomock.NewController(t)), resolver)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+
+			if tc.expectedLines == nil {
+				body, err := io.ReadAll(resp.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expectedBody, string(body))
+				return
+			}
+
+			assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+			assert.True(t, w.Flushed)
+
+			var lines []map[string]any
+			scanner := bufio.NewScanner(resp.Body)
+			for scanner.Scan() {
+				var line map[string]any
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+				lines = append(lines, line)
+			}
+			assert.Equal(t, tc.expectedLines, lines)
+		})
+	}
+}
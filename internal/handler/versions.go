@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+const (
+	// defaultVersionsPerPage is the number of versions of a page of the versions endpoint, unless requested otherwise.
+	defaultVersionsPerPage = 100
+	// maxVersionsPerPage is the maximum number of versions of a page of the versions endpoint.
+	maxVersionsPerPage = 1000
+)
+
+// versionsResponse is the response body of the versions endpoint.
+type versionsResponse struct {
+	Name     string            `json:"name"`
+	DistTags map[string]string `json:"distTags"`
+	Versions []npm.VersionInfo `json:"versions"`
+	// Total is the number of versions of all the pages.
+	Total   int `json:"total"`
+	Page    int `json:"page"`
+	PerPage int `json:"perPage"`
+}
+
+// PackageVersions is the [http.HandlerFunc] for GET /package/{package}/versions.
+// It lists the published versions of the package sorted by semver precedence, with their publish time,
+// deprecation message and dist-tags. The optional range query parameter only keeps the versions it matches,
+// and the page and perPage query parameters paginate the versions.
+func PackageVersions(logHandler slog.Handler, fetcher PackageMetaFetcher) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		pkgName := req.PathValue("packageName")
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var constraint *semver.Constraints
+		if r := req.URL.Query().Get("range"); r != "" {
+			var err error
+			if constraint, err = semver.NewConstraint(r); err != nil {
+				log.Debug("invalid range", slog.String("error", err.Error()))
+				writeProblem(w, req, log, problem{
+					typ: problemInvalidRange, Detail: err.Error(), Package: pkgName, Range: r,
+				})
+				return
+			}
+		}
+
+		page, err := parsePositiveInt(req, "page", 1)
+		if err != nil {
+			log.Debug("invalid page", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: "invalid page: " + err.Error()})
+			return
+		}
+		perPage, err := parsePositiveInt(req, "perPage", defaultVersionsPerPage)
+		if err != nil || perPage > maxVersionsPerPage {
+			log.Debug("invalid perPage", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: "invalid perPage"})
+			return
+		}
+
+		meta, err := fetcher.FetchPackageMeta(ctx, pkgName)
+		if err != nil {
+			writeResolutionProblem(w, req, log, err, pkgName, "")
+			return
+		}
+
+		versions := meta.ListVersions(constraint)
+		start, end := len(versions), len(versions)
+		if page-1 <= len(versions)/perPage {
+			start = (page - 1) * perPage
+			end = min(start+perPage, len(versions))
+		}
+
+		resp := versionsResponse{
+			Name:     meta.Name,
+			DistTags: meta.DistTags,
+			Versions: versions[start:end],
+			Total:    len(versions),
+			Page:     page,
+			PerPage:  perPage,
+		}
+		if resp.DistTags == nil {
+			resp.DistTags = map[string]string{}
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Error("versions encoding error", slog.Any("error", err))
+			writeProblem(w, req, log, problem{typ: problemInternal})
+			return
+		}
+	}
+}
+
+// parsePositiveInt parses the query parameter as a positive integer, or returns the default value when it is missing.
+func parsePositiveInt(req *http.Request, param string, defaultValue int) (int, error) {
+	s := req.URL.Query().Get(param)
+	if s == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if n < 1 {
+		return 0, errors.New(param + " query parameter must be a positive integer")
+	}
+	return n, nil
+}
@@ -0,0 +1,154 @@
+package handler_test
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestPackageVersions(t *testing.T) {
+	meta := &npm.PackageMeta{
+		Name:     "foo",
+		DistTags: map[string]string{"latest": "1.10.0"},
+		Versions: map[string]npm.Package{
+			"1.0.0":  {Name: "foo", Version: "1.0.0", Deprecated: "use 1.10.0"},
+			"1.2.0":  {Name: "foo", Version: "1.2.0"},
+			"1.10.0": {Name: "foo", Version: "1.10.0"},
+			"2.0.0":  {Name: "foo", Version: "2.0.0"},
+		},
+		Time: npm.PublishTimes{"1.10.0": time.Date(2021, 2, 20, 15, 42, 16, 0, time.UTC)},
+	}
+
+	testCases := []struct {
+		name               string
+		query              string
+		setup              func(testing.TB) handler.PackageMetaFetcher
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:  "invalid range",
+			query: "?range=latest",
+			setup: func(tb testing.TB) handler.PackageMetaFetcher {
+				tb.Helper()
+				return mockshandler.NewMockPackageMetaFetcher(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-range\",\"title\":\"Invalid version range\",\"status\":400,\"detail\":\"improper constraint: latest\",\"code\":\"invalid-range\",\"package\":\"foo\",\"range\":\"latest\"}\n",
+		},
+		{
+			name:  "invalid page",
+			query: "?page=0",
+			setup: func(tb testing.TB) handler.PackageMetaFetcher {
+				tb.Helper()
+				return mockshandler.NewMockPackageMetaFetcher(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-parameter\",\"title\":\"Invalid query parameter\",\"status\":400,\"detail\":\"invalid page: page query parameter must be a positive integer\",\"code\":\"invalid-parameter\"}\n",
+		},
+		{
+			name:  "too many versions per page",
+			query: "?perPage=1001",
+			setup: func(tb testing.TB) handler.PackageMetaFetcher {
+				tb.Helper()
+				return mockshandler.NewMockPackageMetaFetcher(gomock.NewController(tb))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-parameter\",\"title\":\"Invalid query parameter\",\"status\":400,\"detail\":\"invalid perPage\",\"code\":\"invalid-parameter\"}\n",
+		},
+		{
+			name: "package not found",
+			setup: func(tb testing.TB) handler.PackageMetaFetcher {
+				tb.Helper()
+				fetcher := mockshandler.NewMockPackageMetaFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(nil, npm.ErrPackageNotFound)
+				return fetcher
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:package-not-found\",\"title\":\"Package not found\",\"status\":404,\"detail\":\"package not found\",\"code\":\"package-not-found\",\"package\":\"foo\"}\n",
+		},
+		{
+			name: "fetch failed",
+			setup: func(tb testing.TB) handler.PackageMetaFetcher {
+				tb.Helper()
+				fetcher := mockshandler.NewMockPackageMetaFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(nil, errors.New("something bad happened"))
+				return fetcher
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:internal-error\",\"title\":\"Internal server error\",\"status\":500,\"code\":\"internal-error\",\"package\":\"foo\"}\n",
+		},
+		{
+			name:  "versions in range",
+			query: "?range=%5E1.0.0&page=2&perPage=2",
+			setup: func(tb testing.TB) handler.PackageMetaFetcher {
+				tb.Helper()
+				fetcher := mockshandler.NewMockPackageMetaFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(meta, nil)
+				return fetcher
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"name":"foo","distTags":{"latest":"1.10.0"},"versions":[` +
+				`{"version":"1.10.0","published":"2021-02-20T15:42:16Z","distTags":["latest"]}],` +
+				`"total":3,"page":2,"perPage":2}` + "\n",
+		},
+		{
+			name: "all versions",
+			setup: func(tb testing.TB) handler.PackageMetaFetcher {
+				tb.Helper()
+				fetcher := mockshandler.NewMockPackageMetaFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(meta, nil)
+				return fetcher
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"name":"foo","distTags":{"latest":"1.10.0"},"versions":[` +
+				`{"version":"1.0.0","deprecated":"use 1.10.0"},{"version":"1.2.0"},` +
+				`{"version":"1.10.0","published":"2021-02-20T15:42:16Z","distTags":["latest"]},{"version":"2.0.0"}],` +
+				`"total":4,"page":1,"perPage":100}` + "\n",
+		},
+		{
+			name:  "page out of range",
+			query: "?page=9223372036854775807",
+			setup: func(tb testing.TB) handler.PackageMetaFetcher {
+				tb.Helper()
+				fetcher := mockshandler.NewMockPackageMetaFetcher(gomock.NewController(tb))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(meta, nil)
+				return fetcher
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"name":"foo","distTags":{"latest":"1.10.0"},"versions":[],` +
+				`"total":4,"page":9223372036854775807,"perPage":100}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/foo/versions"+tc.query, http.NoBody)
+			req.SetPathValue("packageName", "foo")
+
+			h := handler.PackageVersions(slog.DiscardHandler, tc.setup(t))
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+const (
+	// defaultWhyLimit is the default maximum number of dependency paths returned by the why endpoints.
+	defaultWhyLimit = 100
+	// maxWhyLimit caps the number of dependency paths returned by the why endpoints, for very dense graphs.
+	maxWhyLimit = 1000
+)
+
+type (
+	// whyQuery holds the query parameters of the why endpoints.
+	whyQuery struct {
+		target     string
+		constraint *semver.Constraints
+		limit      int
+	}
+
+	// whyResponse is the response body of the why endpoints.
+	whyResponse struct {
+		Paths     [][]npm.PathStep `json:"paths"`
+		Truncated bool             `json:"truncated"`
+	}
+)
+
+// PackageWhy is the [http.HandlerFunc] for GET /package/{package}/{version}/why.
+// The package query parameter is the name of the target package, and the optional range and limit
+// query parameters filter its versions and cap the number of returned paths.
+func PackageWhy(logHandler slog.Handler, resolver GraphResolver) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		pkgName, pkgVersion := req.PathValue("packageName"), req.PathValue("packageVersion")
+
+		w.Header().Set("Content-Type", "application/json")
+
+		constraint, err := semver.NewConstraint(pkgVersion)
+		if err != nil {
+			log.Debug("invalid version constraint", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{
+				typ: problemInvalidConstraint, Detail: err.Error(), Package: pkgName, Range: pkgVersion,
+			})
+			return
+		}
+
+		query, err := parseWhyQuery(req)
+		if err != nil {
+			log.Debug("invalid why query", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: "invalid query: " + err.Error()})
+			return
+		}
+
+		graph, err := resolver.ResolvePackageGraph(ctx, pkgName, constraint)
+		if err != nil {
+			writeResolutionProblem(w, req, log, err, pkgName, pkgVersion)
+			return
+		}
+
+		writeWhy(w, req, log, graph, query)
+	}
+}
+
+// LockfileWhy is the [http.HandlerFunc] for POST /lockfile/why.
+// It explains the paths of the uploaded lockfile graph, with the query parameters of [PackageWhy].
+func LockfileWhy(logHandler slog.Handler) http.HandlerFunc {
+	log := slog.New(logHandler)
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		query, err := parseWhyQuery(req)
+		if err != nil {
+			log.Debug("invalid why query", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidParameter, Detail: "invalid query: " + err.Error()})
+			return
+		}
+
+		graph, err := npm.ParseLockfile(http.MaxBytesReader(w, req.Body, maxLockfileSize))
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			log.Debug("lockfile too large", slog.Int64("limit", maxBytesErr.Limit))
+			writeProblem(w, req, log, problem{typ: problemBodyTooLarge, Detail: "lockfile too large"})
+			return
+		}
+
+		if errors.Is(err, npm.ErrUnsupportedLockfile) {
+			log.Debug("unsupported lockfile", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemUnsupportedLockfile, Detail: err.Error()})
+			return
+		}
+
+		if err != nil {
+			log.Debug("invalid lockfile", slog.String("error", err.Error()))
+			writeProblem(w, req, log, problem{typ: problemInvalidBody, Detail: err.Error()})
+			return
+		}
+
+		writeWhy(w, req, log, graph, query)
+	}
+}
+
+func parseWhyQuery(req *http.Request) (*whyQuery, error) {
+	values := req.URL.Query()
+	query := &whyQuery{target: values.Get("package"), limit: defaultWhyLimit}
+
+	if query.target == "" {
+		return nil, errors.New("missing package query parameter")
+	}
+
+	if r := values.Get("range"); r != "" {
+		constraint, err := semver.NewConstraint(r)
+		if err != nil {
+			return nil, fmt.Errorf("range query parameter: %w", err)
+		}
+		query.constraint = constraint
+	}
+
+	if l := values.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 || limit > maxWhyLimit {
+			return nil, fmt.Errorf("limit query parameter must be between 1 and %d", maxWhyLimit)
+		}
+		query.limit = limit
+	}
+
+	return query, nil
+}
+
+func writeWhy(w http.ResponseWriter, req *http.Request, log *slog.Logger, graph *npm.Graph, query *whyQuery) {
+	paths, truncated := graph.Paths(query.target, query.constraint, query.limit)
+	if err := json.NewEncoder(w).Encode(whyResponse{Paths: paths, Truncated: truncated}); err != nil {
+		log.Error("paths encoding error", slog.Any("error", err))
+		writeProblem(w, req, log, problem{typ: problemInternal})
+	}
+}
@@ -0,0 +1,159 @@
+package handler_test
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/handler"
+	mockshandler "github.com/snyk/npmjs-deps-fetcher/internal/handler/mocks"
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestPackageWhy(t *testing.T) {
+	testCases := []struct {
+		name               string
+		setup              func(testing.TB) (*http.Request, handler.GraphResolver)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "missing package query parameter",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/why", http.NoBody)
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-parameter\",\"title\":\"Invalid query parameter\",\"status\":400,\"detail\":\"invalid query: missing package query parameter\",\"code\":\"invalid-parameter\"}\n",
+		},
+		{
+			name: "limit out of bounds",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/why?package=foo&limit=5000", http.NoBody)
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				return req, mockshandler.NewMockGraphResolver(gomock.NewController(t))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-parameter\",\"title\":\"Invalid query parameter\",\"status\":400,\"detail\":\"invalid query: limit query parameter must be between 1 and 1000\",\"code\":\"invalid-parameter\"}\n",
+		},
+		{
+			name: "package not found",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/why?package=foo", http.NoBody)
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackageGraph(gomock.Any(), "app", gomock.Any()).Return(nil, npm.ErrPackageNotFound)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:package-not-found\",\"title\":\"Package not found\",\"status\":404,\"detail\":\"package not found\",\"code\":\"package-not-found\",\"package\":\"app\",\"range\":\"1.0.0\"}\n",
+		},
+		{
+			name: "paths found",
+			setup: func(tb testing.TB) (*http.Request, handler.GraphResolver) {
+				tb.Helper()
+				req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/package/app/1.0.0/why?package=foo&range=^1.0.0", http.NoBody)
+				req.SetPathValue("packageName", "app")
+				req.SetPathValue("packageVersion", "1.0.0")
+				resolver := mockshandler.NewMockGraphResolver(gomock.NewController(t))
+				resolver.EXPECT().ResolvePackageGraph(gomock.Any(), "app", gomock.Any()).Return(&npm.Graph{
+					Root: "app@1.0.0",
+					Nodes: map[string]*npm.Node{
+						"app@1.0.0": {
+							Name: "app", Version: "1.0.0",
+							Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"}},
+						},
+						"foo@1.2.0": {Name: "foo", Version: "1.2.0"},
+					},
+				}, nil)
+				return req, resolver
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"paths":[[{"name":"app","version":"1.0.0"},{"name":"foo","version":"1.2.0","constraint":"^1.0.0"}]],` +
+				`"truncated":false}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, resolver := tc.setup(t)
+
+			h := handler.PackageWhy(slog.DiscardHandler, resolver)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
+
+func TestLockfileWhy(t *testing.T) {
+	testCases := []struct {
+		name               string
+		url                string
+		body               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "invalid range",
+			url:                "http://localhost:8080/lockfile/why?package=foo&range=latest",
+			body:               driftLockfile,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-parameter\",\"title\":\"Invalid query parameter\",\"status\":400,\"detail\":\"invalid query: range query parameter: improper constraint: latest\",\"code\":\"invalid-parameter\"}\n",
+		},
+		{
+			name:               "invalid lockfile",
+			url:                "http://localhost:8080/lockfile/why?package=foo",
+			body:               `{"lockfileVersion":3}`,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       "{\"type\":\"urn:npmjs-deps-fetcher:problem:invalid-body\",\"title\":\"Invalid request body\",\"status\":400,\"detail\":\"invalid lockfile: missing packages in package-lock.json\",\"code\":\"invalid-body\"}\n",
+		},
+		{
+			name:               "paths found",
+			url:                "http://localhost:8080/lockfile/why?package=bar",
+			body:               driftLockfile,
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"paths":[[{"name":"app","version":"1.0.0"},{"name":"bar","version":"2.0.0","constraint":"^2.0.0"}]],` +
+				`"truncated":false}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tc.url, strings.NewReader(tc.body))
+
+			h := handler.LockfileWhy(slog.DiscardHandler)
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, req)
+
+			resp := w.Result()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+			assert.Equal(t, tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+type (
+	// PackageQuery is a package name and a version constraint to resolve in a batch.
+	PackageQuery struct {
+		Name       string
+		Constraint *semver.Constraints
+	}
+
+	// BatchResult is the outcome of a [PackageQuery], either its resolved [Graph] or its resolution error.
+	BatchResult struct {
+		Graph *Graph
+		Err   error
+	}
+)
+
+// ResolveBatch resolves the transitive dependencies of every queried package into a deduplicated [Graph],
+// returning the results in the order of the queries. The resolutions run concurrently and share the
+// fetched package metadata, and the failure of a resolution does not interrupt the other ones.
+func (r Resolver) ResolveBatch(ctx context.Context, queries []PackageQuery) []BatchResult {
+	cache := newMetaCache(r.client)
+	results := make([]BatchResult, len(queries))
+
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].Graph, results[i].Err = resolveQuery(ctx, cache, query)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func resolveQuery(ctx context.Context, cache *metaCache, query PackageQuery) (*Graph, error) {
+	meta, err := cache.fetch(ctx, query.Name)
+	if err != nil {
+		return nil, fmt.Errorf("fetch package meta %s: %w", query.Name, err)
+	}
+
+	version, err := resolveHighestVersion(meta, query.Name, query.Constraint.String(), query.Constraint)
+	if err != nil {
+		return nil, fmt.Errorf("resolve highest version of %s@%s: %w", query.Name, query.Constraint, err)
+	}
+
+	root := meta.Versions[version]
+	root.Name, root.Version = query.Name, version
+
+	return resolveGraph(ctx, cache, &root)
+}
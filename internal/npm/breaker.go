@@ -0,0 +1,299 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// The states of a [CircuitBreaker].
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+var _ PackageFetcher = (*CircuitBreaker)(nil)
+
+type (
+	// CircuitBreakerConfig configures the circuit breaker around the requests to the NPM registry.
+	CircuitBreakerConfig struct {
+		// FailureRate is the rate of failed requests, among the last WindowSize requests, opening the circuit.
+		// The circuit never opens when it is zero or less.
+		FailureRate float64 `json:"failureRate"`
+		// WindowSize is the number of the last requests whose failure rate is computed.
+		WindowSize int `json:"windowSize"`
+		// MinRequests is the minimum number of requests in the window before the circuit can open.
+		MinRequests int `json:"minRequests"`
+		// OpenTimeout is the time the circuit stays open before trial requests are let through.
+		OpenTimeout time.Duration `json:"openTimeout"`
+		// HalfOpenRequests is the number of successful trial requests closing the circuit.
+		HalfOpenRequests int `json:"halfOpenRequests"`
+		// CacheBytes is the maximum approximate size in bytes of the successful responses kept to be served
+		// while the circuit is open.
+		CacheBytes int `json:"cacheBytes"`
+	}
+
+	// CircuitBreakerStatus reports the state of a [CircuitBreaker].
+	CircuitBreakerStatus struct {
+		// State is the state of the circuit: closed, open or half-open.
+		State string `json:"state"`
+		// Requests is the number of requests in the failure rate window.
+		Requests int `json:"requests"`
+		// Failures is the number of failed requests in the failure rate window.
+		Failures int `json:"failures"`
+		// OpenedAt is the time the circuit last opened, while it is not closed.
+		OpenedAt *time.Time `json:"openedAt,omitempty"`
+	}
+
+	// CircuitBreaker is a [PackageFetcher] failing fast while the registry is failing. The circuit opens
+	// when the failure rate of the last requests exceeds its threshold, the requests being then served
+	// from the cache of the successful responses, or failing with [ErrCircuitOpen]. Once the open timeout
+	// elapsed, trial requests are let through, closing the circuit when they succeed.
+	CircuitBreaker struct {
+		fetcher PackageFetcher
+		cfg     CircuitBreakerConfig
+
+		mu       sync.Mutex
+		state    string
+		outcomes []bool
+		next     int
+		openedAt time.Time
+		trials   int
+		passed   int
+
+		cache *responseCache
+	}
+
+	// responseCache holds the last successful responses of the registry, evicting the oldest ones
+	// once their approximate size exceeds the maximum size in bytes.
+	responseCache struct {
+		mu       sync.Mutex
+		maxBytes int
+		bytes    int
+		entries  map[string]cachedResponse
+		keys     []string
+	}
+
+	// cachedResponse is a response of the [responseCache], along with its approximate size in bytes.
+	cachedResponse struct {
+		resp  any
+		bytes int
+	}
+)
+
+// NewCircuitBreaker wraps the [PackageFetcher] with a [CircuitBreaker].
+func NewCircuitBreaker(fetcher PackageFetcher, cfg CircuitBreakerConfig) *CircuitBreaker {
+	return &CircuitBreaker{
+		fetcher: fetcher,
+		cfg:     cfg,
+		state:   CircuitClosed,
+		cache:   &responseCache{maxBytes: cfg.CacheBytes, entries: map[string]cachedResponse{}},
+	}
+}
+
+// FetchPackage fetches the [Package] information of an NPM package at a given version,
+// from the cache while the circuit is open.
+func (cb *CircuitBreaker) FetchPackage(ctx context.Context, name, version string) (*Package, error) {
+	return fetchThrough(ctx, cb, name+"@"+version, func() (*Package, error) {
+		return cb.fetcher.FetchPackage(ctx, name, version)
+	}, clonePackage, packageSize)
+}
+
+// FetchPackageMeta fetches the [PackageMeta] metadata of an NPM package, from the cache while the circuit is open.
+func (cb *CircuitBreaker) FetchPackageMeta(ctx context.Context, name string) (*PackageMeta, error) {
+	return fetchThrough(ctx, cb, name, func() (*PackageMeta, error) {
+		return cb.fetcher.FetchPackageMeta(ctx, name)
+	}, clonePackageMeta, packageMetaSize)
+}
+
+// Status returns the state of the circuit.
+func (cb *CircuitBreaker) Status() CircuitBreakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := CircuitBreakerStatus{State: cb.state, Requests: len(cb.outcomes)}
+	for _, failed := range cb.outcomes {
+		if failed {
+			status.Failures++
+		}
+	}
+	if cb.state != CircuitClosed {
+		openedAt := cb.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+// fetchThrough sends the request through the circuit breaker, caching its successful response under the key,
+// or serves the cached response while the circuit is open. The responses, which can be modified by the callers,
+// are cloned in and out of the cache, where they count for their approximate size in bytes.
+func fetchThrough[T any](ctx context.Context, cb *CircuitBreaker, key string, fetch func() (*T, error),
+	clone func(*T) *T, size func(*T) int,
+) (*T, error) {
+	trial, ok := cb.allow(time.Now())
+	if !ok {
+		if cached, ok := cb.cache.get(key).(*T); ok {
+			return clone(cached), nil
+		}
+		return nil, fmt.Errorf("%w: fetch %s", ErrCircuitOpen, key)
+	}
+
+	resp, err := fetch()
+	cb.record(trial, err, ctx.Err() != nil, time.Now())
+	if err == nil {
+		cb.cache.add(key, clone(resp), size(resp))
+	}
+	return resp, err
+}
+
+func clonePackage(pkg *Package) *Package {
+	clone := *pkg
+	clone.Dependencies = maps.Clone(pkg.Dependencies)
+	return &clone
+}
+
+func clonePackageMeta(meta *PackageMeta) *PackageMeta {
+	clone := *meta
+	clone.DistTags = maps.Clone(meta.DistTags)
+	clone.Time = maps.Clone(meta.Time)
+	if meta.Versions != nil {
+		clone.Versions = make(map[string]Package, len(meta.Versions))
+		for version, pkg := range meta.Versions {
+			clone.Versions[version] = *clonePackage(&pkg)
+		}
+	}
+	return &clone
+}
+
+// cacheEntryBytes is the approximate overhead in bytes of a struct or of a map entry held by the cache.
+const cacheEntryBytes = 64
+
+// packageSize returns the approximate size in bytes of the package in memory.
+func packageSize(pkg *Package) int {
+	size := cacheEntryBytes + len(pkg.Name) + len(pkg.Version) + len(pkg.License) + len(pkg.Deprecated)
+	for name, constraint := range pkg.Dependencies {
+		size += cacheEntryBytes + len(name) + len(constraint)
+	}
+	if pkg.Dist != nil {
+		size += cacheEntryBytes + len(pkg.Dist.Tarball) + len(pkg.Dist.Integrity) + len(pkg.Dist.Shasum)
+	}
+	return size
+}
+
+// packageMetaSize returns the approximate size in bytes of the package metadata in memory.
+func packageMetaSize(meta *PackageMeta) int {
+	size := cacheEntryBytes + len(meta.Name)
+	for tag, version := range meta.DistTags {
+		size += cacheEntryBytes + len(tag) + len(version)
+	}
+	for version, pkg := range meta.Versions {
+		size += len(version) + packageSize(&pkg)
+	}
+	for version := range meta.Time {
+		size += cacheEntryBytes + len(version)
+	}
+	return size
+}
+
+// allow reports whether a request can be sent, and whether it is a trial request of the half-open circuit.
+func (cb *CircuitBreaker) allow(now time.Time) (trial, ok bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.cfg.OpenTimeout {
+		cb.state, cb.trials, cb.passed = CircuitHalfOpen, 0, 0
+	}
+
+	switch cb.state {
+	case CircuitOpen:
+		return false, false
+	case CircuitHalfOpen:
+		if cb.trials >= max(cb.cfg.HalfOpenRequests, 1)-cb.passed {
+			return false, false
+		}
+		cb.trials++
+		return true, true
+	}
+	return false, true
+}
+
+// record records the outcome of a request. The requests interrupted by their context are neither
+// failures nor successes, and the errors other than an unavailable registry are successes.
+func (cb *CircuitBreaker) record(trial bool, err error, interrupted bool, now time.Time) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	failed := errors.Is(err, ErrRegistryUnavailable) || errors.Is(err, ErrRegistryTimeout)
+
+	if trial {
+		cb.trials--
+		switch {
+		case cb.state != CircuitHalfOpen || (interrupted && !failed):
+		case failed:
+			cb.state, cb.openedAt = CircuitOpen, now
+		default:
+			cb.passed++
+			if cb.passed >= max(cb.cfg.HalfOpenRequests, 1) {
+				cb.state, cb.outcomes, cb.next = CircuitClosed, nil, 0
+			}
+		}
+		return
+	}
+
+	if cb.state != CircuitClosed || (interrupted && !failed) || cb.cfg.FailureRate <= 0 {
+		return
+	}
+
+	windowSize := max(cb.cfg.WindowSize, 1)
+	if len(cb.outcomes) < windowSize {
+		cb.outcomes = append(cb.outcomes, failed)
+	} else {
+		cb.outcomes[cb.next] = failed
+		cb.next = (cb.next + 1) % windowSize
+	}
+
+	failures := 0
+	for _, f := range cb.outcomes {
+		if f {
+			failures++
+		}
+	}
+	if len(cb.outcomes) >= cb.cfg.MinRequests && float64(failures) >= cb.cfg.FailureRate*float64(len(cb.outcomes)) {
+		cb.state, cb.openedAt = CircuitOpen, now
+	}
+}
+
+func (c *responseCache) get(key string) any {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.entries[key].resp
+}
+
+// add caches the response of the approximate size in bytes, unless it exceeds the maximum size of the cache.
+func (c *responseCache) add(key string, resp any, bytes int) {
+	if bytes > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.entries[key]; ok {
+		c.bytes -= cached.bytes
+		c.keys = slices.DeleteFunc(c.keys, func(k string) bool { return k == key })
+	}
+	for c.bytes+bytes > c.maxBytes {
+		c.bytes -= c.entries[c.keys[0]].bytes
+		delete(c.entries, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+	c.keys = append(c.keys, key)
+	c.entries[key] = cachedResponse{resp: resp, bytes: bytes}
+	c.bytes += bytes
+}
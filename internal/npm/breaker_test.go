@@ -0,0 +1,246 @@
+package npm_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	mocksnpm "github.com/snyk/npmjs-deps-fetcher/internal/npm/mocks"
+)
+
+var breakerConfig = npm.CircuitBreakerConfig{
+	FailureRate:      0.5,
+	WindowSize:       4,
+	MinRequests:      4,
+	OpenTimeout:      50 * time.Millisecond,
+	HalfOpenRequests: 1,
+	CacheBytes:       1 << 20,
+}
+
+// openCircuit makes the circuit breaker open, after a successful fetch of the foo package metadata
+// and 3 failed fetches of the bar package metadata.
+func openCircuit(tb testing.TB, fetcher *mocksnpm.MockPackageFetcher, breaker *npm.CircuitBreaker) {
+	tb.Helper()
+	ctx := context.Background()
+
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{Name: "foo"}, nil)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").
+		Return(nil, fmt.Errorf("%w: connection refused", npm.ErrRegistryUnavailable)).Times(3)
+
+	_, err := breaker.FetchPackageMeta(ctx, "foo")
+	require.NoError(tb, err)
+	for range 3 {
+		_, err := breaker.FetchPackageMeta(ctx, "bar")
+		require.ErrorIs(tb, err, npm.ErrRegistryUnavailable)
+	}
+}
+
+func TestCircuitBreaker_Open(t *testing.T) {
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	breaker := npm.NewCircuitBreaker(fetcher, breakerConfig)
+	ctx := context.Background()
+
+	openCircuit(t, fetcher, breaker)
+
+	status := breaker.Status()
+	assert.Equal(t, npm.CircuitOpen, status.State)
+	assert.Equal(t, 4, status.Requests)
+	assert.Equal(t, 3, status.Failures)
+	assert.NotNil(t, status.OpenedAt)
+
+	meta, err := breaker.FetchPackageMeta(ctx, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", meta.Name)
+
+	_, err = breaker.FetchPackageMeta(ctx, "bar")
+	require.ErrorIs(t, err, npm.ErrCircuitOpen)
+	assert.EqualError(t, err, "registry circuit open: fetch bar")
+
+	_, err = breaker.FetchPackage(ctx, "foo", "1.0.0")
+	require.ErrorIs(t, err, npm.ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	testCases := []struct {
+		name          string
+		trialErr      error
+		expectedState string
+	}{
+		{
+			name:          "successful trial request",
+			expectedState: npm.CircuitClosed,
+		},
+		{
+			name:          "package not found trial request",
+			trialErr:      npm.ErrPackageNotFound,
+			expectedState: npm.CircuitClosed,
+		},
+		{
+			name:          "failed trial request",
+			trialErr:      fmt.Errorf("%w: i/o timeout", npm.ErrRegistryTimeout),
+			expectedState: npm.CircuitOpen,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+			breaker := npm.NewCircuitBreaker(fetcher, breakerConfig)
+			ctx := context.Background()
+
+			openCircuit(t, fetcher, breaker)
+			time.Sleep(breakerConfig.OpenTimeout)
+
+			trialStarted, trialDone := make(chan struct{}), make(chan struct{})
+			fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").DoAndReturn(
+				func(context.Context, string) (*npm.PackageMeta, error) {
+					close(trialStarted)
+					<-trialDone
+					if tc.trialErr != nil {
+						return nil, tc.trialErr
+					}
+					return &npm.PackageMeta{Name: "bar"}, nil
+				})
+
+			trialErr := make(chan error)
+			go func() {
+				_, err := breaker.FetchPackageMeta(ctx, "bar")
+				trialErr <- err
+			}()
+			<-trialStarted
+
+			assert.Equal(t, npm.CircuitHalfOpen, breaker.Status().State)
+			_, err := breaker.FetchPackageMeta(ctx, "baz")
+			require.ErrorIs(t, err, npm.ErrCircuitOpen, "only the trial request is let through")
+
+			close(trialDone)
+			if tc.trialErr != nil {
+				require.ErrorIs(t, <-trialErr, tc.trialErr)
+			} else {
+				require.NoError(t, <-trialErr)
+			}
+
+			status := breaker.Status()
+			assert.Equal(t, tc.expectedState, status.State)
+			if tc.expectedState == npm.CircuitClosed {
+				assert.Equal(t, 0, status.Requests)
+				assert.Nil(t, status.OpenedAt)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_NotFoundIsNotFailure(t *testing.T) {
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "missing").Return(nil, npm.ErrPackageNotFound).Times(5)
+	breaker := npm.NewCircuitBreaker(fetcher, breakerConfig)
+
+	for range 5 {
+		_, err := breaker.FetchPackageMeta(context.Background(), "missing")
+		require.ErrorIs(t, err, npm.ErrPackageNotFound)
+	}
+
+	assert.Equal(t, npm.CircuitBreakerStatus{State: npm.CircuitClosed, Requests: 4}, breaker.Status())
+}
+
+func TestCircuitBreaker_CachedPackageIsNotShared(t *testing.T) {
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	breaker := npm.NewCircuitBreaker(fetcher, breakerConfig)
+	ctx := context.Background()
+
+	fetcher.EXPECT().FetchPackage(gomock.Any(), "baz", "1.0.0").Return(&npm.Package{
+		Name: "baz", Version: "1.0.0", Dependencies: map[string]string{"qux": "^1.0.0"},
+	}, nil)
+	pkg, err := breaker.FetchPackage(ctx, "baz", "1.0.0")
+	require.NoError(t, err)
+	pkg.Dependencies["qux"] = "1.2.3"
+
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(nil, npm.ErrRegistryUnavailable).Times(3)
+	for range 3 {
+		_, err := breaker.FetchPackageMeta(ctx, "bar")
+		require.ErrorIs(t, err, npm.ErrRegistryUnavailable)
+	}
+	require.Equal(t, npm.CircuitOpen, breaker.Status().State)
+
+	cached, err := breaker.FetchPackage(ctx, "baz", "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"qux": "^1.0.0"}, cached.Dependencies)
+}
+
+func TestCircuitBreaker_CachedPackageMetaIsNotShared(t *testing.T) {
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	breaker := npm.NewCircuitBreaker(fetcher, breakerConfig)
+	ctx := context.Background()
+
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "baz").Return(&npm.PackageMeta{
+		Name:     "baz",
+		DistTags: map[string]string{"latest": "1.0.0"},
+		Versions: map[string]npm.Package{"1.0.0": {Name: "baz", Version: "1.0.0", Dependencies: map[string]string{"qux": "^1.0.0"}}},
+	}, nil)
+	meta, err := breaker.FetchPackageMeta(ctx, "baz")
+	require.NoError(t, err)
+	meta.DistTags["latest"] = "2.0.0"
+	meta.Versions["1.0.0"].Dependencies["qux"] = "1.2.3"
+	delete(meta.Versions, "1.0.0")
+
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(nil, npm.ErrRegistryUnavailable).Times(3)
+	for range 3 {
+		_, err := breaker.FetchPackageMeta(ctx, "bar")
+		require.ErrorIs(t, err, npm.ErrRegistryUnavailable)
+	}
+	require.Equal(t, npm.CircuitOpen, breaker.Status().State)
+
+	cached, err := breaker.FetchPackageMeta(ctx, "baz")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"latest": "1.0.0"}, cached.DistTags)
+	assert.Equal(t, map[string]npm.Package{
+		"1.0.0": {Name: "baz", Version: "1.0.0", Dependencies: map[string]string{"qux": "^1.0.0"}},
+	}, cached.Versions)
+}
+
+func TestCircuitBreaker_CacheEvictsBySize(t *testing.T) {
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	cfg := breakerConfig
+	cfg.CacheBytes = 1 << 10
+	breaker := npm.NewCircuitBreaker(fetcher, cfg)
+	ctx := context.Background()
+
+	// Every cached metadata holds a deprecation message of about half the cache size, so the cache holds
+	// the last one only, and the too large one is not cached.
+	metaOfSize := func(name string, size int) *npm.PackageMeta {
+		return &npm.PackageMeta{
+			Name:     name,
+			Versions: map[string]npm.Package{"1.0.0": {Deprecated: npm.Deprecation(strings.Repeat("x", size))}},
+		}
+	}
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(metaOfSize("foo", 512), nil)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "baz").Return(metaOfSize("baz", 512), nil)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "qux").Return(metaOfSize("qux", 2048), nil)
+	for _, name := range []string{"foo", "baz", "qux"} {
+		_, err := breaker.FetchPackageMeta(ctx, name)
+		require.NoError(t, err)
+	}
+
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(nil, npm.ErrRegistryUnavailable).Times(2)
+	for range 2 {
+		_, err := breaker.FetchPackageMeta(ctx, "bar")
+		require.ErrorIs(t, err, npm.ErrRegistryUnavailable)
+	}
+	require.Equal(t, npm.CircuitOpen, breaker.Status().State)
+
+	meta, err := breaker.FetchPackageMeta(ctx, "baz")
+	require.NoError(t, err)
+	assert.Equal(t, "baz", meta.Name)
+
+	_, err = breaker.FetchPackageMeta(ctx, "foo")
+	require.ErrorIs(t, err, npm.ErrCircuitOpen)
+	_, err = breaker.FetchPackageMeta(ctx, "qux")
+	require.ErrorIs(t, err, npm.ErrCircuitOpen)
+}
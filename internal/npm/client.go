@@ -3,12 +3,24 @@ package npm
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
+const (
+	// maxRegistryErrorBody is the maximum number of bytes read from an error response of the registry.
+	maxRegistryErrorBody = 64 << 10
+	// maxRegistryErrorExcerpt is the maximum number of bytes of the body excerpt of a [RegistryError].
+	maxRegistryErrorExcerpt = 512
+)
+
 var _ PackageFetcher = (*Client)(nil)
 
 type (
@@ -16,14 +28,28 @@ type (
 	Client struct {
 		client      *http.Client
 		registryURL string
+		token       string
+		retry       RetryConfig
+		limiter     *rateLimiter
+		dependents  *DependentsIndex
 	}
 
 	// ClientConfig provides the configuration of the NPM HTTP client.
 	ClientConfig struct {
 		// RegistryURL is the HTTP URL of the NPM registry.
 		RegistryURL string `json:"registryUrl"`
+		// Token is the auth token of the NPM registry, sent as a bearer token with every request to it.
+		// The token of a scope is not inherited, it is only sent to the registry of the scope.
+		Token string `json:"token"`
 		// Timeout configures the timeout of the HTTP client.
 		Timeout time.Duration `json:"timeout"`
+		// Retry configures the retries of the requests failing with a transient error.
+		Retry RetryConfig `json:"retry"`
+		// RateLimit configures the rate limiting of the requests to every registry host.
+		RateLimit RateLimitConfig `json:"rateLimit"`
+		// Scopes configures the registries of the scoped packages by scope, like @acme, their unset
+		// settings defaulting to the ones of this configuration. They are only used by [NewRouter].
+		Scopes map[string]ClientConfig `json:"scopes"`
 	}
 
 	// ClientOption represent optional configuration for the NPM client.
@@ -38,6 +64,22 @@ func ClientOptionHTTPTransport(rt http.RoundTripper) ClientOption {
 	}
 }
 
+// ClientOptionDependentsIndex is a client option to index the dependencies of every fetched
+// package into the provided [DependentsIndex].
+func ClientOptionDependentsIndex(idx *DependentsIndex) ClientOption {
+	return func(c *Client) {
+		c.dependents = idx
+	}
+}
+
+// clientOptionRateLimiter is a client option sending the requests through the provided rate limiter,
+// shared with other clients.
+func clientOptionRateLimiter(limiter *rateLimiter) ClientOption {
+	return func(c *Client) {
+		c.limiter = limiter
+	}
+}
+
 // NewClient creates an HTTP client to communicate with the NPM registry provided in the configuration.
 func NewClient(cfg ClientConfig, opts ...ClientOption) (c *Client, err error) {
 	if _, err := url.Parse(cfg.RegistryURL); err != nil {
@@ -50,6 +92,9 @@ func NewClient(cfg ClientConfig, opts ...ClientOption) (c *Client, err error) {
 			Transport: http.DefaultTransport,
 		},
 		registryURL: cfg.RegistryURL,
+		token:       cfg.Token,
+		retry:       cfg.Retry,
+		limiter:     newRateLimiter(cfg.RateLimit),
 	}
 
 	for _, opt := range opts {
@@ -68,10 +113,16 @@ func (c *Client) FetchPackage(ctx context.Context, name, version string) (*Packa
 	}
 
 	var pkg Package
-	if err := c.fetch(req, &pkg); err != nil {
+	if err := c.fetch(req, &pkg); errors.Is(err, ErrPackageNotFound) {
+		return nil, &VersionNotFoundError{Name: name, Version: version}
+	} else if err != nil {
 		return nil, err
 	}
 
+	if c.dependents != nil {
+		c.dependents.AddPackage(&pkg)
+	}
+
 	return &pkg, nil
 }
 
@@ -84,15 +135,60 @@ func (c *Client) FetchPackageMeta(ctx context.Context, name string) (*PackageMet
 	}
 
 	var pkgMeta PackageMeta
-	if err := c.fetch(req, &pkgMeta); err != nil {
+	if err := c.fetch(req, &pkgMeta); errors.Is(err, ErrPackageNotFound) {
+		return nil, &PackageNotFoundError{Name: name}
+	} else if err != nil {
 		return nil, err
 	}
 
+	if c.dependents != nil {
+		c.dependents.AddPackageMeta(&pkgMeta)
+	}
+
 	return &pkgMeta, nil
 }
 
+// RateLimitStats returns the activity of the rate limiter of every registry host the client sent requests to.
+func (c *Client) RateLimitStats() map[string]RateLimitStats {
+	return c.limiter.stats()
+}
+
+// fetch sends the request, decoding the response into obj, and retries it according to the [RetryConfig]
+// of the client when it fails with a transient error.
 func (c *Client) fetch(req *http.Request, obj any) error {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.retry.MaxElapsed > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), c.retry.MaxElapsed)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.fetchOnce(req, obj)
+		delay, retry := c.retry.retryDelay(req, attempt, err)
+		if !retry {
+			return err
+		}
+		if sleepErr := sleep(req.Context(), delay); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) fetchOnce(req *http.Request, obj any) error {
+	if err := c.limiter.wait(req.Context(), req.URL.Host); err != nil {
+		return err
+	}
+
 	resp, err := c.client.Do(req)
+	if netErr := net.Error(nil); errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: http request roundtrip for: %w", ErrRegistryTimeout, err)
+	}
+	if err != nil && req.Context().Err() == nil {
+		return fmt.Errorf("%w: http request roundtrip for: %w", ErrRegistryUnavailable, err)
+	}
 	if err != nil {
 		return fmt.Errorf("http request roundtrip for: %w", err)
 	}
@@ -103,11 +199,7 @@ func (c *Client) fetch(req *http.Request, obj any) error {
 	case http.StatusNotFound:
 		return ErrPackageNotFound
 	default:
-		var body string
-		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-			return fmt.Errorf("error response decoding of %q: %w", req.URL.String(), err)
-		}
-		return fmt.Errorf("http response for %q: %s", req.URL.String(), body)
+		return newRegistryError(req, resp, time.Now())
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(obj); err != nil {
@@ -116,3 +208,60 @@ func (c *Client) fetch(req *http.Request, obj any) error {
 
 	return nil
 }
+
+// newRegistryError reads the error response of the registry, parsing the error message of the known
+// registry error shapes, like a JSON string or an object with an error, a reason or a message.
+func newRegistryError(req *http.Request, resp *http.Response, now time.Time) *RegistryError {
+	regErr := &RegistryError{
+		StatusCode: resp.StatusCode,
+		URL:        req.URL.String(),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now),
+	}
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxRegistryErrorBody))
+	if err != nil {
+		return regErr
+	}
+
+	var (
+		message string
+		shape   struct {
+			Error   string `json:"error"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		}
+	)
+	switch {
+	case json.Unmarshal(b, &message) == nil:
+		regErr.Message = message
+	case json.Unmarshal(b, &shape) == nil:
+		regErr.Message = shape.Message
+		if shape.Error != "" {
+			regErr.Message = strings.TrimSpace(shape.Error + ": " + shape.Reason)
+			regErr.Message = strings.TrimSuffix(regErr.Message, ":")
+		}
+	}
+
+	body := strings.ToValidUTF8(strings.Join(strings.Fields(string(b)), " "), "")
+	if len(body) > maxRegistryErrorExcerpt {
+		body = strings.ToValidUTF8(body[:maxRegistryErrorExcerpt], "") + "..."
+	}
+	regErr.Body = body
+
+	return regErr
+}
+
+// parseRetryAfter parses the value of a Retry-After header, either a number of seconds or an HTTP date,
+// into the delay to wait from now. Zero is returned for a missing or invalid value, or a past date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
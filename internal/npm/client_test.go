@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -63,37 +64,38 @@ func (rt fakeTransport) RoundTrip(*http.Request) (*http.Response, error) {
 
 func TestClient_FetchPackage(t *testing.T) {
 	testCases := []struct {
-		name        string
-		transport   http.RoundTripper
-		expectedErr string
-		expectedPkg *npm.Package
+		name          string
+		transport     http.RoundTripper
+		expectedErr   string
+		expectedErrIs error
+		expectedPkg   *npm.Package
 	}{
 		{
 			name: "round trip error",
 			transport: fakeTransport{
 				err: errors.New("round trip error"),
 			},
-			expectedErr: "http request roundtrip for: Get \"http://localhost:8080/fake-name/fake-version\": round trip error",
+			expectedErr: "registry unavailable: http request roundtrip for: Get \"http://localhost:8080/fake-name/fake-version\": round trip error",
 		},
 		{
-			name: "http error response decoding error",
+			name: "http error response with error object",
 			transport: fakeTransport{
 				resp: &http.Response{
 					StatusCode: http.StatusBadRequest,
 					Body:       io.NopCloser(strings.NewReader(`{"error":"json error"}`)),
 				},
 			},
-			expectedErr: "error response decoding of \"http://localhost:8080/fake-name/fake-version\": json: cannot unmarshal object into Go value of type string",
+			expectedErr: "registry response 400 for \"http://localhost:8080/fake-name/fake-version\": json error",
 		},
 		{
-			name: "http error response decoding success",
+			name: "http error response with error string",
 			transport: fakeTransport{
 				resp: &http.Response{
 					StatusCode: http.StatusInternalServerError,
 					Body:       io.NopCloser(strings.NewReader(`"internal server error"`)),
 				},
 			},
-			expectedErr: "http response for \"http://localhost:8080/fake-name/fake-version\": internal server error",
+			expectedErr: "registry response 500 for \"http://localhost:8080/fake-name/fake-version\": internal server error",
 		},
 		{
 			name: "http response decoding error",
@@ -113,7 +115,8 @@ func TestClient_FetchPackage(t *testing.T) {
 					Body:       io.NopCloser(strings.NewReader(`"not found"`)),
 				},
 			},
-			expectedErr: npm.ErrPackageNotFound.Error(),
+			expectedErr:   "version fake-version of fake-name not found",
+			expectedErrIs: npm.ErrPackageNotFound,
 		},
 		{
 			name: "http response decoding",
@@ -150,6 +153,9 @@ func TestClient_FetchPackage(t *testing.T) {
 			} else {
 				assert.EqualError(t, err, tc.expectedErr)
 			}
+			if tc.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tc.expectedErrIs)
+			}
 		})
 	}
 }
@@ -159,6 +165,7 @@ func TestClient_FetchPackageMeta(t *testing.T) {
 		name            string
 		transport       http.RoundTripper
 		expectedErr     string
+		expectedErrIs   error
 		expectedPkgMeta *npm.PackageMeta
 	}{
 		{
@@ -166,27 +173,48 @@ func TestClient_FetchPackageMeta(t *testing.T) {
 			transport: fakeTransport{
 				err: errors.New("round trip error"),
 			},
-			expectedErr: "http request roundtrip for: Get \"http://localhost:8080/fake-name\": round trip error",
+			expectedErr:   "registry unavailable: http request roundtrip for: Get \"http://localhost:8080/fake-name\": round trip error",
+			expectedErrIs: npm.ErrRegistryUnavailable,
 		},
 		{
-			name: "http error response decoding error",
+			name: "round trip timeout",
+			transport: fakeTransport{
+				err: os.ErrDeadlineExceeded,
+			},
+			expectedErr:   "registry timeout: http request roundtrip for: Get \"http://localhost:8080/fake-name\": i/o timeout",
+			expectedErrIs: npm.ErrRegistryTimeout,
+		},
+		{
+			name: "http error response with error object",
 			transport: fakeTransport{
 				resp: &http.Response{
 					StatusCode: http.StatusBadRequest,
 					Body:       io.NopCloser(strings.NewReader(`{"error":"json error"}`)),
 				},
 			},
-			expectedErr: "error response decoding of \"http://localhost:8080/fake-name\": json: cannot unmarshal object into Go value of type string",
+			expectedErr: "registry response 400 for \"http://localhost:8080/fake-name\": json error",
 		},
 		{
-			name: "http error response decoding success",
+			name: "http error response with error string",
 			transport: fakeTransport{
 				resp: &http.Response{
 					StatusCode: http.StatusInternalServerError,
 					Body:       io.NopCloser(strings.NewReader(`"internal server error"`)),
 				},
 			},
-			expectedErr: "http response for \"http://localhost:8080/fake-name\": internal server error",
+			expectedErr:   "registry response 500 for \"http://localhost:8080/fake-name\": internal server error",
+			expectedErrIs: npm.ErrRegistryUnavailable,
+		},
+		{
+			name: "http error response gateway timeout",
+			transport: fakeTransport{
+				resp: &http.Response{
+					StatusCode: http.StatusGatewayTimeout,
+					Body:       io.NopCloser(strings.NewReader(`"gateway timeout"`)),
+				},
+			},
+			expectedErr:   "registry response 504 for \"http://localhost:8080/fake-name\": gateway timeout",
+			expectedErrIs: npm.ErrRegistryTimeout,
 		},
 		{
 			name: "http response decoding error",
@@ -206,7 +234,8 @@ func TestClient_FetchPackageMeta(t *testing.T) {
 					Body:       io.NopCloser(strings.NewReader(`"not found"`)),
 				},
 			},
-			expectedErr: npm.ErrPackageNotFound.Error(),
+			expectedErr:   "package fake-name not found",
+			expectedErrIs: npm.ErrPackageNotFound,
 		},
 		{
 			name: "http response decoding",
@@ -258,6 +287,99 @@ func TestClient_FetchPackageMeta(t *testing.T) {
 			} else {
 				assert.EqualError(t, err, tc.expectedErr)
 			}
+			if tc.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tc.expectedErrIs)
+			}
+		})
+	}
+}
+
+func TestClient_RegistryError(t *testing.T) {
+	testCases := []struct {
+		name               string
+		statusCode         int
+		header             http.Header
+		body               string
+		expectedMessage    string
+		expectedBody       string
+		expectedRetryAfter time.Duration
+		expectedErr        string
+		expectedErrIs      error
+	}{
+		{
+			name:          "html error page",
+			statusCode:    http.StatusBadGateway,
+			body:          "<html>\n  <body><h1>502 Bad Gateway</h1></body>\n</html>\n",
+			expectedBody:  "<html> <body><h1>502 Bad Gateway</h1></body> </html>",
+			expectedErr:   "registry response 502 for \"http://localhost:8080/fake-name\": <html> <body><h1>502 Bad Gateway</h1></body> </html>",
+			expectedErrIs: npm.ErrRegistryUnavailable,
+		},
+		{
+			name:            "error and reason object",
+			statusCode:      http.StatusUnauthorized,
+			body:            `{"error":"unauthorized","reason":"Name or password is incorrect."}`,
+			expectedMessage: "unauthorized: Name or password is incorrect.",
+			expectedBody:    `{"error":"unauthorized","reason":"Name or password is incorrect."}`,
+			expectedErr:     "registry response 401 for \"http://localhost:8080/fake-name\": unauthorized: Name or password is incorrect.",
+		},
+		{
+			name:               "code and message object",
+			statusCode:         http.StatusServiceUnavailable,
+			header:             http.Header{"Retry-After": {"120"}},
+			body:               `{"code":"ServiceUnavailable","message":"registry under maintenance"}`,
+			expectedMessage:    "registry under maintenance",
+			expectedBody:       `{"code":"ServiceUnavailable","message":"registry under maintenance"}`,
+			expectedErr:        "registry response 503 for \"http://localhost:8080/fake-name\": registry under maintenance",
+			expectedErrIs:      npm.ErrRegistryUnavailable,
+			expectedRetryAfter: 2 * time.Minute,
+		},
+		{
+			name:          "empty body",
+			statusCode:    http.StatusGatewayTimeout,
+			header:        http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}},
+			expectedErr:   "registry response 504 for \"http://localhost:8080/fake-name\": Gateway Timeout",
+			expectedErrIs: npm.ErrRegistryTimeout,
+		},
+		{
+			name:         "long body excerpt",
+			statusCode:   http.StatusTooManyRequests,
+			header:       http.Header{"Retry-After": {"soon"}},
+			body:         strings.Repeat("too many requests ", 100),
+			expectedBody: strings.Repeat("too many requests ", 100)[:512] + "...",
+			expectedErr:  "registry response 429 for \"http://localhost:8080/fake-name\": " + strings.Repeat("too many requests ", 100)[:512] + "...",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := npm.NewClient(npm.ClientConfig{
+				RegistryURL: "http://localhost:8080",
+				Timeout:     15 * time.Second,
+			}, npm.ClientOptionHTTPTransport(fakeTransport{
+				resp: &http.Response{
+					StatusCode: tc.statusCode,
+					Header:     tc.header,
+					Body:       io.NopCloser(strings.NewReader(tc.body)),
+				},
+			}))
+			require.NoError(t, err)
+
+			_, err = client.FetchPackageMeta(context.Background(), "fake-name")
+
+			var regErr *npm.RegistryError
+			require.ErrorAs(t, err, &regErr)
+			assert.Equal(t, tc.statusCode, regErr.StatusCode)
+			assert.Equal(t, "http://localhost:8080/fake-name", regErr.URL)
+			assert.Equal(t, tc.expectedRetryAfter, regErr.RetryAfter)
+			assert.Equal(t, tc.expectedMessage, regErr.Message)
+			assert.Equal(t, tc.expectedBody, regErr.Body)
+			assert.EqualError(t, err, tc.expectedErr)
+			if tc.expectedErrIs != nil {
+				assert.ErrorIs(t, err, tc.expectedErrIs)
+			} else {
+				assert.NotErrorIs(t, err, npm.ErrRegistryUnavailable)
+				assert.NotErrorIs(t, err, npm.ErrRegistryTimeout)
+			}
 		})
 	}
 }
@@ -0,0 +1,190 @@
+package npm
+
+import (
+	"cmp"
+	"container/list"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+type (
+	// Dependent is a package version declaring a dependency on another package.
+	Dependent struct {
+		// Name is the name of the dependent package.
+		Name string `json:"name"`
+		// Version is the version of the dependent package.
+		Version string `json:"version"`
+		// Constraint is the version range of the dependency declared by the dependent package.
+		Constraint string `json:"constraint"`
+	}
+
+	// DependentsIndex is a reverse-dependency index of the package versions fetched from the registry,
+	// mapping every dependency name to the package versions depending on it.
+	// It is safe for concurrent use, and evicts the least recently fetched package versions
+	// beyond its maximum number of package versions.
+	DependentsIndex struct {
+		maxVersions int
+
+		mu sync.RWMutex
+		// dependents maps the dependency names to the IDs of their dependents.
+		dependents map[string]map[string]indexedDependent
+		// versions maps the IDs of the indexed package versions to their element in the lru list.
+		versions map[string]*list.Element
+		// lru holds the indexed package versions, from the most to the least recently fetched.
+		lru *list.List
+		// revisions maps the names of the packages whose metadata was indexed to the modification
+		// time of the metadata, so that it is only indexed again when it changed.
+		revisions map[string]time.Time
+	}
+
+	// indexedDependent is a [Dependent] with its parsed constraint, nil when it is not a semver range,
+	// like a git URL or a dist-tag.
+	indexedDependent struct {
+		Dependent
+		rng semverutil.Range
+	}
+
+	// indexedVersion is a package version of the lru list of a [DependentsIndex], with the names of its dependencies.
+	indexedVersion struct {
+		id, name string
+		deps     []string
+	}
+)
+
+// NewDependentsIndex creates an empty [DependentsIndex] holding up to maxVersions package versions,
+// or an unbounded one when maxVersions is zero or less.
+func NewDependentsIndex(maxVersions int) *DependentsIndex {
+	return &DependentsIndex{
+		maxVersions: maxVersions,
+		dependents:  map[string]map[string]indexedDependent{},
+		versions:    map[string]*list.Element{},
+		lru:         list.New(),
+		revisions:   map[string]time.Time{},
+	}
+}
+
+// AddPackageMeta indexes the dependencies of every version of the package. The metadata is only indexed
+// again when its modification time changed, its versions being then the most recently fetched ones.
+func (idx *DependentsIndex) AddPackageMeta(meta *PackageMeta) {
+	modified, ok := meta.Time["modified"]
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if revision, indexed := idx.revisions[meta.Name]; ok && indexed && revision.Equal(modified) {
+		for version, pkg := range meta.Versions {
+			if elem, ok := idx.versions[NodeID(cmp.Or(pkg.Name, meta.Name), cmp.Or(pkg.Version, version))]; ok {
+				idx.lru.MoveToFront(elem)
+			}
+		}
+		return
+	}
+
+	for version, pkg := range meta.Versions {
+		pkg.Name = cmp.Or(pkg.Name, meta.Name)
+		pkg.Version = cmp.Or(pkg.Version, version)
+		idx.add(&pkg)
+	}
+	if ok {
+		idx.revisions[meta.Name] = modified
+	}
+	idx.evict()
+}
+
+// AddPackage indexes the dependencies of the package version.
+func (idx *DependentsIndex) AddPackage(pkg *Package) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.add(pkg)
+	idx.evict()
+}
+
+// add indexes the dependencies of the package version, unless it is already indexed, since the published
+// package versions are immutable, making it the most recently fetched one.
+func (idx *DependentsIndex) add(pkg *Package) {
+	id := NodeID(pkg.Name, pkg.Version)
+	if elem, ok := idx.versions[id]; ok {
+		idx.lru.MoveToFront(elem)
+		return
+	}
+
+	v := &indexedVersion{id: id, name: pkg.Name, deps: make([]string, 0, len(pkg.Dependencies))}
+	for depName, constraint := range pkg.Dependencies {
+		rng, err := semverutil.ParseRange(constraint)
+		if err != nil {
+			rng = nil
+		}
+
+		if idx.dependents[depName] == nil {
+			idx.dependents[depName] = map[string]indexedDependent{}
+		}
+		idx.dependents[depName][id] = indexedDependent{
+			Dependent: Dependent{Name: pkg.Name, Version: pkg.Version, Constraint: constraint},
+			rng:       rng,
+		}
+		v.deps = append(v.deps, depName)
+	}
+	idx.versions[id] = idx.lru.PushFront(v)
+}
+
+// evict removes the least recently fetched package versions beyond the maximum number of package versions.
+// The metadata of their package is then indexed again when it is fetched.
+func (idx *DependentsIndex) evict() {
+	for idx.maxVersions > 0 && idx.lru.Len() > idx.maxVersions {
+		v, _ := idx.lru.Remove(idx.lru.Back()).(*indexedVersion)
+		delete(idx.versions, v.id)
+		delete(idx.revisions, v.name)
+		for _, depName := range v.deps {
+			delete(idx.dependents[depName], v.id)
+			if len(idx.dependents[depName]) == 0 {
+				delete(idx.dependents, depName)
+			}
+		}
+	}
+}
+
+// Dependents returns the indexed package versions depending on the package, sorted by name and version.
+func (idx *DependentsIndex) Dependents(name string) []Dependent {
+	return idx.dependentsFunc(name, func(indexedDependent) bool { return true })
+}
+
+// DependentsInRange returns the indexed package versions depending on the package with a version range
+// intersecting the provided one, sorted by name and version. Dependents declaring a constraint that is
+// not a semver range are omitted.
+func (idx *DependentsIndex) DependentsInRange(name string, rng semverutil.Range) []Dependent {
+	return idx.dependentsFunc(name, func(d indexedDependent) bool { return d.rng != nil && d.rng.Intersects(rng) })
+}
+
+func (idx *DependentsIndex) dependentsFunc(name string, keep func(indexedDependent) bool) []Dependent {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	dependents := []Dependent{}
+	for _, d := range idx.dependents[name] {
+		if keep(d) {
+			dependents = append(dependents, d.Dependent)
+		}
+	}
+
+	slices.SortFunc(dependents, func(a, b Dependent) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), compareVersions(a.Version, b.Version))
+	})
+
+	return dependents
+}
+
+// compareVersions compares two versions by semver precedence, or lexically when they are not valid semver.
+func compareVersions(a, b string) int {
+	va, errA := semver.StrictNewVersion(a)
+	vb, errB := semver.StrictNewVersion(b)
+	if errA != nil || errB != nil {
+		return cmp.Compare(a, b)
+	}
+	return va.Compare(vb)
+}
@@ -0,0 +1,108 @@
+package npm_test
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+func TestDependentsIndex(t *testing.T) {
+	idx := npm.NewDependentsIndex(0)
+
+	c, err := npm.NewClient(
+		npm.ClientConfig{RegistryURL: "http://localhost:8080", Timeout: 15 * time.Second},
+		npm.ClientOptionHTTPTransport(fakeTransport{
+			resp: &http.Response{
+				StatusCode: http.StatusOK,
+				Body: io.NopCloser(strings.NewReader(`{"name":"express","versions":{` +
+					`"4.17.0":{"dependencies":{"lodash":"~4.16.0"}},` +
+					`"4.18.0":{"dependencies":{"lodash":"^4.17.0","debug":"2.6.9"}}}}`)),
+			},
+		}),
+		npm.ClientOptionDependentsIndex(idx),
+	)
+	require.NoError(t, err)
+
+	_, err = c.FetchPackageMeta(t.Context(), "express")
+	require.NoError(t, err)
+
+	idx.AddPackage(&npm.Package{Name: "async", Version: "10.0.0", Dependencies: map[string]string{"lodash": "github:lodash/lodash"}})
+	idx.AddPackage(&npm.Package{Name: "async", Version: "9.0.0", Dependencies: map[string]string{"lodash": "4.x"}})
+
+	assert.Equal(t, []npm.Dependent{
+		{Name: "async", Version: "9.0.0", Constraint: "4.x"},
+		{Name: "async", Version: "10.0.0", Constraint: "github:lodash/lodash"},
+		{Name: "express", Version: "4.17.0", Constraint: "~4.16.0"},
+		{Name: "express", Version: "4.18.0", Constraint: "^4.17.0"},
+	}, idx.Dependents("lodash"))
+
+	rng, err := semverutil.ParseRange("4.17.20")
+	require.NoError(t, err)
+
+	assert.Equal(t, []npm.Dependent{
+		{Name: "async", Version: "9.0.0", Constraint: "4.x"},
+		{Name: "express", Version: "4.18.0", Constraint: "^4.17.0"},
+	}, idx.DependentsInRange("lodash", rng))
+
+	assert.Equal(t, []npm.Dependent{}, idx.Dependents("react"))
+}
+
+func TestDependentsIndex_Eviction(t *testing.T) {
+	idx := npm.NewDependentsIndex(2)
+
+	idx.AddPackage(&npm.Package{Name: "async", Version: "1.0.0", Dependencies: map[string]string{"lodash": "^4.0.0"}})
+	idx.AddPackage(&npm.Package{Name: "express", Version: "4.18.0", Dependencies: map[string]string{"lodash": "^4.17.0", "debug": "2.6.9"}})
+	idx.AddPackage(&npm.Package{Name: "async", Version: "1.0.0", Dependencies: map[string]string{"lodash": "^4.0.0"}})
+	idx.AddPackage(&npm.Package{Name: "request", Version: "2.88.0", Dependencies: map[string]string{"lodash": "4.x"}})
+
+	assert.Equal(t, []npm.Dependent{
+		{Name: "async", Version: "1.0.0", Constraint: "^4.0.0"},
+		{Name: "request", Version: "2.88.0", Constraint: "4.x"},
+	}, idx.Dependents("lodash"), "the least recently fetched package version is evicted")
+	assert.Equal(t, []npm.Dependent{}, idx.Dependents("debug"))
+}
+
+func TestDependentsIndex_PackageMetaRevision(t *testing.T) {
+	idx := npm.NewDependentsIndex(0)
+	modified := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	idx.AddPackageMeta(&npm.PackageMeta{
+		Name:     "express",
+		Versions: map[string]npm.Package{"4.17.0": {Dependencies: map[string]string{"lodash": "~4.16.0"}}},
+		Time:     npm.PublishTimes{"modified": modified},
+	})
+	idx.AddPackageMeta(&npm.PackageMeta{
+		Name: "express",
+		Versions: map[string]npm.Package{
+			"4.17.0": {Dependencies: map[string]string{"lodash": "~4.16.0"}},
+			"4.18.0": {Dependencies: map[string]string{"lodash": "^4.17.0"}},
+		},
+		Time: npm.PublishTimes{"modified": modified},
+	})
+
+	assert.Equal(t, []npm.Dependent{
+		{Name: "express", Version: "4.17.0", Constraint: "~4.16.0"},
+	}, idx.Dependents("lodash"), "unmodified metadata is not indexed again")
+
+	idx.AddPackageMeta(&npm.PackageMeta{
+		Name: "express",
+		Versions: map[string]npm.Package{
+			"4.17.0": {Dependencies: map[string]string{"lodash": "~4.16.0"}},
+			"4.18.0": {Dependencies: map[string]string{"lodash": "^4.17.0"}},
+		},
+		Time: npm.PublishTimes{"modified": modified.Add(time.Hour)},
+	})
+
+	assert.Equal(t, []npm.Dependent{
+		{Name: "express", Version: "4.17.0", Constraint: "~4.16.0"},
+		{Name: "express", Version: "4.18.0", Constraint: "^4.17.0"},
+	}, idx.Dependents("lodash"))
+}
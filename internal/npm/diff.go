@@ -0,0 +1,124 @@
+package npm
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ChangeKind is the kind of change of a package between two dependency graphs.
+type ChangeKind string
+
+const (
+	// ChangeAdded indicates a package version only part of the new graph.
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved indicates a package version only part of the old graph.
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeUpgraded indicates a package version replaced by a higher one.
+	ChangeUpgraded ChangeKind = "upgraded"
+	// ChangeDowngraded indicates a package version replaced by a lower one.
+	ChangeDowngraded ChangeKind = "downgraded"
+)
+
+// Change is a difference between two dependency graphs, for a single package.
+type Change struct {
+	// Kind is the kind of change.
+	Kind ChangeKind `json:"kind"`
+	// Name is the name of the package.
+	Name string `json:"name"`
+	// From is the version in the old graph, empty for added packages.
+	From string `json:"from,omitempty"`
+	// To is the version in the new graph, empty for removed packages.
+	To string `json:"to,omitempty"`
+	// Paths are the dependency paths that introduced the change, in the new graph,
+	// or in the old graph for removed packages.
+	Paths [][]PathStep `json:"paths"`
+	// Truncated reports whether paths were omitted because of the path limit.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// DiffGraphs compares the packages of two dependency graphs, ignoring their root nodes.
+// When a package has several versions in the graphs, the versions only part of the old graph are paired,
+// from the highest, with the ones only part of the new graph. Every change reports up to pathLimit dependency
+// paths. The changes are sorted by package name and versions.
+func DiffGraphs(from, to *Graph, pathLimit int) []Change {
+	changes := []Change{}
+
+	fromVersions, toVersions := versionsByName(from), versionsByName(to)
+	names := slices.Sorted(maps.Keys(fromVersions))
+	for name := range toVersions {
+		if _, ok := fromVersions[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		removed := versionsDifference(fromVersions[name], toVersions[name])
+		added := versionsDifference(toVersions[name], fromVersions[name])
+
+		for len(removed) > 0 && len(added) > 0 {
+			change := Change{Kind: ChangeUpgraded, Name: name, From: removed[0], To: added[0]}
+			if compareVersions(change.From, change.To) > 0 {
+				change.Kind = ChangeDowngraded
+			}
+			change.Paths, change.Truncated = to.Paths(name, exactVersionConstraint(change.To), pathLimit)
+			changes = append(changes, change)
+			removed, added = removed[1:], added[1:]
+		}
+		for _, version := range removed {
+			change := Change{Kind: ChangeRemoved, Name: name, From: version}
+			change.Paths, change.Truncated = from.Paths(name, exactVersionConstraint(version), pathLimit)
+			changes = append(changes, change)
+		}
+		for _, version := range added {
+			change := Change{Kind: ChangeAdded, Name: name, To: version}
+			change.Paths, change.Truncated = to.Paths(name, exactVersionConstraint(version), pathLimit)
+			changes = append(changes, change)
+		}
+	}
+
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		return cmp.Or(
+			cmp.Compare(a.Name, b.Name),
+			compareVersions(cmp.Or(a.From, a.To), cmp.Or(b.From, b.To)),
+		)
+	})
+
+	return changes
+}
+
+// versionsByName indexes the versions of the graph packages by name, without the root node.
+func versionsByName(g *Graph) map[string][]string {
+	versions := map[string][]string{}
+	for id, node := range g.Nodes {
+		if id != g.Root {
+			versions[node.Name] = append(versions[node.Name], node.Version)
+		}
+	}
+	return versions
+}
+
+// versionsDifference returns the versions of a that are not in b, from the highest.
+func versionsDifference(a, b []string) []string {
+	var diff []string
+	for _, version := range a {
+		if !slices.Contains(b, version) {
+			diff = append(diff, version)
+		}
+	}
+	slices.SortFunc(diff, func(x, y string) int { return compareVersions(y, x) })
+	return diff
+}
+
+// exactVersionConstraint returns the constraint only matching the version,
+// or nil to match any version when it is not a valid semver version.
+func exactVersionConstraint(version string) *semver.Constraints {
+	constraint, err := semver.NewConstraint("=" + version)
+	if err != nil {
+		return nil
+	}
+	return constraint
+}
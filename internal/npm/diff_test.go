@@ -0,0 +1,75 @@
+package npm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestDiffGraphs(t *testing.T) {
+	from := &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{
+					"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"},
+					"bar": {Constraint: "^2.0.0", Node: "bar@2.1.0"},
+					"old": {Constraint: "^1.0.0", Node: "old@1.0.0"},
+				},
+			},
+			"foo@1.2.0": {Name: "foo", Version: "1.2.0"},
+			"bar@2.1.0": {Name: "bar", Version: "2.1.0"},
+			"old@1.0.0": {Name: "old", Version: "1.0.0"},
+		},
+	}
+	to := &npm.Graph{
+		Root: "app@2.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@2.0.0": {
+				Name: "app", Version: "2.0.0",
+				Dependencies: map[string]npm.Edge{
+					"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"},
+					"bar": {Constraint: "~2.0.0", Node: "bar@2.0.5"},
+					"new": {Constraint: "^3.0.0", Node: "new@3.0.0"},
+				},
+			},
+			"foo@1.2.0": {Name: "foo", Version: "1.2.0"},
+			"bar@2.0.5": {Name: "bar", Version: "2.0.5"},
+			"new@3.0.0": {
+				Name: "new", Version: "3.0.0",
+				Dependencies: map[string]npm.Edge{"old": {Constraint: "^2.0.0", Node: "old@2.0.0"}},
+			},
+			"old@2.0.0": {Name: "old", Version: "2.0.0"},
+		},
+	}
+
+	assert.Equal(t, []npm.Change{
+		{
+			Kind: npm.ChangeDowngraded, Name: "bar", From: "2.1.0", To: "2.0.5",
+			Paths: [][]npm.PathStep{{
+				{Name: "app", Version: "2.0.0"},
+				{Name: "bar", Version: "2.0.5", Constraint: "~2.0.0"},
+			}},
+		},
+		{
+			Kind: npm.ChangeAdded, Name: "new", To: "3.0.0",
+			Paths: [][]npm.PathStep{{
+				{Name: "app", Version: "2.0.0"},
+				{Name: "new", Version: "3.0.0", Constraint: "^3.0.0"},
+			}},
+		},
+		{
+			Kind: npm.ChangeUpgraded, Name: "old", From: "1.0.0", To: "2.0.0",
+			Paths: [][]npm.PathStep{{
+				{Name: "app", Version: "2.0.0"},
+				{Name: "new", Version: "3.0.0", Constraint: "^3.0.0"},
+				{Name: "old", Version: "2.0.0", Constraint: "^2.0.0"},
+			}},
+		},
+	}, npm.DiffGraphs(from, to, 10))
+
+	assert.Equal(t, []npm.Change{}, npm.DiffGraphs(from, from, 10))
+}
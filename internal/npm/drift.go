@@ -0,0 +1,174 @@
+package npm
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// DriftKind is the kind of difference between a lockfile and a fresh resolution of its manifest.
+type DriftKind string
+
+const (
+	// DriftOutdated indicates the locked version satisfies the constraint, but a higher one is available.
+	DriftOutdated DriftKind = "outdated"
+	// DriftUnsatisfied indicates the locked version does not satisfy the constraint anymore.
+	DriftUnsatisfied DriftKind = "unsatisfied"
+	// DriftMissing indicates a dependency that is not locked.
+	DriftMissing DriftKind = "missing"
+	// DriftOrphaned indicates a locked package that is not part of the fresh resolution.
+	DriftOrphaned DriftKind = "orphaned"
+)
+
+// Drift is a difference between a lockfile and a fresh resolution of its manifest, for a single package.
+type Drift struct {
+	// Kind is the kind of difference.
+	Kind DriftKind `json:"kind"`
+	// Name is the name of the package.
+	Name string `json:"name"`
+	// Dependent is the name of the package depending on it, empty for the root project
+	// and for orphaned packages.
+	Dependent string `json:"dependent,omitempty"`
+	// Constraint is the version constraint of the fresh resolution.
+	Constraint string `json:"constraint,omitempty"`
+	// Locked is the version in the lockfile.
+	Locked string `json:"locked,omitempty"`
+	// Resolved is the version of the fresh resolution.
+	Resolved string `json:"resolved,omitempty"`
+}
+
+// DetectDrift compares a lockfile graph to a fresh resolution of the same manifest. Dependencies are matched by
+// the name of their dependent package, so the drift of a transitive dependency is reported even when its dependent
+// package version also drifted. The direct dependencies of the manifest, the root dependencies of the fresh
+// resolution, that the lockfile root does not record are matched by name among the locked packages.
+// The locked package versions that are neither part of the fresh resolution nor reported as drifted are orphaned,
+// except the ones of the dependencies skipped by the fresh resolution.
+// The drifts are sorted by package name, dependent and kind.
+func DetectDrift(locked, fresh *Graph) []Drift {
+	drifts := []Drift{}
+
+	lockedDeps, freshDeps := dependenciesByDependent(locked), dependenciesByDependent(fresh)
+	lockedDeps[""] = lockedRootDependencies(locked, lockedDeps[""], freshDeps[""])
+	for dependent, deps := range freshDeps {
+		for depName, freshVersions := range deps {
+			lockedVersions := slices.Compact(slices.Sorted(maps.Values(lockedDeps[dependent][depName])))
+			for constraintStr, freshVersion := range freshVersions {
+				drift, ok := compareLocked(constraintStr, freshVersion, lockedVersions)
+				if !ok {
+					continue
+				}
+				drift.Name, drift.Dependent = depName, dependent
+				drifts = append(drifts, drift)
+			}
+		}
+	}
+
+	accounted := map[string]bool{}
+	for _, node := range fresh.Nodes {
+		accounted[node.ID()] = true
+	}
+	for _, drift := range drifts {
+		accounted[NodeID(drift.Name, drift.Locked)] = true
+	}
+	skipped := map[string]bool{}
+	for _, dep := range fresh.Skipped {
+		skipped[dep.Name] = true
+	}
+	for _, id := range locked.SortedIDs() {
+		if node := locked.Nodes[id]; id != locked.Root && !accounted[node.ID()] && !skipped[node.Name] {
+			drifts = append(drifts, Drift{Kind: DriftOrphaned, Name: node.Name, Locked: node.Version})
+		}
+	}
+
+	slices.SortFunc(drifts, func(a, b Drift) int {
+		return cmp.Or(
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Dependent, b.Dependent),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Locked, b.Locked),
+			cmp.Compare(a.Constraint, b.Constraint),
+		)
+	})
+
+	return drifts
+}
+
+// compareLocked compares the sorted locked versions of a dependency to its fresh resolution.
+// It reports false when one of the locked versions is the freshly resolved one.
+func compareLocked(constraintStr, freshVersion string, lockedVersions []string) (Drift, bool) {
+	drift := Drift{Constraint: constraintStr, Resolved: freshVersion}
+	if len(lockedVersions) == 0 {
+		drift.Kind = DriftMissing
+		return drift, true
+	}
+	if slices.Contains(lockedVersions, freshVersion) {
+		return Drift{}, false
+	}
+
+	drift.Kind, drift.Locked = DriftUnsatisfied, lockedVersions[0]
+
+	constraint, err := semver.NewConstraint(constraintStr)
+	if err != nil {
+		return drift, true
+	}
+	for _, lockedVersion := range lockedVersions {
+		if v, err := semver.NewVersion(lockedVersion); err == nil && constraint.Check(v) {
+			drift.Kind, drift.Locked = DriftOutdated, lockedVersion
+			break
+		}
+	}
+
+	return drift, true
+}
+
+// dependenciesByDependent indexes the edges of a graph by the name of the dependent package, then by the
+// name of the dependency, then by the version constraint, to the resolved dependency version.
+// The dependent name of the root project is empty.
+func dependenciesByDependent(g *Graph) map[string]map[string]map[string]string {
+	index := map[string]map[string]map[string]string{}
+	for id, node := range g.Nodes {
+		dependent := node.Name
+		if id == g.Root {
+			dependent = ""
+		}
+		if index[dependent] == nil {
+			index[dependent] = map[string]map[string]string{}
+		}
+
+		for depName, edge := range node.Dependencies {
+			if index[dependent][depName] == nil {
+				index[dependent][depName] = map[string]string{}
+			}
+			index[dependent][depName][edge.Constraint] = g.Nodes[edge.Node].Version
+		}
+	}
+	return index
+}
+
+// lockedRootDependencies completes the locked root dependencies with the other direct dependencies of the manifest,
+// mapped to every locked version of a package with the same name. npm v1 and yarn classic lockfiles do not record
+// the dependencies of the root project, which are only linked to the packages no other package depends on,
+// so a direct dependency that is also a transitive one is missing from their root.
+func lockedRootDependencies(locked *Graph, lockedRoot, freshRoot map[string]map[string]string) map[string]map[string]string {
+	deps := maps.Clone(lockedRoot)
+	if deps == nil {
+		deps = map[string]map[string]string{}
+	}
+
+	for id, node := range locked.Nodes {
+		if _, direct := freshRoot[node.Name]; !direct || id == locked.Root {
+			continue
+		}
+		if _, recorded := lockedRoot[node.Name]; recorded {
+			continue
+		}
+		if deps[node.Name] == nil {
+			deps[node.Name] = map[string]string{}
+		}
+		deps[node.Name][node.Version] = node.Version
+	}
+
+	return deps
+}
@@ -0,0 +1,168 @@
+package npm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestDetectDrift(t *testing.T) {
+	locked := &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{
+					"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"},
+					"bar": {Constraint: "^2.0.0", Node: "bar@2.0.0"},
+					"old": {Constraint: "^1.0.0", Node: "old@1.0.0"},
+				},
+			},
+			"foo@1.0.0": {
+				Name: "foo", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{"baz": {Constraint: "3.0.0", Node: "baz@3.0.0"}},
+			},
+			"bar@2.0.0": {Name: "bar", Version: "2.0.0"},
+			"baz@3.0.0": {Name: "baz", Version: "3.0.0"},
+			"old@1.0.0": {Name: "old", Version: "1.0.0"},
+		},
+	}
+	fresh := &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{
+					"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"},
+					"bar": {Constraint: "^3.0.0", Node: "bar@3.1.0"},
+					"new": {Constraint: "~0.1.0", Node: "new@0.1.2"},
+				},
+			},
+			"foo@1.2.0": {
+				Name: "foo", Version: "1.2.0",
+				Dependencies: map[string]npm.Edge{"baz": {Constraint: "^3.0.0", Node: "baz@3.0.0"}},
+			},
+			"bar@3.1.0": {Name: "bar", Version: "3.1.0"},
+			"baz@3.0.0": {Name: "baz", Version: "3.0.0"},
+			"new@0.1.2": {Name: "new", Version: "0.1.2"},
+		},
+	}
+
+	drifts := npm.DetectDrift(locked, fresh)
+
+	assert.Equal(t, []npm.Drift{
+		{Kind: npm.DriftUnsatisfied, Name: "bar", Constraint: "^3.0.0", Locked: "2.0.0", Resolved: "3.1.0"},
+		{Kind: npm.DriftOutdated, Name: "foo", Constraint: "^1.0.0", Locked: "1.0.0", Resolved: "1.2.0"},
+		{Kind: npm.DriftMissing, Name: "new", Constraint: "~0.1.0", Resolved: "0.1.2"},
+		{Kind: npm.DriftOrphaned, Name: "old", Locked: "1.0.0"},
+	}, drifts)
+
+	assert.Empty(t, npm.DetectDrift(fresh, fresh))
+}
+
+func TestDetectDrift_StaleLockedVersion(t *testing.T) {
+	locked := &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"}},
+			},
+			"foo@1.0.0": {Name: "foo", Version: "1.0.0"},
+			"foo@0.9.0": {Name: "foo", Version: "0.9.0"},
+			"bar@1.0.0": {Name: "bar", Version: "1.0.0"},
+		},
+	}
+	fresh := &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"}},
+			},
+			"foo@1.0.0": {Name: "foo", Version: "1.0.0"},
+		},
+		Skipped: []npm.SkippedDependency{{Name: "bar", Spec: "^2.0.0", Error: "no matching version"}},
+	}
+
+	assert.Equal(t, []npm.Drift{
+		{Kind: npm.DriftOrphaned, Name: "foo", Locked: "0.9.0"},
+	}, npm.DetectDrift(locked, fresh))
+}
+
+func TestDetectDrift_UnrecordedRootDependencies(t *testing.T) {
+	lockfiles := []struct {
+		name     string
+		lockfile string
+	}{
+		{
+			name: "package-lock.json v1",
+			lockfile: `{"name":"app","version":"1.0.0","lockfileVersion":1,"requires":true,"dependencies":{` +
+				`"bar":{"version":"1.0.0","requires":{"foo":"^1.0.0"}},` +
+				`"foo":{"version":"1.1.0"}}}`,
+		},
+		{
+			name: "yarn classic",
+			lockfile: "# yarn lockfile v1\n\n" +
+				"bar@^1.0.0:\n  version \"1.0.0\"\n  dependencies:\n    foo \"^1.0.0\"\n\n" +
+				"foo@^1.0.0:\n  version \"1.1.0\"\n",
+		},
+	}
+
+	freshGraph := func(fooVersion string) *npm.Graph {
+		return &npm.Graph{
+			Root: "app@1.0.0",
+			Nodes: map[string]*npm.Node{
+				"app@1.0.0": {
+					Name: "app", Version: "1.0.0",
+					Dependencies: map[string]npm.Edge{
+						"foo": {Constraint: "^1.0.0", Node: "foo@" + fooVersion},
+						"bar": {Constraint: "^1.0.0", Node: "bar@1.0.0"},
+					},
+				},
+				"bar@1.0.0": {
+					Name: "bar", Version: "1.0.0",
+					Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@" + fooVersion}},
+				},
+				"foo@" + fooVersion: {Name: "foo", Version: fooVersion},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		fresh          *npm.Graph
+		expectedDrifts []npm.Drift
+	}{
+		{
+			name:           "up to date",
+			fresh:          freshGraph("1.1.0"),
+			expectedDrifts: []npm.Drift{},
+		},
+		{
+			name:  "outdated",
+			fresh: freshGraph("1.2.0"),
+			expectedDrifts: []npm.Drift{
+				{Kind: npm.DriftOutdated, Name: "foo", Constraint: "^1.0.0", Locked: "1.1.0", Resolved: "1.2.0"},
+				{Kind: npm.DriftOutdated, Name: "foo", Dependent: "bar", Constraint: "^1.0.0", Locked: "1.1.0", Resolved: "1.2.0"},
+			},
+		},
+	}
+
+	for _, lf := range lockfiles {
+		for _, tc := range testCases {
+			t.Run(lf.name+" "+tc.name, func(t *testing.T) {
+				locked, err := npm.ParseLockfile(strings.NewReader(lf.lockfile))
+				require.NoError(t, err)
+				_, recorded := locked.RootNode().Dependencies["foo"]
+				require.False(t, recorded, "the lockfile does not record the direct dependencies")
+
+				assert.Equal(t, tc.expectedDrifts, npm.DetectDrift(locked, tc.fresh))
+			})
+		}
+	}
+}
@@ -1,7 +1,140 @@
 package npm
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
-// ErrPackageNotFound indicates the package/version is
-// not found in the registry.
-var ErrPackageNotFound = errors.New("package not found")
+var (
+	// ErrPackageNotFound indicates the package/version is
+	// not found in the registry.
+	ErrPackageNotFound = errors.New("package not found")
+
+	// ErrInvalidManifest indicates a package.json manifest
+	// is malformed or declares invalid dependencies.
+	ErrInvalidManifest = errors.New("invalid manifest")
+
+	// ErrInvalidLockfile indicates a lockfile is malformed
+	// or inconsistent.
+	ErrInvalidLockfile = errors.New("invalid lockfile")
+
+	// ErrUnsupportedLockfile indicates a lockfile format or
+	// version that cannot be read.
+	ErrUnsupportedLockfile = errors.New("unsupported lockfile")
+
+	// ErrRegistryUnavailable indicates the registry cannot be
+	// reached or responds with a server error.
+	ErrRegistryUnavailable = errors.New("registry unavailable")
+
+	// ErrRegistryTimeout indicates the registry does not
+	// respond in time.
+	ErrRegistryTimeout = errors.New("registry timeout")
+
+	// ErrRateLimited indicates a request to the registry cannot
+	// be sent before its deadline within the rate limit of the host.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrCircuitOpen indicates a request to the registry is
+	// not sent while its circuit breaker is open.
+	ErrCircuitOpen = errors.New("registry circuit open")
+)
+
+type (
+	// PackageNotFoundError indicates a package is not found in the registry.
+	// It matches [ErrPackageNotFound] with [errors.Is].
+	PackageNotFoundError struct {
+		// Name is the name of the package.
+		Name string
+	}
+
+	// VersionNotFoundError indicates a version of a package is not found in the registry.
+	// It matches [ErrPackageNotFound] with [errors.Is].
+	VersionNotFoundError struct {
+		// Name is the name of the package.
+		Name string
+		// Version is the version that is not found.
+		Version string
+	}
+
+	// RegistryError is an unexpected error response of the registry. It matches [ErrRegistryTimeout]
+	// with [errors.Is] for a 504 response, and [ErrRegistryUnavailable] for the other server errors.
+	RegistryError struct {
+		// StatusCode is the HTTP status code of the response.
+		StatusCode int
+		// URL is the URL of the request.
+		URL string
+		// RetryAfter is the delay before retrying requested by the Retry-After header, or zero.
+		RetryAfter time.Duration
+		// Message is the error message of the response body, when it has a known error shape.
+		Message string
+		// Body is an excerpt of the response body, of at most maxRegistryErrorExcerpt bytes.
+		Body string
+	}
+
+	// InvalidDependencySpecError indicates a package declares a dependency with an invalid version constraint.
+	InvalidDependencySpecError struct {
+		// Dependent is the ID of the package declaring the dependency.
+		Dependent string
+		// Name is the name of the dependency.
+		Name string
+		// Spec is the invalid version constraint of the dependency.
+		Spec string
+		// Err is the parsing error of the version constraint.
+		Err error
+	}
+)
+
+// Error implements the error interface.
+func (e *PackageNotFoundError) Error() string {
+	return fmt.Sprintf("package %s not found", e.Name)
+}
+
+// Unwrap returns [ErrPackageNotFound].
+func (e *PackageNotFoundError) Unwrap() error {
+	return ErrPackageNotFound
+}
+
+// Error implements the error interface.
+func (e *VersionNotFoundError) Error() string {
+	return fmt.Sprintf("version %s of %s not found", e.Version, e.Name)
+}
+
+// Unwrap returns [ErrPackageNotFound].
+func (e *VersionNotFoundError) Unwrap() error {
+	return ErrPackageNotFound
+}
+
+// Error implements the error interface.
+func (e *InvalidDependencySpecError) Error() string {
+	return fmt.Sprintf("invalid version constraint %q of %s in %s: %v", e.Spec, e.Name, e.Dependent, e.Err)
+}
+
+// Unwrap returns the parsing error of the version constraint.
+func (e *InvalidDependencySpecError) Unwrap() error {
+	return e.Err
+}
+
+// Error implements the error interface.
+func (e *RegistryError) Error() string {
+	detail := e.Message
+	if detail == "" {
+		detail = e.Body
+	}
+	if detail == "" {
+		detail = http.StatusText(e.StatusCode)
+	}
+	return fmt.Sprintf("registry response %d for %q: %s", e.StatusCode, e.URL, detail)
+}
+
+// Is reports whether the error matches [ErrRegistryTimeout] or [ErrRegistryUnavailable].
+func (e *RegistryError) Is(target error) bool {
+	switch target {
+	case ErrRegistryTimeout:
+		return e.StatusCode == http.StatusGatewayTimeout
+	case ErrRegistryUnavailable:
+		return e.StatusCode >= http.StatusInternalServerError && e.StatusCode != http.StatusGatewayTimeout
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package npm
+
+import (
+	"maps"
+	"slices"
+)
+
+type (
+	// Graph is a dependency graph of resolved NPM packages, deduplicated by name and version.
+	Graph struct {
+		// Root is the ID of the root node of the graph.
+		Root string `json:"root"`
+		// Nodes maps the ID of every resolved package to its node.
+		Nodes map[string]*Node `json:"nodes"`
+		// Skipped are the dependencies of the root that are not resolved, when it is a manifest.
+		Skipped []SkippedDependency `json:"skipped,omitempty"`
+	}
+
+	// Node is a resolved NPM package of a [Graph].
+	Node struct {
+		// Name is the name of the NPM package.
+		Name string `json:"name"`
+		// Version is the resolved version of the NPM package.
+		Version string `json:"version"`
+		// Resolved is the location the package tarball is fetched from, when known.
+		Resolved string `json:"resolved,omitempty"`
+		// Integrity is the subresource integrity string of the package tarball, when known.
+		Integrity string `json:"integrity,omitempty"`
+		// Checksum is the lockfile specific checksum of the package, like the yarn berry cache checksum.
+		Checksum string `json:"checksum,omitempty"`
+		// License is the license declared by the package, when known.
+		License string `json:"license,omitempty"`
+		// Dependencies maps the name of every direct dependency of the package
+		// to the edge leading to its resolved node.
+		Dependencies map[string]Edge `json:"dependencies,omitempty"`
+	}
+
+	// Edge is a dependency relationship between two nodes of a [Graph].
+	Edge struct {
+		// Constraint is the version constraint declared by the dependent package.
+		Constraint string `json:"constraint"`
+		// Node is the ID of the resolved dependency node.
+		Node string `json:"node"`
+	}
+)
+
+// NodeID returns the ID of the [Node] of an NPM package, in the name@version form.
+func NodeID(name, version string) string {
+	return name + "@" + version
+}
+
+// NewGraph creates a [Graph] containing only the provided root node.
+func NewGraph(root *Node) *Graph {
+	id := NodeID(root.Name, root.Version)
+	return &Graph{
+		Root:  id,
+		Nodes: map[string]*Node{id: root},
+	}
+}
+
+// ID returns the ID of the node.
+func (n *Node) ID() string {
+	return NodeID(n.Name, n.Version)
+}
+
+// RootNode returns the root node of the graph.
+func (g *Graph) RootNode() *Node {
+	return g.Nodes[g.Root]
+}
+
+// Merge adds the dependencies of the root node of the other graph, whose root is the same package, to the root node
+// of the graph, along with the nodes of the other graph it does not hold yet.
+func (g *Graph) Merge(other *Graph) {
+	for id, node := range other.Nodes {
+		if _, ok := g.Nodes[id]; !ok && id != other.Root {
+			g.Nodes[id] = node
+		}
+	}
+
+	root := g.RootNode()
+	if root.Dependencies == nil {
+		root.Dependencies = map[string]Edge{}
+	}
+	maps.Copy(root.Dependencies, other.RootNode().Dependencies)
+}
+
+// SortedIDs returns the IDs of all the nodes of the graph, in lexical order.
+func (g *Graph) SortedIDs() []string {
+	return slices.Sorted(maps.Keys(g.Nodes))
+}
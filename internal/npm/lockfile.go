@@ -0,0 +1,108 @@
+package npm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// lockfileRootName is the name of the root node of a lockfile without a project name.
+const lockfileRootName = manifestRootName
+
+// ParseLockfile reads a lockfile into a [Graph], without contacting the registry.
+// The lockfile format is detected from its content.
+func ParseLockfile(r io.Reader) (*Graph, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading lockfile: %w", err)
+	}
+
+	trimmed := bytes.TrimSpace(content)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return ParsePackageLock(bytes.NewReader(trimmed))
+	case bytes.HasPrefix(trimmed, []byte("lockfileVersion:")):
+		return ParsePnpmLock(bytes.NewReader(trimmed))
+	case isYarnBerryLock(content):
+		return parseYarnBerryLock(content)
+	case isYarnClassicLock(content):
+		return parseYarnClassicLock(content)
+	}
+
+	return nil, fmt.Errorf("%w: unknown lockfile format", ErrUnsupportedLockfile)
+}
+
+// lockfileGraph incrementally builds the [Graph] of a lockfile,
+// deduplicating packages installed in several locations.
+type lockfileGraph struct {
+	graph *Graph
+}
+
+func newLockfileGraph(name, version string) *lockfileGraph {
+	if name == "" {
+		name = lockfileRootName
+	}
+	return &lockfileGraph{graph: NewGraph(&Node{Name: name, Version: version})}
+}
+
+// node returns the node of the package, adding it to the graph when missing.
+func (lg *lockfileGraph) node(name, version, resolved, integrity string) *Node {
+	id := NodeID(name, version)
+	if node, ok := lg.graph.Nodes[id]; ok {
+		return node
+	}
+
+	node := &Node{Name: name, Version: version, Resolved: resolved, Integrity: integrity}
+	lg.graph.Nodes[id] = node
+	return node
+}
+
+// link adds the dependency edge between the parent and the child nodes.
+func (*lockfileGraph) link(parent *Node, depName, constraint string, child *Node) {
+	if parent.Dependencies == nil {
+		parent.Dependencies = map[string]Edge{}
+	}
+	parent.Dependencies[depName] = Edge{Constraint: constraint, Node: child.ID()}
+}
+
+// unreachable returns the set of IDs of the nodes that cannot be reached from the root node.
+func (lg *lockfileGraph) unreachable() map[string]bool {
+	seen := map[string]bool{}
+	stack := []string{lg.graph.Root}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		for _, edge := range lg.graph.Nodes[id].Dependencies {
+			stack = append(stack, edge.Node)
+		}
+	}
+
+	ids := map[string]bool{}
+	for id := range lg.graph.Nodes {
+		if !seen[id] {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// parseAliasSpec parses an npm:name@version alias specification,
+// returning the aliased package name and its version.
+func parseAliasSpec(spec string) (name, version string, ok bool) {
+	spec, ok = strings.CutPrefix(spec, "npm:")
+	if !ok {
+		return "", "", false
+	}
+
+	at := strings.LastIndex(spec, "@")
+	if at <= 0 {
+		return "", "", false
+	}
+
+	return spec[:at], spec[at+1:], true
+}
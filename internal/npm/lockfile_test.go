@@ -0,0 +1,207 @@
+package npm_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestParseLockfile(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		expectedGraph *npm.Graph
+		expectedErr   string
+	}{
+		{
+			name:        "unknown format",
+			content:     "not a lockfile",
+			expectedErr: "unsupported lockfile: unknown lockfile format",
+		},
+		{
+			name:        "package-lock.json decoding error",
+			content:     `{"lockfileVersion":"3"}`,
+			expectedErr: "invalid lockfile: decoding package-lock.json: json: cannot unmarshal string into Go struct field packageLock.lockfileVersion of type int",
+		},
+		{
+			name:        "package-lock.json missing lockfileVersion",
+			content:     `{"name":"app"}`,
+			expectedErr: "invalid lockfile: missing lockfileVersion in package-lock.json",
+		},
+		{
+			name:        "package-lock.json unsupported lockfileVersion",
+			content:     `{"lockfileVersion":4}`,
+			expectedErr: "unsupported lockfile: package-lock.json lockfileVersion 4",
+		},
+		{
+			name:        "package-lock.json missing packages",
+			content:     `{"lockfileVersion":2}`,
+			expectedErr: "invalid lockfile: missing packages in package-lock.json",
+		},
+		{
+			name:        "package-lock.json dependency not locked",
+			content:     `{"lockfileVersion":3,"packages":{"":{"dependencies":{"foo":"^1.0.0"}}}}`,
+			expectedErr: "invalid lockfile: dependency foo of \"\" is not locked",
+		},
+		{
+			name:        "package-lock.json link to missing location",
+			content:     `{"lockfileVersion":3,"packages":{"":{},"node_modules/ui":{"resolved":"packages/ui","link":true}}}`,
+			expectedErr: "invalid lockfile: link \"node_modules/ui\" targets missing location \"packages/ui\"",
+		},
+		{
+			name:        "package-lock.json v1 dependency not locked",
+			content:     `{"lockfileVersion":1,"dependencies":{"foo":{"version":"1.0.0","requires":{"bar":"^1.0.0"}}}}`,
+			expectedErr: "invalid lockfile: dependency bar of foo@1.0.0 is not locked",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			graph, err := npm.ParseLockfile(strings.NewReader(tc.content))
+
+			assert.Equal(t, tc.expectedGraph, graph)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestParsePackageLock(t *testing.T) {
+	testCases := []struct {
+		name          string
+		file          string
+		expectedGraph *npm.Graph
+	}{
+		{
+			name: "lockfile version 3 with alias, nested package and workspace",
+			file: "testdata/package-lock_v3.json",
+			expectedGraph: &npm.Graph{
+				Root: "app@1.0.0",
+				Nodes: map[string]*npm.Node{
+					"app@1.0.0": {
+						Name:    "app",
+						Version: "1.0.0",
+						Dependencies: map[string]npm.Edge{
+							"bar-alias": {Constraint: "npm:bar@^2.0.0", Node: "bar@2.1.0"},
+							"baz":       {Constraint: "~3.1.0", Node: "baz@3.1.4"},
+							"foo":       {Constraint: "^1.0.0", Node: "foo@1.4.0"},
+							"ui":        {Constraint: "*", Node: "ui@0.1.0"},
+						},
+					},
+					"bar@2.1.0": {
+						Name: "bar", Version: "2.1.0",
+						Resolved: "https://registry.npmjs.org/bar/-/bar-2.1.0.tgz", Integrity: "sha512-bar",
+					},
+					"baz@3.1.4": {
+						Name: "baz", Version: "3.1.4",
+						Resolved: "https://registry.npmjs.org/baz/-/baz-3.1.4.tgz", Integrity: "sha512-baz3",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^2.0.0", Node: "foo@2.0.0"}},
+					},
+					"foo@1.4.0": {
+						Name: "foo", Version: "1.4.0",
+						Resolved: "https://registry.npmjs.org/foo/-/foo-1.4.0.tgz", Integrity: "sha512-foo1",
+					},
+					"foo@2.0.0": {
+						Name: "foo", Version: "2.0.0",
+						Resolved: "https://registry.npmjs.org/foo/-/foo-2.0.0.tgz", Integrity: "sha512-foo2",
+					},
+					"ui@0.1.0": {
+						Name: "ui", Version: "0.1.0",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.2.0", Node: "foo@1.4.0"}},
+					},
+				},
+			},
+		},
+		{
+			name: "lockfile version 2 with workspaces also declared as root dependencies",
+			file: "testdata/package-lock_v2.json",
+			expectedGraph: &npm.Graph{
+				Root: "app@1.0.0",
+				Nodes: map[string]*npm.Node{
+					"app@1.0.0": {
+						Name:    "app",
+						Version: "1.0.0",
+						Dependencies: map[string]npm.Edge{
+							"api": {Constraint: "*", Node: "api@0.2.0"},
+							"foo": {Constraint: "^1.0.0", Node: "foo@1.4.0"},
+							"ui":  {Constraint: "^0.1.0", Node: "ui@0.1.0"},
+						},
+					},
+					"api@0.2.0": {
+						Name: "api", Version: "0.2.0",
+						Dependencies: map[string]npm.Edge{"ui": {Constraint: "^0.1.0", Node: "ui@0.1.0"}},
+					},
+					"foo@1.4.0": {
+						Name: "foo", Version: "1.4.0",
+						Resolved: "https://registry.npmjs.org/foo/-/foo-1.4.0.tgz", Integrity: "sha512-foo1",
+					},
+					"ui@0.1.0": {
+						Name: "ui", Version: "0.1.0",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.2.0", Node: "foo@1.4.0"}},
+					},
+				},
+			},
+		},
+		{
+			name: "lockfile version 1 with alias and nested package",
+			file: "testdata/package-lock_v1.json",
+			expectedGraph: &npm.Graph{
+				Root: "app@1.0.0",
+				Nodes: map[string]*npm.Node{
+					"app@1.0.0": {
+						Name:    "app",
+						Version: "1.0.0",
+						Dependencies: map[string]npm.Edge{
+							"bar":       {Constraint: "2.1.0", Node: "bar@2.1.0"},
+							"baz":       {Constraint: "3.1.4", Node: "baz@3.1.4"},
+							"qux-alias": {Constraint: "1.0.0", Node: "qux@1.0.0"},
+						},
+					},
+					"bar@2.1.0": {
+						Name: "bar", Version: "2.1.0",
+						Resolved: "https://registry.npmjs.org/bar/-/bar-2.1.0.tgz", Integrity: "sha512-bar",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.4.0"}},
+					},
+					"baz@3.1.4": {
+						Name: "baz", Version: "3.1.4",
+						Resolved: "https://registry.npmjs.org/baz/-/baz-3.1.4.tgz", Integrity: "sha512-baz3",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^2.0.0", Node: "foo@2.0.0"}},
+					},
+					"foo@1.4.0": {
+						Name: "foo", Version: "1.4.0",
+						Resolved: "https://registry.npmjs.org/foo/-/foo-1.4.0.tgz", Integrity: "sha512-foo1",
+					},
+					"foo@2.0.0": {
+						Name: "foo", Version: "2.0.0",
+						Resolved: "https://registry.npmjs.org/foo/-/foo-2.0.0.tgz", Integrity: "sha512-foo2",
+					},
+					"qux@1.0.0": {
+						Name: "qux", Version: "1.0.0",
+						Resolved: "https://registry.npmjs.org/qux/-/qux-1.0.0.tgz", Integrity: "sha512-qux",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := os.Open(tc.file)
+			require.NoError(t, err)
+			defer f.Close()
+
+			graph, err := npm.ParseLockfile(f)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedGraph, graph)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package npm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// manifestRootName is the name of the root node of a manifest without a name,
+// which is common for unpublished projects.
+const manifestRootName = "root"
+
+type (
+	// Manifest contains the dependency related fields of a package.json file.
+	Manifest struct {
+		// Name is the name of the project.
+		Name string `json:"name,omitempty"`
+		// Version is the version of the project.
+		Version string `json:"version,omitempty"`
+		// Dependencies maps the production dependencies to their version constraint.
+		Dependencies map[string]string `json:"dependencies,omitempty"`
+		// DevDependencies maps the development dependencies to their version constraint.
+		DevDependencies map[string]string `json:"devDependencies,omitempty"`
+		// OptionalDependencies maps the optional dependencies to their version constraint.
+		OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
+	}
+
+	// SkippedDependency is a dependency of a [Manifest] that is not resolved from the registry, since its
+	// specification is not a version range, like a git URL, a local path, an alias or a dist-tag,
+	// or since it is an optional dependency that failed to resolve.
+	SkippedDependency struct {
+		// Name is the name of the dependency.
+		Name string `json:"name"`
+		// Spec is the specification of the dependency.
+		Spec string `json:"spec"`
+		// Error is the resolution error of an optional dependency.
+		Error string `json:"error,omitempty"`
+	}
+
+	// ManifestOptions selects the dependency types of a [Manifest] to resolve.
+	// Production dependencies are always included.
+	ManifestOptions struct {
+		// Dev includes the devDependencies.
+		Dev bool
+		// Optional includes the optionalDependencies.
+		Optional bool
+	}
+)
+
+// ParseManifest decodes a package.json manifest, and validates the dependencies selected by the options.
+func ParseManifest(r io.Reader, opts ManifestOptions) (*Manifest, error) {
+	var m Manifest
+	if err := json.NewDecoder(r).Decode(&m); err != nil {
+		return nil, fmt.Errorf("%w: decoding: %w", ErrInvalidManifest, err)
+	}
+
+	if err := m.Validate(opts); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Validate checks that every dependency selected by the options has a valid name. The dependencies whose
+// specification is not a version range are not invalid, they are skipped by the resolution.
+func (m *Manifest) Validate(opts ManifestOptions) error {
+	var errs error
+	for _, name := range slices.Sorted(maps.Keys(m.dependencies(opts))) {
+		if err := validatePackageName(name); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("dependency %q: %w", name, err))
+		}
+	}
+
+	if errs != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidManifest, errs)
+	}
+
+	return nil
+}
+
+// Package returns the root [Package] of the manifest, declaring the dependencies selected by the options
+// whose specification is a version range. Production dependencies take precedence over optional ones,
+// which take precedence over development ones, like npm does when the same package is declared more than once.
+func (m *Manifest) Package(opts ManifestOptions) *Package {
+	pkg := &Package{
+		Name:         m.Name,
+		Version:      m.Version,
+		Dependencies: make(map[string]string, len(m.Dependencies)),
+	}
+	if pkg.Name == "" {
+		pkg.Name = manifestRootName
+	}
+
+	for name, spec := range m.dependencies(opts) {
+		if _, err := semver.NewConstraint(spec); err == nil {
+			pkg.Dependencies[name] = spec
+		}
+	}
+
+	return pkg
+}
+
+// Skipped returns the dependencies selected by the options that the [Package] of the manifest does not declare,
+// since their specification is not a version range, sorted by name.
+func (m *Manifest) Skipped(opts ManifestOptions) []SkippedDependency {
+	var skipped []SkippedDependency
+	deps := m.dependencies(opts)
+	for _, name := range slices.Sorted(maps.Keys(deps)) {
+		if _, err := semver.NewConstraint(deps[name]); err != nil {
+			skipped = append(skipped, SkippedDependency{Name: name, Spec: deps[name]})
+		}
+	}
+	return skipped
+}
+
+// Optionals returns the dependencies of the [Package] selected by the options that are optional dependencies,
+// the ones that are not also production dependencies, since a failure to resolve them does not fail npm install.
+func (m *Manifest) Optionals(opts ManifestOptions) map[string]string {
+	optionals := map[string]string{}
+	if !opts.Optional {
+		return optionals
+	}
+
+	for name, spec := range m.Package(opts).Dependencies {
+		if _, ok := m.OptionalDependencies[name]; !ok {
+			continue
+		}
+		if _, ok := m.Dependencies[name]; !ok {
+			optionals[name] = spec
+		}
+	}
+	return optionals
+}
+
+// dependencies returns the specifications of the dependencies selected by the options, by name,
+// with the precedence of the dependency types.
+func (m *Manifest) dependencies(opts ManifestOptions) map[string]string {
+	deps := make(map[string]string, len(m.Dependencies))
+	if opts.Dev {
+		maps.Copy(deps, m.DevDependencies)
+	}
+	if opts.Optional {
+		maps.Copy(deps, m.OptionalDependencies)
+	}
+	maps.Copy(deps, m.Dependencies)
+	return deps
+}
+
+// validatePackageName checks the basic npm naming rules of a package name.
+func validatePackageName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("empty package name")
+	case len(name) > 214:
+		return errors.New("package name longer than 214 characters")
+	case strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_"):
+		return errors.New("package name cannot start with a period or an underscore")
+	case strings.TrimSpace(name) != name:
+		return errors.New("package name cannot contain leading or trailing spaces")
+	case strings.ContainsAny(name, "~'!()* "):
+		return errors.New("package name contains forbidden characters")
+	}
+
+	scope, base, scoped := strings.Cut(name, "/")
+	if scoped && (!strings.HasPrefix(scope, "@") || len(scope) == 1 || base == "" || strings.Contains(base, "/")) {
+		return errors.New("invalid scoped package name")
+	}
+	if !scoped && strings.HasPrefix(name, "@") {
+		return errors.New("invalid scoped package name")
+	}
+
+	return nil
+}
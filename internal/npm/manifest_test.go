@@ -0,0 +1,135 @@
+package npm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestParseManifest(t *testing.T) {
+	testCases := []struct {
+		name             string
+		content          string
+		opts             npm.ManifestOptions
+		expectedManifest *npm.Manifest
+		expectedErr      string
+	}{
+		{
+			name:        "decoding error",
+			content:     `{"dependencies":["foo"]}`,
+			expectedErr: "invalid manifest: decoding: json: cannot unmarshal array into Go struct field Manifest.dependencies of type map[string]string",
+		},
+		{
+			name:        "invalid dependency name",
+			content:     `{"dependencies":{"@scope":"^1.0.0"}}`,
+			expectedErr: "invalid manifest: dependency \"@scope\": invalid scoped package name",
+		},
+		{
+			name:        "invalid selected dev dependency name",
+			content:     `{"dependencies":{"foo":"^1.0.0"},"devDependencies":{"_bar":"^1.0.0"}}`,
+			opts:        npm.ManifestOptions{Dev: true},
+			expectedErr: "invalid manifest: dependency \"_bar\": package name cannot start with a period or an underscore",
+		},
+		{
+			name:    "invalid unselected dev dependency name",
+			content: `{"dependencies":{"foo":"^1.0.0"},"devDependencies":{"_bar":"^1.0.0"}}`,
+			expectedManifest: &npm.Manifest{
+				Dependencies:    map[string]string{"foo": "^1.0.0"},
+				DevDependencies: map[string]string{"_bar": "^1.0.0"},
+			},
+		},
+		{
+			name:    "unsupported dependency specs",
+			content: `{"dependencies":{"foo":"latest"},"devDependencies":{"bar":"git+https://github.com/bar/bar.git"}}`,
+			opts:    npm.ManifestOptions{Dev: true},
+			expectedManifest: &npm.Manifest{
+				Dependencies:    map[string]string{"foo": "latest"},
+				DevDependencies: map[string]string{"bar": "git+https://github.com/bar/bar.git"},
+			},
+		},
+		{
+			name:    "valid manifest",
+			content: `{"name":"app","private":true,"dependencies":{"foo":"^1.0.0","@scope/bar":"~2.1"},"devDependencies":{"baz":"*"}}`,
+			expectedManifest: &npm.Manifest{
+				Name:            "app",
+				Dependencies:    map[string]string{"foo": "^1.0.0", "@scope/bar": "~2.1"},
+				DevDependencies: map[string]string{"baz": "*"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest, err := npm.ParseManifest(strings.NewReader(tc.content), tc.opts)
+
+			assert.Equal(t, tc.expectedManifest, manifest)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestManifest_Package(t *testing.T) {
+	manifest := &npm.Manifest{
+		Dependencies:         map[string]string{"foo": "^1.0.0", "bar": "^2.0.0"},
+		DevDependencies:      map[string]string{"bar": "^3.0.0", "baz": "*"},
+		OptionalDependencies: map[string]string{"qux": "1.x"},
+	}
+
+	pkg := manifest.Package(npm.ManifestOptions{})
+	require.NotNil(t, pkg)
+	assert.Equal(t, &npm.Package{
+		Name:         "root",
+		Dependencies: map[string]string{"foo": "^1.0.0", "bar": "^2.0.0"},
+	}, pkg)
+
+	pkg = manifest.Package(npm.ManifestOptions{Dev: true, Optional: true})
+	assert.Equal(t, &npm.Package{
+		Name:         "root",
+		Dependencies: map[string]string{"foo": "^1.0.0", "bar": "^2.0.0", "baz": "*", "qux": "1.x"},
+	}, pkg)
+}
+
+func TestManifest_Skipped(t *testing.T) {
+	manifest := &npm.Manifest{
+		Dependencies: map[string]string{
+			"foo": "^1.0.0", "bar": "npm:baz@^1.0.0", "qux": "file:../qux", "quux": "workspace:*", "corge": "latest",
+		},
+		DevDependencies: map[string]string{"grault": "git+https://github.com/grault/grault.git"},
+	}
+
+	pkg := manifest.Package(npm.ManifestOptions{Dev: true})
+	assert.Equal(t, &npm.Package{Name: "root", Dependencies: map[string]string{"foo": "^1.0.0"}}, pkg)
+
+	assert.Equal(t, []npm.SkippedDependency{
+		{Name: "bar", Spec: "npm:baz@^1.0.0"},
+		{Name: "corge", Spec: "latest"},
+		{Name: "quux", Spec: "workspace:*"},
+		{Name: "qux", Spec: "file:../qux"},
+	}, manifest.Skipped(npm.ManifestOptions{}))
+	assert.Equal(t, []npm.SkippedDependency{
+		{Name: "bar", Spec: "npm:baz@^1.0.0"},
+		{Name: "corge", Spec: "latest"},
+		{Name: "grault", Spec: "git+https://github.com/grault/grault.git"},
+		{Name: "quux", Spec: "workspace:*"},
+		{Name: "qux", Spec: "file:../qux"},
+	}, manifest.Skipped(npm.ManifestOptions{Dev: true}))
+}
+
+func TestManifest_Optionals(t *testing.T) {
+	manifest := &npm.Manifest{
+		Dependencies:         map[string]string{"foo": "^1.0.0"},
+		DevDependencies:      map[string]string{"bar": "^2.0.0"},
+		OptionalDependencies: map[string]string{"foo": "^1.1.0", "bar": "^2.1.0", "baz": "*", "qux": "latest"},
+	}
+
+	assert.Empty(t, manifest.Optionals(npm.ManifestOptions{Dev: true}))
+	assert.Equal(t, map[string]string{"bar": "^2.1.0", "baz": "*"}, manifest.Optionals(npm.ManifestOptions{Dev: true, Optional: true}))
+}
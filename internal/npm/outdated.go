@@ -0,0 +1,167 @@
+package npm
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+	"golang.org/x/sync/errgroup"
+
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+// latestDistTag is the distribution tag of the version installed by default.
+const latestDistTag = "latest"
+
+// Outdated compares the version of a direct dependency to the versions available in the registry,
+// like the npm outdated command.
+type Outdated struct {
+	// Name is the name of the dependency.
+	Name string `json:"name"`
+	// Constraint is the declared version constraint of the dependency.
+	Constraint string `json:"constraint"`
+	// Current is the currently installed version, the wanted one when unknown.
+	// The gaps of a current version that is not a semver version, like a git dependency, are unknown.
+	Current string `json:"current"`
+	// Wanted is the highest version satisfying the constraint.
+	Wanted string `json:"wanted"`
+	// Latest is the version of the latest distribution tag.
+	Latest string `json:"latest"`
+	// WantedGap is the difference between the current and wanted versions.
+	WantedGap semverutil.Gap `json:"wantedGap,omitempty"`
+	// LatestGap is the difference between the current and latest versions.
+	LatestGap semverutil.Gap `json:"latestGap,omitempty"`
+}
+
+// ResolvePackageOutdated reports the outdated direct dependencies of an NPM package, for the highest version
+// satisfying the constraint. The current version of every dependency is the wanted one, like for a fresh install.
+func (r Resolver) ResolvePackageOutdated(ctx context.Context, name string, constraint *semver.Constraints) ([]Outdated, error) {
+	version, err := r.resolvePackageHighestVersion(ctx, name, constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := r.client.FetchPackage(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("fetch package %s/%s: %w", name, version, err)
+	}
+
+	return r.ResolveOutdated(ctx, pkg.Dependencies, nil)
+}
+
+// ResolveOutdated reports the outdated dependencies, mapping the package names to their version constraint.
+// The current versions map the package names to their installed version, when known. The report is sorted by
+// package name and omits the dependencies that are current with the latest version.
+func (r Resolver) ResolveOutdated(ctx context.Context, deps, current map[string]string) ([]Outdated, error) {
+	var (
+		mu       sync.Mutex
+		outdated = []Outdated{}
+	)
+
+	grp, grpCtx := errgroup.WithContext(ctx)
+	grp.SetLimit(maxConcurrentFetches)
+	for _, name := range slices.Sorted(maps.Keys(deps)) {
+		grp.Go(func() error {
+			o, err := r.resolveOutdated(grpCtx, name, deps[name], current[name])
+			if err != nil {
+				return err
+			}
+			if o.LatestGap != semverutil.GapNone || o.WantedGap != semverutil.GapNone {
+				mu.Lock()
+				outdated = append(outdated, *o)
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+
+	if err := grp.Wait(); err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(outdated, func(a, b Outdated) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return outdated, nil
+}
+
+func (r Resolver) resolveOutdated(ctx context.Context, name, constraintStr, current string) (*Outdated, error) {
+	constraint, err := semver.NewConstraint(constraintStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint of %s: %w", name, err)
+	}
+
+	meta, err := r.client.FetchPackageMeta(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("fetch package meta %s: %w", name, err)
+	}
+
+	wanted, err := resolveHighestVersion(meta, name, constraintStr, constraint)
+	if err != nil {
+		return nil, fmt.Errorf("resolve highest version of %s@%s: %w", name, constraintStr, err)
+	}
+
+	latest, ok := meta.DistTags[latestDistTag]
+	if !ok {
+		return nil, fmt.Errorf("package %s has no %s dist-tag", name, latestDistTag)
+	}
+
+	o := &Outdated{Name: name, Constraint: constraintStr, Current: current, Wanted: wanted, Latest: latest}
+	if o.Current == "" {
+		o.Current = wanted
+	}
+
+	// The current version of a git, file or tarball dependency, or of an alias, is not a semver version.
+	if o.WantedGap, err = semverutil.VersionGap(o.Current, wanted); err != nil {
+		o.WantedGap = semverutil.GapUnknown
+	}
+	if o.LatestGap, err = semverutil.VersionGap(o.Current, latest); err != nil {
+		o.LatestGap = semverutil.GapUnknown
+	}
+
+	return o, nil
+}
+
+// CurrentVersions returns the installed versions of the dependencies, mapping the package names to their version
+// constraint, read from a lockfile graph. The dependencies that the lockfile root does not record, like in npm v1
+// and yarn classic lockfiles, are matched by name among the locked packages, preferring the highest version
+// satisfying their constraint.
+func CurrentVersions(locked *Graph, deps map[string]string) map[string]string {
+	root := locked.RootNode()
+
+	lockedVersions := map[string][]string{}
+	for id, node := range locked.Nodes {
+		if _, recorded := root.Dependencies[node.Name]; !recorded && id != locked.Root {
+			lockedVersions[node.Name] = append(lockedVersions[node.Name], node.Version)
+		}
+	}
+
+	current := map[string]string{}
+	for name, constraintStr := range deps {
+		if edge, ok := root.Dependencies[name]; ok {
+			current[name] = locked.Nodes[edge.Node].Version
+			continue
+		}
+
+		versions := lockedVersions[name]
+		if len(versions) == 0 {
+			continue
+		}
+		// The versions stay sorted lexicographically when one of them is not a semver version.
+		slices.Sort(versions)
+		_ = semverutil.SortVersions(versions)
+		current[name] = versions[len(versions)-1]
+		if constraint, err := semver.NewConstraint(constraintStr); err == nil {
+			if v, err := semverutil.ResolveHighestVersion(constraint, slices.Values(versions)); err == nil {
+				current[name] = v
+			}
+		}
+	}
+
+	return current
+}
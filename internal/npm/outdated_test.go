@@ -0,0 +1,197 @@
+package npm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	mocksnpm "github.com/snyk/npmjs-deps-fetcher/internal/npm/mocks"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+func TestResolver_ResolveOutdated(t *testing.T) {
+	fooMeta := &npm.PackageMeta{
+		Name:     "foo",
+		DistTags: map[string]string{"latest": "2.1.0"},
+		Versions: map[string]npm.Package{
+			"1.0.0": {Name: "foo", Version: "1.0.0"},
+			"1.2.0": {Name: "foo", Version: "1.2.0"},
+			"2.1.0": {Name: "foo", Version: "2.1.0"},
+		},
+	}
+	barMeta := &npm.PackageMeta{
+		Name:     "bar",
+		DistTags: map[string]string{"latest": "3.0.1"},
+		Versions: map[string]npm.Package{
+			"3.0.0": {Name: "bar", Version: "3.0.0"},
+			"3.0.1": {Name: "bar", Version: "3.0.1"},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		deps, current    map[string]string
+		setup            func(testing.TB) npm.PackageFetcher
+		expectedOutdated []npm.Outdated
+		expectedErr      string
+	}{
+		{
+			name: "fetch meta failure",
+			deps: map[string]string{"foo": "^1.0.0"},
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(nil, errors.New("something bad happened"))
+				return fetcher
+			},
+			expectedErr: "fetch package meta foo: something bad happened",
+		},
+		{
+			name: "missing latest dist-tag",
+			deps: map[string]string{"foo": "^1.0.0"},
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+					Name:     "foo",
+					Versions: fooMeta.Versions,
+				}, nil)
+				return fetcher
+			},
+			expectedErr: "package foo has no latest dist-tag",
+		},
+		{
+			name:    "outdated dependencies",
+			deps:    map[string]string{"foo": "^1.0.0", "bar": "~3.0.0"},
+			current: map[string]string{"foo": "1.0.0"},
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(fooMeta, nil)
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(barMeta, nil)
+				return fetcher
+			},
+			expectedOutdated: []npm.Outdated{
+				{
+					Name: "foo", Constraint: "^1.0.0", Current: "1.0.0", Wanted: "1.2.0", Latest: "2.1.0",
+					WantedGap: semverutil.GapMinor, LatestGap: semverutil.GapMajor,
+				},
+			},
+		},
+		{
+			name:    "current version not semver",
+			deps:    map[string]string{"foo": "^1.0.0"},
+			current: map[string]string{"foo": "github:acme/foo#v1"},
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(fooMeta, nil)
+				return fetcher
+			},
+			expectedOutdated: []npm.Outdated{
+				{
+					Name: "foo", Constraint: "^1.0.0", Current: "github:acme/foo#v1", Wanted: "1.2.0", Latest: "2.1.0",
+					WantedGap: semverutil.GapUnknown, LatestGap: semverutil.GapUnknown,
+				},
+			},
+		},
+		{
+			name:    "current version ahead of latest",
+			deps:    map[string]string{"bar": "~3.0.0"},
+			current: map[string]string{"bar": "3.0.2"},
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(barMeta, nil)
+				return fetcher
+			},
+			expectedOutdated: []npm.Outdated{
+				{
+					Name: "bar", Constraint: "~3.0.0", Current: "3.0.2", Wanted: "3.0.1", Latest: "3.0.1",
+					WantedGap: semverutil.GapAhead, LatestGap: semverutil.GapAhead,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := npm.NewResolver(tc.setup(t))
+
+			outdated, err := resolver.ResolveOutdated(context.Background(), tc.deps, tc.current)
+
+			assert.Equal(t, tc.expectedOutdated, outdated)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestResolver_ResolvePackageOutdated(t *testing.T) {
+	constraint, err := semver.NewConstraint("^1.0.0")
+	require.NoError(t, err)
+
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").Return(&npm.PackageMeta{
+		Name:     "app",
+		Versions: map[string]npm.Package{"1.0.0": {Name: "app", Version: "1.0.0"}},
+	}, nil)
+	fetcher.EXPECT().FetchPackage(gomock.Any(), "app", "1.0.0").Return(&npm.Package{
+		Name:         "app",
+		Version:      "1.0.0",
+		Dependencies: map[string]string{"foo": "~1.2.0"},
+	}, nil)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+		Name:     "foo",
+		DistTags: map[string]string{"latest": "1.3.0"},
+		Versions: map[string]npm.Package{
+			"1.2.0": {Name: "foo", Version: "1.2.0"},
+			"1.3.0": {Name: "foo", Version: "1.3.0"},
+		},
+	}, nil)
+
+	outdated, err := npm.NewResolver(fetcher).ResolvePackageOutdated(context.Background(), "app", constraint)
+
+	require.NoError(t, err)
+	assert.Equal(t, []npm.Outdated{
+		{Name: "foo", Constraint: "~1.2.0", Current: "1.2.0", Wanted: "1.2.0", Latest: "1.3.0", LatestGap: semverutil.GapMinor},
+	}, outdated)
+}
+
+func TestCurrentVersions(t *testing.T) {
+	// The root records foo, like in package-lock.json v2 and v3, but not bar and baz, like in v1 and yarn classic.
+	locked := &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.1.0"}},
+			},
+			"foo@1.1.0": {
+				Name: "foo", Version: "1.1.0",
+				Dependencies: map[string]npm.Edge{"bar": {Constraint: "^1.0.0", Node: "bar@1.10.0"}},
+			},
+			"foo@2.0.0":  {Name: "foo", Version: "2.0.0"},
+			"bar@1.9.0":  {Name: "bar", Version: "1.9.0"},
+			"bar@1.10.0": {Name: "bar", Version: "1.10.0"},
+			"bar@2.0.0":  {Name: "bar", Version: "2.0.0"},
+			"baz@0.9.0":  {Name: "baz", Version: "0.9.0"},
+			"baz@0.10.0": {Name: "baz", Version: "0.10.0"},
+		},
+	}
+
+	current := npm.CurrentVersions(locked, map[string]string{
+		"foo": "^2.0.0", "bar": "~1.9.0 || ^1.10.0", "baz": "^1.0.0", "qux": "^1.0.0",
+	})
+
+	assert.Equal(t, map[string]string{"foo": "1.1.0", "bar": "1.10.0", "baz": "0.10.0"}, current)
+}
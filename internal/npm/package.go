@@ -1,5 +1,13 @@
 package npm
 
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
 type (
 	// Package contains the info of an NPM package version.
 	Package struct {
@@ -10,13 +18,102 @@ type (
 		// Dependencies contains the direct dependencies of an NPM package,
 		// mapping the package name to its version constraint.
 		Dependencies map[string]string `json:"dependencies,omitempty"`
+		// License is the license of the NPM package version.
+		License License `json:"license,omitempty"`
+		// Dist contains the distribution info of the NPM package version.
+		Dist *PackageDist `json:"dist,omitempty"`
+		// Deprecated is the deprecation message of the NPM package version, empty when it is not deprecated.
+		Deprecated Deprecation `json:"deprecated,omitempty"`
+	}
+
+	// PackageDist contains the distribution info of an NPM package version.
+	PackageDist struct {
+		// Tarball is the URL of the package tarball.
+		Tarball string `json:"tarball,omitempty"`
+		// Integrity is the Subresource Integrity of the tarball, like "sha512-...".
+		Integrity string `json:"integrity,omitempty"`
+		// Shasum is the hex-encoded SHA-1 checksum of the tarball.
+		Shasum string `json:"shasum,omitempty"`
 	}
 
+	// License is the license of an NPM package, usually an SPDX license expression.
+	License string
+
+	// Deprecation is the deprecation message of an NPM package version.
+	Deprecation string
+
+	// PublishTimes maps the versions of an NPM package to their publish time.
+	PublishTimes map[string]time.Time
+
 	// PackageMeta contains the metadata of an NPM package.
 	PackageMeta struct {
 		// Name is the name of the NPM package.
 		Name string `json:"name,omitempty"`
+		// DistTags maps the distribution tags, like "latest", to the version they point to.
+		DistTags map[string]string `json:"dist-tags,omitempty"` //nolint:tagliatelle // NPM registry field name.
 		// Versions contains all the versions of the given NPM package.
 		Versions map[string]Package `json:"versions,omitempty"`
+		// Time contains the publish time of the versions, as well as the "created" and "modified" times.
+		Time PublishTimes `json:"time,omitempty"`
 	}
 )
+
+// integrity returns the Subresource Integrity of the tarball, derived from
+// the SHA-1 checksum for packages published before integrity was recorded.
+func (d *PackageDist) integrity() string {
+	if d.Integrity != "" {
+		return d.Integrity
+	}
+	sum, err := hex.DecodeString(d.Shasum)
+	if err != nil || len(sum) == 0 {
+		return ""
+	}
+	return "sha1-" + base64.StdEncoding.EncodeToString(sum)
+}
+
+// UnmarshalJSON decodes a license declared as a string, or as a legacy {"type": "MIT"} object.
+// Other declarations are ignored, as they must not prevent the package from being decoded.
+func (l *License) UnmarshalJSON(data []byte) error {
+	var license string
+	if err := json.Unmarshal(data, &license); err == nil {
+		*l = License(strings.TrimSpace(license))
+		return nil
+	}
+
+	var legacy struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &legacy); err == nil {
+		*l = License(strings.TrimSpace(legacy.Type))
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes a deprecation message. Other values, like the false value of some
+// legacy packages, are ignored, as they must not prevent the package from being decoded.
+func (d *Deprecation) UnmarshalJSON(data []byte) error {
+	var msg string
+	if err := json.Unmarshal(data, &msg); err == nil {
+		*d = Deprecation(msg)
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the publish times of the versions. The entries that are not a timestamp,
+// like the "unpublished" object of unpublished packages, are ignored, as well as malformed times.
+func (t *PublishTimes) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if json.Unmarshal(data, &raw) != nil {
+		return nil
+	}
+
+	*t = PublishTimes{}
+	for version, value := range raw {
+		var published time.Time
+		if err := json.Unmarshal(value, &published); err == nil {
+			(*t)[version] = published
+		}
+	}
+	return nil
+}
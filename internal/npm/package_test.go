@@ -0,0 +1,59 @@
+package npm_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestLicense_UnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		name            string
+		json            string
+		expectedLicense npm.License
+	}{
+		{name: "SPDX expression", json: `{"license":"(MIT OR Apache-2.0)"}`, expectedLicense: "(MIT OR Apache-2.0)"},
+		{name: "legacy object", json: `{"license":{"type":"BSD","url":"https://example.com/LICENSE"}}`, expectedLicense: "BSD"},
+		{name: "unknown declaration", json: `{"license":["MIT"]}`, expectedLicense: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var pkg npm.Package
+			require.NoError(t, json.Unmarshal([]byte(tc.json), &pkg))
+
+			assert.Equal(t, tc.expectedLicense, pkg.License)
+		})
+	}
+}
+
+func TestPackageMeta_UnmarshalJSON(t *testing.T) {
+	data := `{
+		"name": "foo",
+		"versions": {
+			"1.0.0": {"name": "foo", "version": "1.0.0", "deprecated": "use bar"},
+			"1.1.0": {"name": "foo", "version": "1.1.0", "deprecated": false}
+		},
+		"time": {
+			"created": "2020-01-01T00:00:00.000Z",
+			"1.0.0": "2020-01-01T00:00:00.000Z",
+			"1.1.0": "not a time",
+			"unpublished": {"time": "2021-01-01T00:00:00.000Z"}
+		}
+	}`
+
+	var meta npm.PackageMeta
+	require.NoError(t, json.Unmarshal([]byte(data), &meta))
+
+	assert.Equal(t, npm.Deprecation("use bar"), meta.Versions["1.0.0"].Deprecated)
+	assert.Empty(t, meta.Versions["1.1.0"].Deprecated)
+	assert.Equal(t, npm.PublishTimes{
+		"created": time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		"1.0.0":   time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, meta.Time)
+}
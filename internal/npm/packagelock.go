@@ -0,0 +1,265 @@
+package npm
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+)
+
+type (
+	// packageLock is the content of a package-lock.json or npm-shrinkwrap.json file.
+	packageLock struct {
+		Name            string `json:"name"`
+		Version         string `json:"version"`
+		LockfileVersion int    `json:"lockfileVersion"`
+		// Packages maps the install location of every package to its entry,
+		// from lockfile version 2 onwards. The root project location is "".
+		Packages map[string]packageLockEntry `json:"packages"`
+		// Dependencies is the nested dependency tree of lockfile version 1.
+		Dependencies map[string]packageLockDependency `json:"dependencies"`
+	}
+
+	// packageLockEntry is an entry of the packages section of a lockfile.
+	packageLockEntry struct {
+		Name                 string            `json:"name"`
+		Version              string            `json:"version"`
+		Resolved             string            `json:"resolved"`
+		Integrity            string            `json:"integrity"`
+		Link                 bool              `json:"link"`
+		Dependencies         map[string]string `json:"dependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+	}
+
+	// declaredDependencies are dependencies of a single type declared by a package,
+	// mapping the package names to their version constraint. Missing optional
+	// dependencies, like platform specific ones, do not make the lockfile invalid.
+	declaredDependencies struct {
+		deps     map[string]string
+		required bool
+	}
+
+	// packageLockDependency is an entry of the dependencies tree of a version 1 lockfile.
+	packageLockDependency struct {
+		Version      string                           `json:"version"`
+		Resolved     string                           `json:"resolved"`
+		Integrity    string                           `json:"integrity"`
+		Requires     map[string]string                `json:"requires"`
+		Dependencies map[string]packageLockDependency `json:"dependencies"`
+	}
+)
+
+// ParsePackageLock reads a package-lock.json file, of lockfile version 1, 2 or 3, into a [Graph].
+func ParsePackageLock(r io.Reader) (*Graph, error) {
+	var lock packageLock
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, fmt.Errorf("%w: decoding package-lock.json: %w", ErrInvalidLockfile, err)
+	}
+
+	switch lock.LockfileVersion {
+	case 1:
+		return lock.graphFromDependencies()
+	case 2, 3:
+		return lock.graphFromPackages()
+	case 0:
+		return nil, fmt.Errorf("%w: missing lockfileVersion in package-lock.json", ErrInvalidLockfile)
+	default:
+		return nil, fmt.Errorf("%w: package-lock.json lockfileVersion %d", ErrUnsupportedLockfile, lock.LockfileVersion)
+	}
+}
+
+// graphFromPackages builds the graph from the packages section of lockfile versions 2 and 3,
+// resolving every dependency like the Node.js module resolution algorithm does.
+func (lock *packageLock) graphFromPackages() (*Graph, error) {
+	if lock.Packages == nil {
+		return nil, fmt.Errorf("%w: missing packages in package-lock.json", ErrInvalidLockfile)
+	}
+
+	rootEntry := lock.Packages[""]
+	lg := newLockfileGraph(cmp.Or(rootEntry.Name, lock.Name), cmp.Or(rootEntry.Version, lock.Version))
+
+	locations := slices.Sorted(maps.Keys(lock.Packages))
+	nodes := map[string]*Node{"": lg.graph.RootNode()}
+
+	for _, location := range locations {
+		entry := lock.Packages[location]
+		if location == "" || entry.Link {
+			continue
+		}
+		nodes[location] = lg.node(cmp.Or(entry.Name, packageNameFromLocation(location)), entry.Version, entry.Resolved, entry.Integrity)
+	}
+
+	var workspaces []*Node
+	for _, location := range locations {
+		entry := lock.Packages[location]
+		if !entry.Link {
+			continue
+		}
+		target, ok := nodes[entry.Resolved]
+		if !ok {
+			return nil, fmt.Errorf("%w: link %q targets missing location %q", ErrInvalidLockfile, location, entry.Resolved)
+		}
+		nodes[location] = target
+
+		// Workspaces are only linked from the root node_modules folder.
+		if !strings.Contains(entry.Resolved, "node_modules/") && strings.Count(location, "node_modules/") == 1 {
+			workspaces = append(workspaces, target)
+		}
+	}
+
+	for _, location := range locations {
+		entry := lock.Packages[location]
+		if entry.Link {
+			continue
+		}
+
+		deps := []declaredDependencies{
+			{deps: entry.Dependencies, required: true},
+			{deps: entry.OptionalDependencies},
+			{deps: entry.PeerDependencies},
+		}
+		// Development dependencies are only installed for the project and its workspaces.
+		if !strings.Contains(location, "node_modules/") {
+			deps = append(deps, declaredDependencies{deps: entry.DevDependencies, required: true})
+		}
+
+		for _, d := range deps {
+			for _, depName := range slices.Sorted(maps.Keys(d.deps)) {
+				depLocation, ok := lock.resolveLocation(location, depName)
+				if !ok {
+					if d.required {
+						return nil, fmt.Errorf("%w: dependency %s of %q is not locked", ErrInvalidLockfile, depName, location)
+					}
+					continue
+				}
+				lg.link(nodes[location], depName, d.deps[depName], nodes[depLocation])
+			}
+		}
+	}
+
+	// The root depends on its workspaces, with their declared constraint when they are also root dependencies.
+	root := lg.graph.RootNode()
+	for _, workspace := range workspaces {
+		if _, declared := root.Dependencies[workspace.Name]; !declared {
+			lg.link(root, workspace.Name, "*", workspace)
+		}
+	}
+
+	return lg.graph, nil
+}
+
+// resolveLocation finds the install location of a dependency required from the provided location,
+// walking up the node_modules folders.
+func (lock *packageLock) resolveLocation(from, name string) (string, bool) {
+	dir := from
+	for {
+		candidate := path.Join(dir, "node_modules", name)
+		if _, ok := lock.Packages[candidate]; ok {
+			return candidate, true
+		}
+		if dir == "" {
+			return "", false
+		}
+
+		if i := strings.LastIndex(dir, "node_modules/"); i >= 0 {
+			dir = strings.TrimSuffix(dir[:i], "/")
+		} else {
+			dir = ""
+		}
+	}
+}
+
+// graphFromDependencies builds the graph from the nested dependencies tree of lockfile version 1.
+// Version 1 lockfiles do not record the direct dependencies of the project, so the root node
+// depends on the top-level packages that no other package depends on.
+func (lock *packageLock) graphFromDependencies() (*Graph, error) {
+	lg := newLockfileGraph(lock.Name, lock.Version)
+
+	type requirer struct {
+		node     *Node
+		requires map[string]string
+		scopes   []map[string]packageLockDependency
+	}
+	var requirers []requirer
+
+	var collect func(deps map[string]packageLockDependency, scopes []map[string]packageLockDependency)
+	collect = func(deps map[string]packageLockDependency, scopes []map[string]packageLockDependency) {
+		scopes = append(slices.Clip(scopes), deps)
+		for _, name := range slices.Sorted(maps.Keys(deps)) {
+			dep := deps[name]
+			requirers = append(requirers, requirer{
+				node:     lg.nodeV1(name, dep),
+				requires: dep.Requires,
+				scopes:   append(slices.Clip(scopes), dep.Dependencies),
+			})
+			collect(dep.Dependencies, scopes)
+		}
+	}
+	collect(lock.Dependencies, nil)
+
+	dependedOn := map[string]bool{}
+	for _, r := range requirers {
+		for _, depName := range slices.Sorted(maps.Keys(r.requires)) {
+			dep, ok := lookupV1(r.scopes, depName)
+			if !ok {
+				return nil, fmt.Errorf("%w: dependency %s of %s is not locked", ErrInvalidLockfile, depName, r.node.ID())
+			}
+			child := lg.nodeV1(depName, dep)
+			lg.link(r.node, depName, r.requires[depName], child)
+			dependedOn[child.ID()] = true
+		}
+	}
+
+	root := lg.graph.RootNode()
+	topLevel := slices.Sorted(maps.Keys(lock.Dependencies))
+	for _, name := range topLevel {
+		if node := lg.nodeV1(name, lock.Dependencies[name]); !dependedOn[node.ID()] {
+			lg.link(root, name, node.Version, node)
+		}
+	}
+
+	// Top-level packages only depended on by each other, through a cycle, are still unreachable.
+	unreachable := lg.unreachable()
+	for _, name := range topLevel {
+		if node := lg.nodeV1(name, lock.Dependencies[name]); unreachable[node.ID()] {
+			lg.link(root, name, node.Version, node)
+			unreachable = lg.unreachable()
+		}
+	}
+
+	return lg.graph, nil
+}
+
+// nodeV1 returns the node of a version 1 lockfile dependency, following npm:name@version aliases.
+func (lg *lockfileGraph) nodeV1(name string, dep packageLockDependency) *Node {
+	version := dep.Version
+	if aliasName, aliasVersion, ok := parseAliasSpec(version); ok {
+		name, version = aliasName, aliasVersion
+	}
+	return lg.node(name, version, dep.Resolved, dep.Integrity)
+}
+
+// lookupV1 finds a dependency in the nested scopes of a version 1 lockfile, from the innermost one.
+func lookupV1(scopes []map[string]packageLockDependency, name string) (packageLockDependency, bool) {
+	for i := len(scopes) - 1; i >= 0; i-- {
+		if dep, ok := scopes[i][name]; ok {
+			return dep, true
+		}
+	}
+	return packageLockDependency{}, false
+}
+
+// packageNameFromLocation returns the package name of an install location,
+// e.g. "@scope/name" for "node_modules/foo/node_modules/@scope/name".
+func packageNameFromLocation(location string) string {
+	if i := strings.LastIndex(location, "node_modules/"); i >= 0 {
+		return location[i+len("node_modules/"):]
+	}
+	return path.Base(location)
+}
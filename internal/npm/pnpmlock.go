@@ -0,0 +1,229 @@
+package npm
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// pnpmRootImporter is the importer of the project located at the root of a pnpm workspace.
+const pnpmRootImporter = "."
+
+type (
+	// pnpmLock is the content of a pnpm-lock.yaml file.
+	pnpmLock struct {
+		LockfileVersion string `yaml:"lockfileVersion"`
+		// Importers maps the path of every workspace project to its direct dependencies.
+		Importers map[string]pnpmImporter `yaml:"importers"`
+		// pnpmImporter holds the direct dependencies of lockfiles of a single project, without importers.
+		pnpmImporter `yaml:",inline"`
+		// Packages maps the package keys to their metadata. Up to lockfile version 6,
+		// the keys carry the peer dependency suffixes and the entries the dependencies.
+		Packages map[string]pnpmPackage `yaml:"packages"`
+		// Snapshots maps the package keys, with peer dependency suffixes, to their
+		// dependencies from lockfile version 9 onwards.
+		Snapshots map[string]pnpmSnapshot `yaml:"snapshots"`
+	}
+
+	// pnpmImporter contains the direct dependencies of a workspace project.
+	pnpmImporter struct {
+		Dependencies         map[string]pnpmImporterDependency `yaml:"dependencies"`
+		DevDependencies      map[string]pnpmImporterDependency `yaml:"devDependencies"`
+		OptionalDependencies map[string]pnpmImporterDependency `yaml:"optionalDependencies"`
+	}
+
+	// pnpmImporterDependency is a direct dependency of a workspace project.
+	pnpmImporterDependency struct {
+		// Specifier is the version constraint declared in the project manifest.
+		Specifier string `yaml:"specifier"`
+		// Version is the reference of the locked package, like "18.2.0(react@18.2.0)" or "link:../ui".
+		Version string `yaml:"version"`
+	}
+
+	// pnpmDeclaredDependencies are direct dependencies of a single type of a workspace project,
+	// like [declaredDependencies].
+	pnpmDeclaredDependencies struct {
+		deps     map[string]pnpmImporterDependency
+		required bool
+	}
+
+	// pnpmPackage is the metadata of a locked package.
+	pnpmPackage struct {
+		Resolution struct {
+			Integrity string `yaml:"integrity"`
+			Tarball   string `yaml:"tarball"`
+		} `yaml:"resolution"`
+		PeerDependencies map[string]string `yaml:"peerDependencies"`
+		pnpmSnapshot     `yaml:",inline"`
+	}
+
+	// pnpmSnapshot contains the locked dependencies of a package, mapping their name to their reference.
+	pnpmSnapshot struct {
+		Dependencies         map[string]string `yaml:"dependencies"`
+		OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+	}
+)
+
+// ParsePnpmLock reads a pnpm-lock.yaml file, of lockfile version 6 or 9, into a [Graph].
+// Packages locked several times with different peer dependencies, like
+// "react-dom@18.2.0(react@18.2.0)", are deduplicated by name and version. Workspace projects
+// are named after their path and the root node is the project at the root of the workspace.
+func ParsePnpmLock(r io.Reader) (*Graph, error) {
+	var lock pnpmLock
+	if err := yaml.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, fmt.Errorf("%w: decoding pnpm-lock.yaml: %w", ErrInvalidLockfile, err)
+	}
+
+	switch major, _, _ := strings.Cut(lock.LockfileVersion, "."); major {
+	case "6", "9":
+	case "":
+		return nil, fmt.Errorf("%w: missing lockfileVersion in pnpm-lock.yaml", ErrInvalidLockfile)
+	default:
+		return nil, fmt.Errorf("%w: pnpm-lock.yaml lockfileVersion %s", ErrUnsupportedLockfile, lock.LockfileVersion)
+	}
+
+	if lock.Importers == nil {
+		lock.Importers = map[string]pnpmImporter{pnpmRootImporter: lock.pnpmImporter}
+	}
+
+	lg := newLockfileGraph("", "")
+	importers := map[string]*Node{pnpmRootImporter: lg.graph.RootNode()}
+	for _, importer := range slices.Sorted(maps.Keys(lock.Importers)) {
+		if importer != pnpmRootImporter {
+			importers[importer] = lg.node(importer, "", "", "")
+		}
+	}
+
+	snapshots, err := lock.snapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := map[string]*Node{}
+	for _, key := range slices.Sorted(maps.Keys(snapshots)) {
+		name, version, err := splitPnpmKey(key)
+		if err != nil {
+			return nil, err
+		}
+		meta := lock.packageMeta(key, name, version)
+		nodes[key] = lg.node(name, version, meta.Resolution.Tarball, meta.Resolution.Integrity)
+	}
+
+	for _, importer := range slices.Sorted(maps.Keys(lock.Importers)) {
+		deps := lock.Importers[importer]
+		for _, d := range []pnpmDeclaredDependencies{
+			{deps: deps.Dependencies, required: true},
+			{deps: deps.DevDependencies, required: true},
+			{deps: deps.OptionalDependencies},
+		} {
+			for _, depName := range slices.Sorted(maps.Keys(d.deps)) {
+				dep := d.deps[depName]
+
+				if target, ok := strings.CutPrefix(dep.Version, "link:"); ok {
+					node, ok := importers[path.Join(importer, target)]
+					if !ok {
+						return nil, fmt.Errorf("%w: dependency %s of importer %q links to unknown importer %q",
+							ErrInvalidLockfile, depName, importer, target)
+					}
+					lg.link(importers[importer], depName, dep.Specifier, node)
+					continue
+				}
+
+				node, ok := nodes[pnpmDependencyKey(depName, dep.Version)]
+				if !ok {
+					if d.required {
+						return nil, fmt.Errorf("%w: dependency %s@%s of importer %q is not locked", ErrInvalidLockfile, depName, dep.Version, importer)
+					}
+					continue
+				}
+				lg.link(importers[importer], depName, dep.Specifier, node)
+			}
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(snapshots)) {
+		name, version, _ := splitPnpmKey(key)
+		peers := lock.packageMeta(key, name, version).PeerDependencies
+
+		for _, d := range []declaredDependencies{
+			{deps: snapshots[key].Dependencies, required: true},
+			{deps: snapshots[key].OptionalDependencies},
+		} {
+			for _, depName := range slices.Sorted(maps.Keys(d.deps)) {
+				ref := d.deps[depName]
+				if strings.HasPrefix(ref, "link:") {
+					continue
+				}
+
+				node, ok := nodes[pnpmDependencyKey(depName, ref)]
+				if !ok {
+					if d.required {
+						return nil, fmt.Errorf("%w: dependency %s@%s of %s is not locked", ErrInvalidLockfile, depName, ref, key)
+					}
+					continue
+				}
+
+				// Locked dependencies only reference versions, except peer dependencies that declare a range.
+				constraint, ok := peers[depName]
+				if !ok {
+					constraint = node.Version
+				}
+				lg.link(nodes[key], depName, constraint, node)
+			}
+		}
+	}
+
+	return lg.graph, nil
+}
+
+// snapshots returns the dependencies of every locked package, keyed by their normalized package key.
+func (lock *pnpmLock) snapshots() (map[string]pnpmSnapshot, error) {
+	if strings.HasPrefix(lock.LockfileVersion, "9") {
+		return lock.Snapshots, nil
+	}
+
+	snapshots := make(map[string]pnpmSnapshot, len(lock.Packages))
+	for key, pkg := range lock.Packages {
+		normalized, ok := strings.CutPrefix(key, "/")
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported pnpm-lock.yaml package key %q", ErrInvalidLockfile, key)
+		}
+		snapshots[normalized] = pkg.pnpmSnapshot
+	}
+
+	return snapshots, nil
+}
+
+// packageMeta returns the metadata of the package of the normalized key.
+func (lock *pnpmLock) packageMeta(key, name, version string) pnpmPackage {
+	if strings.HasPrefix(lock.LockfileVersion, "9") {
+		return lock.Packages[name+"@"+version]
+	}
+	return lock.Packages["/"+key]
+}
+
+// pnpmDependencyKey returns the normalized package key of a locked dependency reference, which is
+// either a version with optional peer dependency suffixes, or the package key of an npm alias.
+func pnpmDependencyKey(name, ref string) string {
+	ref = strings.TrimPrefix(ref, "/")
+	if i := strings.Index(ref[min(1, len(ref)):], "@") + 1; i > 0 && !strings.Contains(ref[:i], "(") {
+		return ref
+	}
+	return name + "@" + ref
+}
+
+// splitPnpmKey splits a normalized package key, like "@scope/name@1.0.0(react@18.2.0)",
+// into its package name and version, without the peer dependency suffixes.
+func splitPnpmKey(key string) (name, version string, err error) {
+	withoutPeers, _, _ := strings.Cut(key, "(")
+	at := strings.LastIndex(withoutPeers, "@")
+	if at <= 0 {
+		return "", "", fmt.Errorf("%w: invalid pnpm-lock.yaml package key %q", ErrInvalidLockfile, key)
+	}
+	return withoutPeers[:at], withoutPeers[at+1:], nil
+}
@@ -0,0 +1,132 @@
+package npm_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestParsePnpmLock(t *testing.T) {
+	reactDOM := &npm.Node{
+		Name: "react-dom", Version: "18.2.0", Integrity: "sha512-react-dom",
+		Dependencies: map[string]npm.Edge{
+			"react":     {Constraint: "^18.2.0", Node: "react@18.2.0"},
+			"scheduler": {Constraint: "0.23.0", Node: "scheduler@0.23.0"},
+		},
+	}
+	react := &npm.Node{Name: "react", Version: "18.2.0", Integrity: "sha512-react"}
+	scheduler := &npm.Node{Name: "scheduler", Version: "0.23.0", Integrity: "sha512-scheduler"}
+
+	testCases := []struct {
+		name          string
+		file          string
+		expectedGraph *npm.Graph
+	}{
+		{
+			name: "lockfile version 9 with workspace, alias and peer dependency suffix",
+			file: "testdata/pnpm-lock_v9.yaml",
+			expectedGraph: &npm.Graph{
+				Root: "root@",
+				Nodes: map[string]*npm.Node{
+					"root@": {
+						Name: "root",
+						Dependencies: map[string]npm.Edge{
+							"react-dom":       {Constraint: "^18.2.0", Node: "react-dom@18.2.0"},
+							"scheduler-alias": {Constraint: "npm:scheduler@^0.23.0", Node: "scheduler@0.23.0"},
+							"ui":              {Constraint: "workspace:*", Node: "packages/ui@"},
+						},
+					},
+					"packages/ui@": {
+						Name:         "packages/ui",
+						Dependencies: map[string]npm.Edge{"react": {Constraint: "^18.0.0", Node: "react@18.2.0"}},
+					},
+					"react-dom@18.2.0": reactDOM,
+					"react@18.2.0":     react,
+					"scheduler@0.23.0": scheduler,
+				},
+			},
+		},
+		{
+			name: "lockfile version 6 of a single project",
+			file: "testdata/pnpm-lock_v6.yaml",
+			expectedGraph: &npm.Graph{
+				Root: "root@",
+				Nodes: map[string]*npm.Node{
+					"root@": {
+						Name: "root",
+						Dependencies: map[string]npm.Edge{
+							"react-dom":       {Constraint: "^18.2.0", Node: "react-dom@18.2.0"},
+							"scheduler-alias": {Constraint: "npm:scheduler@^0.23.0", Node: "scheduler@0.23.0"},
+						},
+					},
+					"react-dom@18.2.0": reactDOM,
+					"react@18.2.0":     react,
+					"scheduler@0.23.0": scheduler,
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := os.ReadFile(tc.file)
+			require.NoError(t, err)
+
+			graph, err := npm.ParsePnpmLock(strings.NewReader(string(content)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedGraph, graph)
+
+			graph, err = npm.ParseLockfile(strings.NewReader(string(content)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedGraph, graph)
+		})
+	}
+}
+
+func TestParsePnpmLock_Errors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:        "decoding error",
+			content:     "lockfileVersion: [",
+			expectedErr: "invalid lockfile: decoding pnpm-lock.yaml: yaml: line 1: did not find expected node content",
+		},
+		{
+			name:        "unsupported lockfile version",
+			content:     "lockfileVersion: 5.4\n",
+			expectedErr: "unsupported lockfile: pnpm-lock.yaml lockfileVersion 5.4",
+		},
+		{
+			name:        "dependency not locked",
+			content:     "lockfileVersion: '9.0'\nimporters:\n  .:\n    dependencies:\n      foo:\n        specifier: ^1.0.0\n        version: 1.0.0\n",
+			expectedErr: "invalid lockfile: dependency foo@1.0.0 of importer \".\" is not locked",
+		},
+		{
+			name:        "link to unknown importer",
+			content:     "lockfileVersion: '9.0'\nimporters:\n  .:\n    dependencies:\n      ui:\n        specifier: workspace:*\n        version: link:packages/ui\n",
+			expectedErr: "invalid lockfile: dependency ui of importer \".\" links to unknown importer \"packages/ui\"",
+		},
+		{
+			name:        "invalid package key",
+			content:     "lockfileVersion: '6.0'\npackages:\n  /foo:\n    resolution: {integrity: sha512-foo}\n",
+			expectedErr: "invalid lockfile: invalid pnpm-lock.yaml package key \"foo\"",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			graph, err := npm.ParsePnpmLock(strings.NewReader(tc.content))
+
+			assert.Nil(t, graph)
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
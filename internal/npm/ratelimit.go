@@ -0,0 +1,174 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// RateLimitConfig configures the token bucket limiting the rate of the requests of the NPM HTTP client
+	// to every registry host.
+	RateLimitConfig struct {
+		// RequestsPerSecond is the sustained rate of requests to a registry host.
+		// The requests are not limited when it is zero or less.
+		RequestsPerSecond float64 `json:"requestsPerSecond"`
+		// Burst is the number of requests that can be sent at once, at least 1.
+		Burst int `json:"burst"`
+	}
+
+	// RateLimitStats reports the activity of the rate limiter of a registry host.
+	RateLimitStats struct {
+		// Requests is the number of requests let through.
+		Requests int64 `json:"requests"`
+		// Queued is the number of requests that waited for their turn.
+		Queued int64 `json:"queued"`
+		// Waiting is the number of requests currently waiting for their turn.
+		Waiting int64 `json:"waiting"`
+		// Rejected is the number of requests whose context ended before their turn.
+		Rejected int64 `json:"rejected"`
+		// QueuedSeconds is the total time spent waiting by the requests, in seconds.
+		QueuedSeconds float64 `json:"queuedSeconds"`
+	}
+
+	// rateLimiter limits the rate of the requests to every registry host with a token bucket per host,
+	// configured by the configuration of the host, or else by the default one.
+	rateLimiter struct {
+		cfg     RateLimitConfig
+		mu      sync.Mutex
+		hosts   map[string]RateLimitConfig
+		buckets map[string]*tokenBucket
+	}
+
+	// tokenBucket is a token bucket refilled at a constant rate up to its burst, whose tokens can be
+	// reserved in advance, making the next requests wait longer.
+	tokenBucket struct {
+		rate  float64
+		burst float64
+
+		mu     sync.Mutex
+		tokens float64
+		last   time.Time
+
+		requests, queued, waiting, rejected atomic.Int64
+		queuedTime                          atomic.Int64
+	}
+)
+
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	return &rateLimiter{cfg: cfg, hosts: map[string]RateLimitConfig{}, buckets: map[string]*tokenBucket{}}
+}
+
+// configure sets the rate limit of the requests to the host, failing when the host already has another one.
+func (l *rateLimiter) configure(host string, cfg RateLimitConfig) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if hostCfg, ok := l.hosts[host]; ok && hostCfg != cfg {
+		return fmt.Errorf("rate limit of %s conflicts with the one of another registry of the host", host)
+	}
+	l.hosts[host] = cfg
+	return nil
+}
+
+// wait blocks until a request to the host can be sent, or returns an error when the context ends before,
+// or when its deadline is earlier than the turn of the request.
+func (l *rateLimiter) wait(ctx context.Context, host string) error {
+	b := l.bucket(host)
+	if b == nil {
+		return nil
+	}
+	delay := b.reserve(time.Now())
+	if delay <= 0 {
+		b.requests.Add(1)
+		return nil
+	}
+
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		b.cancel()
+		b.rejected.Add(1)
+		return fmt.Errorf("%w: rate limit of %s: turn in %s exceeds the deadline: %w",
+			ErrRateLimited, host, delay, context.DeadlineExceeded)
+	}
+
+	b.queued.Add(1)
+	b.waiting.Add(1)
+	start := time.Now()
+	err := sleep(ctx, delay)
+	b.queuedTime.Add(int64(time.Since(start)))
+	b.waiting.Add(-1)
+	if err != nil {
+		b.cancel()
+		b.rejected.Add(1)
+		return fmt.Errorf("rate limit of %s: %w", host, err)
+	}
+
+	b.requests.Add(1)
+	return nil
+}
+
+// stats returns the activity of the rate limiter of every registry host.
+func (l *rateLimiter) stats() map[string]RateLimitStats {
+	stats := map[string]RateLimitStats{}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for host, b := range l.buckets {
+		stats[host] = RateLimitStats{
+			Requests:      b.requests.Load(),
+			Queued:        b.queued.Load(),
+			Waiting:       b.waiting.Load(),
+			Rejected:      b.rejected.Load(),
+			QueuedSeconds: time.Duration(b.queuedTime.Load()).Seconds(),
+		}
+	}
+	return stats
+}
+
+// bucket returns the token bucket of the host, or nil when the requests to the host are not limited.
+func (l *rateLimiter) bucket(host string) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[host]
+	if !ok {
+		cfg, ok := l.hosts[host]
+		if !ok {
+			cfg = l.cfg
+		}
+		if cfg.RequestsPerSecond <= 0 {
+			return nil
+		}
+		burst := float64(max(cfg.Burst, 1))
+		b = &tokenBucket{rate: cfg.RequestsPerSecond, burst: burst, tokens: burst}
+		l.buckets[host] = b
+	}
+	return b
+}
+
+// reserve takes a token from the bucket, returning the delay to wait before it is available.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() {
+		b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens--
+
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a reserved token that is not used.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.burst, b.tokens+1)
+}
@@ -0,0 +1,82 @@
+package npm_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestClient_RateLimit(t *testing.T) {
+	transport := &faultTransport{faults: []fault{{statusCode: http.StatusOK, body: `{"name":"awesome"}`}}}
+	client, err := npm.NewClient(npm.ClientConfig{
+		RegistryURL: "http://localhost:8080",
+		Timeout:     15 * time.Second,
+		RateLimit:   npm.RateLimitConfig{RequestsPerSecond: 20, Burst: 2},
+	}, npm.ClientOptionHTTPTransport(transport))
+	require.NoError(t, err)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.FetchPackageMeta(context.Background(), "awesome")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// The burst lets 2 requests through at once, the next ones wait 50ms each for a token.
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	assert.Equal(t, int32(4), transport.attempts.Load())
+
+	stats := client.RateLimitStats()["localhost:8080"]
+	assert.Equal(t, int64(4), stats.Requests)
+	assert.Equal(t, int64(2), stats.Queued)
+	assert.Equal(t, int64(0), stats.Waiting)
+	assert.Equal(t, int64(0), stats.Rejected)
+	assert.Greater(t, stats.QueuedSeconds, 0.0)
+}
+
+func TestClient_RateLimit_Deadline(t *testing.T) {
+	transport := &faultTransport{faults: []fault{{statusCode: http.StatusOK, body: `{"name":"awesome"}`}}}
+	client, err := npm.NewClient(npm.ClientConfig{
+		RegistryURL: "http://localhost:8080",
+		Timeout:     15 * time.Second,
+		RateLimit:   npm.RateLimitConfig{RequestsPerSecond: 1, Burst: 1},
+	}, npm.ClientOptionHTTPTransport(transport))
+	require.NoError(t, err)
+
+	_, err = client.FetchPackageMeta(context.Background(), "awesome")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = client.FetchPackageMeta(ctx, "awesome")
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, npm.ErrRateLimited)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, int32(1), transport.attempts.Load())
+	assert.Equal(t, npm.RateLimitStats{Requests: 1, Rejected: 1}, client.RateLimitStats()["localhost:8080"])
+}
+
+func TestClient_RateLimit_Disabled(t *testing.T) {
+	client, err := npm.NewClient(npm.ClientConfig{RegistryURL: "http://localhost:8080"},
+		npm.ClientOptionHTTPTransport(&faultTransport{faults: []fault{{statusCode: http.StatusOK, body: `{}`}}}))
+	require.NoError(t, err)
+
+	_, err = client.FetchPackageMeta(context.Background(), "awesome")
+	require.NoError(t, err)
+
+	assert.Empty(t, client.RateLimitStats())
+}
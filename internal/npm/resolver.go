@@ -2,16 +2,23 @@ package npm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"maps"
+	"slices"
+	"sync"
 
 	"github.com/Masterminds/semver/v3"
+	"golang.org/x/sync/errgroup"
 
 	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
 )
 
 //go:generate go tool mockgen -destination=mocks/resolver.go -source=resolver.go -package mocksnpm
 
+// maxConcurrentFetches limits the number of in-flight registry requests of a graph resolution.
+const maxConcurrentFetches = 16
+
 type (
 	// PackageFetcher fetches data of NPM packages.
 	PackageFetcher interface {
@@ -48,7 +55,7 @@ func (r Resolver) ResolvePackage(ctx context.Context, name string, constraint *s
 	for depName, depConstraintStr := range pkg.Dependencies {
 		depConstraint, err := semver.NewConstraint(depConstraintStr)
 		if err != nil {
-			return nil, fmt.Errorf("invalid version constraint: %w", err)
+			return nil, &InvalidDependencySpecError{Dependent: NodeID(pkg.Name, pkg.Version), Name: depName, Spec: depConstraintStr, Err: err}
 		}
 
 		pkg.Dependencies[depName], err = r.resolvePackageHighestVersion(ctx, depName, depConstraint)
@@ -61,15 +68,241 @@ func (r Resolver) ResolvePackage(ctx context.Context, name string, constraint *s
 }
 
 func (r Resolver) resolvePackageHighestVersion(ctx context.Context, name string, constraint *semver.Constraints) (string, error) {
+	trace := contextResolveTrace(ctx)
+	trace.fetchStart(name)
 	meta, err := r.client.FetchPackageMeta(ctx, name)
+	trace.fetchDone(name, err)
 	if err != nil {
 		return "", fmt.Errorf("fetch package meta %s: %w", name, err)
 	}
 
-	version, err := semverutil.ResolveHighestVersion(constraint, maps.Keys(meta.Versions))
+	version, err := resolveHighestVersion(meta, name, constraint.String(), constraint)
 	if err != nil {
 		return "", fmt.Errorf("resolve highest version: %w", err)
 	}
 
 	return version, nil
 }
+
+// ResolvePackageGraph resolves the transitive dependencies of a given [Package] into a deduplicated [Graph],
+// based on its name and a version constraint.
+func (r Resolver) ResolvePackageGraph(ctx context.Context, name string, constraint *semver.Constraints) (*Graph, error) {
+	version, err := r.resolvePackageHighestVersion(ctx, name, constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := contextResolveTrace(ctx)
+	trace.fetchStart(name)
+	pkg, err := r.client.FetchPackage(ctx, name, version)
+	trace.fetchDone(name, err)
+	if err != nil {
+		return nil, fmt.Errorf("fetch package %s/%s: %w", name, version, err)
+	}
+
+	return r.ResolveGraph(ctx, pkg)
+}
+
+// ResolvePackageGraphs resolves the transitive dependencies of a given [Package] into a deduplicated [Graph]
+// for every version constraint, in the same order. The resolutions share the fetched package metadata,
+// so the packages common to several graphs are only fetched once.
+func (r Resolver) ResolvePackageGraphs(ctx context.Context, name string, constraints ...*semver.Constraints) ([]*Graph, error) {
+	grp, grpCtx := errgroup.WithContext(ctx)
+	cache := newMetaCache(r.client)
+	graphs := make([]*Graph, len(constraints))
+
+	for i, constraint := range constraints {
+		grp.Go(func() error {
+			var err error
+			graphs[i], err = resolveQuery(grpCtx, cache, PackageQuery{Name: name, Constraint: constraint})
+			return err
+		})
+	}
+
+	if err := grp.Wait(); err != nil {
+		return nil, err
+	}
+
+	return graphs, nil
+}
+
+// ResolveGraph resolves the transitive dependencies of the root package into a deduplicated [Graph].
+// The root package dependencies map package names to version constraints, like a package.json manifest.
+func (r Resolver) ResolveGraph(ctx context.Context, root *Package) (*Graph, error) {
+	return resolveGraph(ctx, newMetaCache(r.client), root)
+}
+
+func resolveGraph(ctx context.Context, cache *metaCache, root *Package) (*Graph, error) {
+	grp, grpCtx := errgroup.WithContext(ctx)
+
+	res := &graphResolution{
+		cache: cache,
+		trace: contextResolveTrace(ctx),
+		grp:   grp,
+		graph: NewGraph(newResolvedNode(root.Name, root.Version, root)),
+	}
+
+	res.trace.nodeResolved(NodeResolution{Name: root.Name, Version: root.Version})
+	res.resolveDependencies(grpCtx, res.graph.RootNode(), root.Dependencies)
+
+	if err := grp.Wait(); err != nil {
+		return nil, err
+	}
+
+	return res.graph, nil
+}
+
+type (
+	// graphResolution holds the state of a single [Resolver.ResolveGraph] call.
+	graphResolution struct {
+		cache *metaCache
+		trace *ResolveTrace
+		grp   *errgroup.Group
+
+		mu    sync.Mutex
+		graph *Graph
+	}
+
+	// metaCache memoizes the [PackageMeta] fetches of one or several graph resolutions,
+	// so every package is only fetched once, and limits the number of in-flight fetches.
+	metaCache struct {
+		client PackageFetcher
+		sem    chan struct{}
+
+		mu    sync.Mutex
+		metas map[string]*metaFetch
+	}
+
+	// metaFetch is the memoized [PackageMeta] fetch of a package.
+	metaFetch struct {
+		done chan struct{}
+		meta *PackageMeta
+		err  error
+		// cancelled reports whether the fetch failed because its context was done, in which case
+		// it is not memoized, so the resolutions with a live context fetch the package again.
+		cancelled bool
+	}
+)
+
+func (res *graphResolution) resolveDependencies(ctx context.Context, node *Node, deps map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(deps)) {
+		res.grp.Go(func() error {
+			return res.resolveDependency(ctx, node, name, deps[name])
+		})
+	}
+}
+
+func (res *graphResolution) resolveDependency(ctx context.Context, parent *Node, name, constraintStr string) error {
+	constraint, err := semver.NewConstraint(constraintStr)
+	if err != nil {
+		return &InvalidDependencySpecError{Dependent: parent.ID(), Name: name, Spec: constraintStr, Err: err}
+	}
+
+	meta, err := res.cache.fetch(ctx, name)
+	if err != nil {
+		return fmt.Errorf("fetch package meta %s: %w", name, err)
+	}
+
+	version, err := resolveHighestVersion(meta, name, constraintStr, constraint)
+	if err != nil {
+		return fmt.Errorf("resolve highest version of %s@%s: %w", name, constraintStr, err)
+	}
+	pkg := meta.Versions[version]
+
+	res.mu.Lock()
+	id := NodeID(name, version)
+	node, seen := res.graph.Nodes[id]
+	if !seen {
+		node = newResolvedNode(name, version, &pkg)
+		res.graph.Nodes[id] = node
+	}
+	if parent.Dependencies == nil {
+		parent.Dependencies = map[string]Edge{}
+	}
+	parent.Dependencies[name] = Edge{Constraint: constraintStr, Node: id}
+	res.mu.Unlock()
+
+	res.trace.nodeResolved(NodeResolution{Name: name, Version: version, Parent: parent.ID(), Constraint: constraintStr, Deduplicated: seen})
+
+	if !seen {
+		res.resolveDependencies(ctx, node, pkg.Dependencies)
+	}
+
+	return nil
+}
+
+// resolveHighestVersion resolves the highest version of the package satisfying the constraint, parsed
+// from the declared range. Its [semverutil.NoMatchingVersionError] reports the package and the range.
+func resolveHighestVersion(meta *PackageMeta, name, rng string, constraint *semver.Constraints) (string, error) {
+	version, err := semverutil.ResolveHighestVersion(constraint, maps.Keys(meta.Versions))
+	if noMatchingErr := (*semverutil.NoMatchingVersionError)(nil); errors.As(err, &noMatchingErr) {
+		noMatchingErr.Package, noMatchingErr.Range = name, rng
+	}
+	return version, err
+}
+
+// newResolvedNode creates the node of a resolved package version, with its distribution info.
+func newResolvedNode(name, version string, pkg *Package) *Node {
+	node := &Node{Name: name, Version: version, License: string(pkg.License)}
+	if pkg.Dist != nil {
+		node.Resolved, node.Integrity = pkg.Dist.Tarball, pkg.Dist.integrity()
+	}
+	return node
+}
+
+func newMetaCache(client PackageFetcher) *metaCache {
+	return &metaCache{
+		client: client,
+		sem:    make(chan struct{}, maxConcurrentFetches),
+		metas:  map[string]*metaFetch{},
+	}
+}
+
+func (c *metaCache) fetch(ctx context.Context, name string) (*PackageMeta, error) {
+	for {
+		c.mu.Lock()
+		f, ok := c.metas[name]
+		if !ok {
+			f = &metaFetch{done: make(chan struct{})}
+			c.metas[name] = f
+		}
+		c.mu.Unlock()
+
+		if !ok {
+			c.fetchMeta(ctx, name, f)
+		}
+
+		select {
+		case <-f.done:
+			if f.cancelled && ctx.Err() == nil {
+				continue
+			}
+			return f.meta, f.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (c *metaCache) fetchMeta(ctx context.Context, name string, f *metaFetch) {
+	trace := contextResolveTrace(ctx)
+	trace.fetchStart(name)
+	select {
+	case c.sem <- struct{}{}:
+		f.meta, f.err = c.client.FetchPackageMeta(ctx, name)
+		<-c.sem
+	case <-ctx.Done():
+		f.err = ctx.Err()
+	}
+	trace.fetchDone(name, f.err)
+
+	if f.err != nil && ctx.Err() != nil {
+		f.cancelled = true
+		c.mu.Lock()
+		if c.metas[name] == f {
+			delete(c.metas, name)
+		}
+		c.mu.Unlock()
+	}
+	close(f.done)
+}
@@ -3,6 +3,8 @@ package npm_test
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/Masterminds/semver/v3"
@@ -12,6 +14,7 @@ import (
 
 	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
 	mocksnpm "github.com/snyk/npmjs-deps-fetcher/internal/npm/mocks"
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
 )
 
 func TestResolver_ResolvePackage(t *testing.T) {
@@ -49,7 +52,7 @@ func TestResolver_ResolvePackage(t *testing.T) {
 				}, nil)
 				return fetcher
 			},
-			expectedErr: "resolve highest version: no compatible versions found",
+			expectedErr: "resolve highest version: no compatible versions found for ^1.0.5 among 2 versions",
 		},
 		{
 			name: "fetch package failure for highest root package version",
@@ -87,7 +90,7 @@ func TestResolver_ResolvePackage(t *testing.T) {
 				}, nil)
 				return fetcher
 			},
-			expectedErr: "invalid version constraint: improper constraint: latest",
+			expectedErr: "invalid version constraint \"latest\" of bar in foo@1.0.5: improper constraint: latest",
 		},
 		{
 			name: "fetch meta failure for dependency package",
@@ -172,3 +175,356 @@ func TestResolver_ResolvePackage(t *testing.T) {
 		})
 	}
 }
+
+func TestResolver_ResolveGraph(t *testing.T) {
+	root := &npm.Package{
+		Name:         "root",
+		Version:      "1.0.0",
+		Dependencies: map[string]string{"foo": "^1.0.0", "bar": "~2.0.0"},
+	}
+
+	testCases := []struct {
+		name          string
+		setup         func(testing.TB) npm.PackageFetcher
+		expectedGraph *npm.Graph
+		expectedErr   string
+	}{
+		{
+			name: "fetch meta failure for dependency",
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(nil, errors.New("something bad happened")).AnyTimes()
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(nil, errors.New("something bad happened")).AnyTimes()
+				return fetcher
+			},
+			expectedErr: "something bad happened",
+		},
+		{
+			name: "invalid transitive version constraint",
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+					Name: "foo",
+					Versions: map[string]npm.Package{
+						"1.0.0": {Name: "foo", Version: "1.0.0", Dependencies: map[string]string{"baz": "latest"}},
+					},
+				}, nil)
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(&npm.PackageMeta{
+					Name: "bar",
+					Versions: map[string]npm.Package{
+						"2.0.0": {Name: "bar", Version: "2.0.0"},
+					},
+				}, nil).AnyTimes()
+				return fetcher
+			},
+			expectedErr: "invalid version constraint \"latest\" of baz in foo@1.0.0: improper constraint: latest",
+		},
+		{
+			name: "successful deduplicated graph with cycle",
+			setup: func(tb testing.TB) npm.PackageFetcher {
+				tb.Helper()
+				fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+					Name: "foo",
+					Versions: map[string]npm.Package{
+						"1.0.0": {Name: "foo", Version: "1.0.0"},
+						"1.2.0": {Name: "foo", Version: "1.2.0", Dependencies: map[string]string{"baz": "^3.0.0"}},
+						"2.0.0": {Name: "foo", Version: "2.0.0"},
+					},
+				}, nil)
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(&npm.PackageMeta{
+					Name: "bar",
+					Versions: map[string]npm.Package{
+						"2.0.3": {Name: "bar", Version: "2.0.3", Dependencies: map[string]string{"baz": "3.x"}},
+						"2.1.0": {Name: "bar", Version: "2.1.0"},
+					},
+				}, nil)
+				fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "baz").Return(&npm.PackageMeta{
+					Name: "baz",
+					Versions: map[string]npm.Package{
+						"3.1.0": {Name: "baz", Version: "3.1.0", Dependencies: map[string]string{"foo": "1.2.0"}},
+					},
+				}, nil)
+				return fetcher
+			},
+			expectedGraph: &npm.Graph{
+				Root: "root@1.0.0",
+				Nodes: map[string]*npm.Node{
+					"root@1.0.0": {
+						Name:    "root",
+						Version: "1.0.0",
+						Dependencies: map[string]npm.Edge{
+							"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"},
+							"bar": {Constraint: "~2.0.0", Node: "bar@2.0.3"},
+						},
+					},
+					"foo@1.2.0": {
+						Name:         "foo",
+						Version:      "1.2.0",
+						Dependencies: map[string]npm.Edge{"baz": {Constraint: "^3.0.0", Node: "baz@3.1.0"}},
+					},
+					"bar@2.0.3": {
+						Name:         "bar",
+						Version:      "2.0.3",
+						Dependencies: map[string]npm.Edge{"baz": {Constraint: "3.x", Node: "baz@3.1.0"}},
+					},
+					"baz@3.1.0": {
+						Name:         "baz",
+						Version:      "3.1.0",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "1.2.0", Node: "foo@1.2.0"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := npm.NewResolver(tc.setup(t))
+
+			graph, err := resolver.ResolveGraph(context.Background(), root)
+
+			assert.Equal(t, tc.expectedGraph, graph)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestResolver_ResolveGraph_NoMatchingTransitiveVersion(t *testing.T) {
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+		Name: "foo",
+		Versions: map[string]npm.Package{
+			"1.0.0": {Name: "foo", Version: "1.0.0", Dependencies: map[string]string{"bar": "^9.0.0"}},
+		},
+	}, nil)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(&npm.PackageMeta{
+		Name:     "bar",
+		Versions: map[string]npm.Package{"2.0.0": {Name: "bar", Version: "2.0.0"}},
+	}, nil)
+
+	_, err := npm.NewResolver(fetcher).ResolveGraph(context.Background(), &npm.Package{
+		Name: "root", Version: "1.0.0", Dependencies: map[string]string{"foo": "^1.0.0"},
+	})
+
+	var noMatchingErr *semverutil.NoMatchingVersionError
+	require.ErrorAs(t, err, &noMatchingErr)
+	assert.Equal(t, "bar", noMatchingErr.Package)
+	assert.Equal(t, "^9.0.0", noMatchingErr.Range)
+}
+
+func TestResolver_ResolvePackageGraphs(t *testing.T) {
+	from, err := semver.NewConstraint("1.0.0")
+	require.NoError(t, err)
+	to, err := semver.NewConstraint("^2.0.0")
+	require.NoError(t, err)
+
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").Return(&npm.PackageMeta{
+		Name: "app",
+		Versions: map[string]npm.Package{
+			"1.0.0": {Name: "app", Version: "1.0.0", Dependencies: map[string]string{"foo": "^1.0.0"}},
+			"2.1.0": {Name: "app", Version: "2.1.0", Dependencies: map[string]string{"foo": "^1.2.0"}},
+		},
+	}, nil)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+		Name: "foo",
+		Versions: map[string]npm.Package{
+			"1.3.0": {
+				Name: "foo", Version: "1.3.0", License: "MIT",
+				Dist: &npm.PackageDist{
+					Tarball: "https://registry.npmjs.org/foo/-/foo-1.3.0.tgz",
+					Shasum:  "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+				},
+			},
+		},
+	}, nil)
+
+	graphs, err := npm.NewResolver(fetcher).ResolvePackageGraphs(context.Background(), "app", from, to)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*npm.Graph{
+		{
+			Root: "app@1.0.0",
+			Nodes: map[string]*npm.Node{
+				"app@1.0.0": {
+					Name: "app", Version: "1.0.0",
+					Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.3.0"}},
+				},
+				"foo@1.3.0": {
+					Name: "foo", Version: "1.3.0", License: "MIT",
+					Resolved:  "https://registry.npmjs.org/foo/-/foo-1.3.0.tgz",
+					Integrity: "sha1-2jmj7l5rSw0yVb/vlWAYkK/YBwk=",
+				},
+			},
+		},
+		{
+			Root: "app@2.1.0",
+			Nodes: map[string]*npm.Node{
+				"app@2.1.0": {
+					Name: "app", Version: "2.1.0",
+					Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.2.0", Node: "foo@1.3.0"}},
+				},
+				"foo@1.3.0": {
+					Name: "foo", Version: "1.3.0", License: "MIT",
+					Resolved:  "https://registry.npmjs.org/foo/-/foo-1.3.0.tgz",
+					Integrity: "sha1-2jmj7l5rSw0yVb/vlWAYkK/YBwk=",
+				},
+			},
+		},
+	}, graphs)
+}
+
+func TestResolver_ResolveBatch(t *testing.T) {
+	anyVersion, err := semver.NewConstraint("*")
+	require.NoError(t, err)
+
+	// The first fetch of foo is cancelled by the failure of bar in the app resolution,
+	// then foo is fetched again by the lib resolution, which is not cancelled.
+	fooStarted := make(chan struct{})
+	var fooCalls atomic.Int32
+
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "app").Return(&npm.PackageMeta{
+		Name: "app",
+		Versions: map[string]npm.Package{
+			"1.0.0": {Name: "app", Version: "1.0.0", Dependencies: map[string]string{"bar": "^1.0.0", "foo": "^1.0.0"}},
+		},
+	}, nil)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").DoAndReturn(func(context.Context, string) (*npm.PackageMeta, error) {
+		<-fooStarted
+		return nil, errors.New("something bad happened")
+	})
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").DoAndReturn(func(ctx context.Context, _ string) (*npm.PackageMeta, error) {
+		if fooCalls.Add(1) == 1 {
+			close(fooStarted)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return &npm.PackageMeta{
+			Name:     "foo",
+			Versions: map[string]npm.Package{"1.0.0": {Name: "foo", Version: "1.0.0"}},
+		}, nil
+	}).Times(2)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "lib").DoAndReturn(func(context.Context, string) (*npm.PackageMeta, error) {
+		<-fooStarted
+		return &npm.PackageMeta{
+			Name: "lib",
+			Versions: map[string]npm.Package{
+				"1.0.0": {Name: "lib", Version: "1.0.0", Dependencies: map[string]string{"foo": "1.x"}},
+			},
+		}, nil
+	})
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "missing").Return(nil, npm.ErrPackageNotFound)
+
+	results := npm.NewResolver(fetcher).ResolveBatch(context.Background(), []npm.PackageQuery{
+		{Name: "app", Constraint: anyVersion},
+		{Name: "lib", Constraint: anyVersion},
+		{Name: "missing", Constraint: anyVersion},
+	})
+	require.Len(t, results, 3)
+
+	assert.Nil(t, results[0].Graph)
+	require.EqualError(t, results[0].Err, "fetch package meta bar: something bad happened")
+
+	require.NoError(t, results[1].Err)
+	assert.Equal(t, &npm.Graph{
+		Root: "lib@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"lib@1.0.0": {
+				Name: "lib", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{"foo": {Constraint: "1.x", Node: "foo@1.0.0"}},
+			},
+			"foo@1.0.0": {Name: "foo", Version: "1.0.0"},
+		},
+	}, results[1].Graph)
+
+	assert.Nil(t, results[2].Graph)
+	assert.ErrorIs(t, results[2].Err, npm.ErrPackageNotFound)
+}
+
+func TestResolver_ResolveGraph_Trace(t *testing.T) {
+	fetcher := mocksnpm.NewMockPackageFetcher(gomock.NewController(t))
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "foo").Return(&npm.PackageMeta{
+		Name: "foo",
+		Versions: map[string]npm.Package{
+			"1.2.0": {Name: "foo", Version: "1.2.0", Dependencies: map[string]string{"bar": "^2.0.0"}},
+		},
+	}, nil)
+	fetcher.EXPECT().FetchPackageMeta(gomock.Any(), "bar").Return(&npm.PackageMeta{
+		Name: "bar",
+		Versions: map[string]npm.Package{
+			"2.0.0": {Name: "bar", Version: "2.0.0"},
+		},
+	}, nil)
+
+	var (
+		mu          sync.Mutex
+		resolutions []npm.NodeResolution
+		fetched     []string
+		pending     int
+	)
+	ctx := npm.WithResolveTrace(context.Background(), &npm.ResolveTrace{
+		FetchStart: func(string) {
+			mu.Lock()
+			defer mu.Unlock()
+			pending++
+		},
+		FetchDone: func(name string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			assert.NoError(t, err)
+			pending--
+			fetched = append(fetched, name)
+		},
+		NodeResolved: func(resolution npm.NodeResolution) {
+			mu.Lock()
+			defer mu.Unlock()
+			resolutions = append(resolutions, resolution)
+		},
+	})
+
+	_, err := npm.NewResolver(fetcher).ResolveGraph(ctx, &npm.Package{
+		Name:         "root",
+		Version:      "1.0.0",
+		Dependencies: map[string]string{"foo": "^1.0.0", "bar": "2.0.0"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, npm.NodeResolution{Name: "root", Version: "1.0.0"}, resolutions[0])
+	assert.ElementsMatch(t, []npm.NodeResolution{
+		{Name: "root", Version: "1.0.0"},
+		{Name: "foo", Version: "1.2.0", Parent: "root@1.0.0", Constraint: "^1.0.0"},
+		{Name: "bar", Version: "2.0.0", Parent: "root@1.0.0", Constraint: "2.0.0"},
+		{Name: "bar", Version: "2.0.0", Parent: "foo@1.2.0", Constraint: "^2.0.0"},
+	}, clearDeduplicated(resolutions))
+	assert.Equal(t, 1, countDeduplicated(resolutions))
+	assert.ElementsMatch(t, []string{"foo", "bar"}, fetched)
+	assert.Zero(t, pending)
+}
+
+// clearDeduplicated returns the resolutions without their deduplicated flag, which depends on the resolution order.
+func clearDeduplicated(resolutions []npm.NodeResolution) []npm.NodeResolution {
+	cleared := make([]npm.NodeResolution, 0, len(resolutions))
+	for _, resolution := range resolutions {
+		resolution.Deduplicated = false
+		cleared = append(cleared, resolution)
+	}
+	return cleared
+}
+
+func countDeduplicated(resolutions []npm.NodeResolution) int {
+	count := 0
+	for _, resolution := range resolutions {
+		if resolution.Deduplicated {
+			count++
+		}
+	}
+	return count
+}
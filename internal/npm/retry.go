@@ -0,0 +1,105 @@
+package npm
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryConfig configures the retries of the idempotent requests of the NPM HTTP client
+// failing with a transient error, like a connection reset or a 503 response.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts of a request, including the first one.
+	// The requests are not retried when it is 1 or less.
+	MaxAttempts int `json:"maxAttempts"`
+	// InitialBackoff is the base delay before the first retry, doubled for every following retry.
+	InitialBackoff time.Duration `json:"initialBackoff"`
+	// MaxBackoff caps the delay before a retry. A request whose Retry-After delay exceeds it
+	// is not retried.
+	MaxBackoff time.Duration `json:"maxBackoff"`
+	// MaxElapsed caps the duration of a request, including all its attempts and the delays between them,
+	// so the retries cannot outlast the caller. The duration is not capped when it is 0 or less.
+	MaxElapsed time.Duration `json:"maxElapsed"`
+}
+
+// retryDelay returns the delay before the next attempt of the request, after the failed attempt
+// with the error, or false when the request must not be retried. The requests are only retried
+// within the retry budget of the request: its maximum number of attempts and its context deadline,
+// bounded by the maximum elapsed duration of the request.
+func (cfg RetryConfig) retryDelay(req *http.Request, attempt int, err error) (time.Duration, bool) {
+	if attempt >= cfg.MaxAttempts || !isIdempotent(req.Method) || !isTransient(req.Context(), err) {
+		return 0, false
+	}
+
+	delay := cfg.backoff(attempt)
+	var regErr *RegistryError
+	if errors.As(err, &regErr) && regErr.RetryAfter > 0 &&
+		(regErr.StatusCode == http.StatusTooManyRequests || regErr.StatusCode == http.StatusServiceUnavailable) {
+		if regErr.RetryAfter > cfg.MaxBackoff {
+			return 0, false
+		}
+		delay = regErr.RetryAfter
+	}
+
+	if deadline, ok := req.Context().Deadline(); ok && time.Now().Add(delay).After(deadline) {
+		return 0, false
+	}
+	return delay, true
+}
+
+// backoff returns the exponential backoff delay before the retry following the attempt,
+// with a random jitter of up to half of the delay.
+func (cfg RetryConfig) backoff(attempt int) time.Duration {
+	delay := cfg.MaxBackoff
+	if shift := attempt - 1; shift < 32 && cfg.InitialBackoff<<shift < cfg.MaxBackoff {
+		delay = cfg.InitialBackoff << shift
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isIdempotent reports whether requests of the HTTP method can be safely retried.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// isTransient reports whether a request failing with the error may succeed when retried:
+// the registry could not be reached, or responded with a rate limiting or server error.
+func isTransient(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var regErr *RegistryError
+	if errors.As(err, &regErr) {
+		switch regErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusInternalServerError,
+			http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	return errors.Is(err, ErrRegistryUnavailable) || errors.Is(err, ErrRegistryTimeout)
+}
+
+// sleep waits for the delay, returning the context error if it is done before.
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
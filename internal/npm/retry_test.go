@@ -0,0 +1,181 @@
+package npm_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// fault is the outcome of an attempt of a request through a faultTransport.
+type fault struct {
+	statusCode int
+	header     http.Header
+	body       string
+	err        error
+}
+
+// faultTransport is an [http.RoundTripper] injecting a fault for every attempt of a request, in order,
+// the last fault being repeated for the following attempts.
+type faultTransport struct {
+	faults   []fault
+	attempts atomic.Int32
+}
+
+func (rt *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempt := int(rt.attempts.Add(1))
+	f := rt.faults[min(attempt, len(rt.faults))-1]
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &http.Response{
+		StatusCode: f.statusCode,
+		Header:     f.header,
+		Body:       io.NopCloser(strings.NewReader(f.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_Retry(t *testing.T) {
+	retry := npm.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	ok := fault{statusCode: http.StatusOK, body: `{"name":"awesome"}`}
+
+	testCases := []struct {
+		name             string
+		retry            npm.RetryConfig
+		faults           []fault
+		timeout          time.Duration
+		expectedAttempts int32
+		expectedErr      string
+		minDuration      time.Duration
+	}{
+		{
+			name:             "transient errors retried",
+			retry:            retry,
+			faults:           []fault{{err: syscall.ECONNRESET}, {statusCode: http.StatusServiceUnavailable}, ok},
+			expectedAttempts: 3,
+		},
+		{
+			name:             "retry attempts exhausted",
+			retry:            retry,
+			faults:           []fault{{statusCode: http.StatusBadGateway, body: `"bad gateway"`}},
+			expectedAttempts: 3,
+			expectedErr:      "registry response 502 for \"http://localhost:8080/awesome\": bad gateway",
+		},
+		{
+			name:             "retries disabled",
+			faults:           []fault{{statusCode: http.StatusServiceUnavailable}, ok},
+			expectedAttempts: 1,
+			expectedErr:      "registry response 503 for \"http://localhost:8080/awesome\": Service Unavailable",
+		},
+		{
+			name:             "client error not retried",
+			retry:            retry,
+			faults:           []fault{{statusCode: http.StatusForbidden}, ok},
+			expectedAttempts: 1,
+			expectedErr:      "registry response 403 for \"http://localhost:8080/awesome\": Forbidden",
+		},
+		{
+			name:             "package not found not retried",
+			retry:            retry,
+			faults:           []fault{{statusCode: http.StatusNotFound}, ok},
+			expectedAttempts: 1,
+			expectedErr:      "package awesome not found",
+		},
+		{
+			name:  "retry after honoured",
+			retry: npm.RetryConfig{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Second},
+			faults: []fault{
+				{statusCode: http.StatusTooManyRequests, header: http.Header{"Retry-After": {"1"}}},
+				ok,
+			},
+			expectedAttempts: 2,
+			minDuration:      time.Second,
+		},
+		{
+			name:  "retry after exceeding the max backoff not retried",
+			retry: retry,
+			faults: []fault{
+				{statusCode: http.StatusServiceUnavailable, header: http.Header{"Retry-After": {"60"}}},
+				ok,
+			},
+			expectedAttempts: 1,
+			expectedErr:      "registry response 503 for \"http://localhost:8080/awesome\": Service Unavailable",
+		},
+		{
+			name:             "retry beyond the context deadline not retried",
+			retry:            npm.RetryConfig{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Minute},
+			faults:           []fault{{err: syscall.ECONNREFUSED}, ok},
+			timeout:          time.Second,
+			expectedAttempts: 1,
+			expectedErr:      "registry unavailable: http request roundtrip for: Get \"http://localhost:8080/awesome\": connection refused",
+		},
+		{
+			name: "retry beyond the max elapsed duration not retried",
+			retry: npm.RetryConfig{
+				MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Minute, MaxElapsed: time.Second,
+			},
+			faults:           []fault{{err: syscall.ECONNREFUSED}, ok},
+			expectedAttempts: 1,
+			expectedErr:      "registry unavailable: http request roundtrip for: Get \"http://localhost:8080/awesome\": connection refused",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &faultTransport{faults: tc.faults}
+			client, err := npm.NewClient(npm.ClientConfig{
+				RegistryURL: "http://localhost:8080",
+				Timeout:     15 * time.Second,
+				Retry:       tc.retry,
+			}, npm.ClientOptionHTTPTransport(transport))
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			meta, err := client.FetchPackageMeta(ctx, "awesome")
+
+			assert.Equal(t, tc.expectedAttempts, transport.attempts.Load())
+			assert.GreaterOrEqual(t, time.Since(start), tc.minDuration)
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+				assert.Equal(t, "awesome", meta.Name)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestClient_Retry_ContextCancelled(t *testing.T) {
+	transport := &faultTransport{faults: []fault{{statusCode: http.StatusServiceUnavailable}}}
+	client, err := npm.NewClient(npm.ClientConfig{
+		RegistryURL: "http://localhost:8080",
+		Timeout:     15 * time.Second,
+		Retry:       npm.RetryConfig{MaxAttempts: 5, InitialBackoff: time.Minute, MaxBackoff: time.Minute},
+	}, npm.ClientOptionHTTPTransport(transport))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err = client.FetchPackageMeta(ctx, "awesome")
+
+	assert.ErrorIs(t, err, npm.ErrRegistryUnavailable)
+	assert.Equal(t, int32(1), transport.attempts.Load())
+}
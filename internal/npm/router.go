@@ -0,0 +1,153 @@
+package npm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var _ PackageFetcher = (*Router)(nil)
+
+// Router is a [PackageFetcher] routing the requests of the scoped packages, like @acme/foo,
+// to the registry of their scope, like the @scope:registry settings of .npmrc, and the requests
+// of the other packages to the default registry. The requests to every registry go through their own
+// [CircuitBreaker], so a failing scope registry does not fail the requests to the other registries,
+// and the requests of all the registries share the rate limit of their host.
+type Router struct {
+	fallback *registry
+	scopes   map[string]*registry
+	limiter  *rateLimiter
+}
+
+// registry is the client of an NPM registry, with the circuit breaker around its requests.
+type registry struct {
+	client  *Client
+	breaker *CircuitBreaker
+}
+
+// NewRouter creates an HTTP client for the default NPM registry provided in the configuration,
+// and for the registry of every scope of [ClientConfig.Scopes], each with a circuit breaker configured
+// by breakerCfg. The options apply to every client. The registries of the same host must have the same
+// rate limit configuration.
+func NewRouter(cfg ClientConfig, breakerCfg CircuitBreakerConfig, opts ...ClientOption) (*Router, error) {
+	limiter := newRateLimiter(cfg.RateLimit)
+	opts = append(slices.Clip(opts), clientOptionRateLimiter(limiter))
+
+	fallback, err := NewClient(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := limiter.configure(registryHost(cfg.RegistryURL), cfg.RateLimit); err != nil {
+		return nil, fmt.Errorf("rate limit configuration: %w", err)
+	}
+
+	r := &Router{
+		fallback: &registry{client: fallback, breaker: NewCircuitBreaker(fallback, breakerCfg)},
+		scopes:   map[string]*registry{},
+		limiter:  limiter,
+	}
+	for _, scope := range slices.Sorted(maps.Keys(cfg.Scopes)) {
+		scopeCfg := cfg.Scopes[scope]
+		if scopeCfg.RegistryURL == "" {
+			return nil, fmt.Errorf("scope %s registry URL configuration: missing registry URL", scope)
+		}
+
+		scopeCfg = scopeCfg.inherit(cfg)
+		client, err := NewClient(scopeCfg, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("scope %s %w", scope, err)
+		}
+		if err := limiter.configure(registryHost(scopeCfg.RegistryURL), scopeCfg.RateLimit); err != nil {
+			return nil, fmt.Errorf("scope %s rate limit configuration: %w", scope, err)
+		}
+
+		r.scopes[normalizeScope(scope)] = &registry{client: client, breaker: NewCircuitBreaker(client, breakerCfg)}
+	}
+
+	return r, nil
+}
+
+// FetchPackage fetches the [Package] information of an NPM package at a given version from the registry of its scope.
+func (r *Router) FetchPackage(ctx context.Context, name, version string) (*Package, error) {
+	return r.registry(name).breaker.FetchPackage(ctx, name, version)
+}
+
+// FetchPackageMeta fetches the [PackageMeta] metadata of an NPM package from the registry of its scope.
+func (r *Router) FetchPackageMeta(ctx context.Context, name string) (*PackageMeta, error) {
+	return r.registry(name).breaker.FetchPackageMeta(ctx, name)
+}
+
+// Status returns the state of the circuits of all the registries: the worst state among them, open
+// being worse than half-open, with the requests and failures of all their windows. The opening time
+// is the earliest one of the circuits in the worst state.
+func (r *Router) Status() CircuitBreakerStatus {
+	status := CircuitBreakerStatus{State: CircuitClosed}
+	for _, reg := range r.registries() {
+		s := reg.breaker.Status()
+		status.Requests += s.Requests
+		status.Failures += s.Failures
+
+		switch severity := circuitSeverity(s.State); {
+		case severity > circuitSeverity(status.State):
+			status.State, status.OpenedAt = s.State, s.OpenedAt
+		case severity == circuitSeverity(status.State) && s.OpenedAt != nil && s.OpenedAt.Before(*status.OpenedAt):
+			status.OpenedAt = s.OpenedAt
+		}
+	}
+	return status
+}
+
+// RateLimitStats returns the activity of the rate limiter of every registry host the clients sent requests to.
+func (r *Router) RateLimitStats() map[string]RateLimitStats {
+	return r.limiter.stats()
+}
+
+// registry returns the registry of the package scope, or the default registry.
+func (r *Router) registry(name string) *registry {
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		if reg, ok := r.scopes[scope]; ok {
+			return reg
+		}
+	}
+	return r.fallback
+}
+
+// registries returns the default registry followed by the registries of the scopes.
+func (r *Router) registries() []*registry {
+	return append([]*registry{r.fallback}, slices.Collect(maps.Values(r.scopes))...)
+}
+
+// registryHost returns the host of the registry URL, already validated by [NewClient].
+func registryHost(registryURL string) string {
+	u, _ := url.Parse(registryURL)
+	return u.Host
+}
+
+// circuitSeverity ranks the states of a circuit, from the closed one to the open one.
+func circuitSeverity(state string) int {
+	return slices.Index([]string{CircuitClosed, CircuitHalfOpen, CircuitOpen}, state)
+}
+
+// inherit returns the configuration of a scope registry, whose unset settings default to the ones of
+// the default registry configuration.
+func (cfg ClientConfig) inherit(defaults ClientConfig) ClientConfig {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.Retry == (RetryConfig{}) {
+		cfg.Retry = defaults.Retry
+	}
+	if cfg.RateLimit == (RateLimitConfig{}) {
+		cfg.RateLimit = defaults.RateLimit
+	}
+	cfg.Scopes = nil
+	return cfg
+}
+
+// normalizeScope returns the scope prefixed with @, as in the name of its packages.
+func normalizeScope(scope string) string {
+	return "@" + strings.TrimPrefix(scope, "@")
+}
@@ -0,0 +1,272 @@
+package npm_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+var routerConfig = npm.ClientConfig{
+	RegistryURL: "https://registry.npmjs.org",
+	Timeout:     15 * time.Second,
+	RateLimit:   npm.RateLimitConfig{RequestsPerSecond: 100, Burst: 100},
+	Scopes: map[string]npm.ClientConfig{
+		"@acme": {RegistryURL: "https://npm.acme.internal", Token: "acme-token"},
+		"corp":  {RegistryURL: "https://npm.corp.internal/registry"},
+	},
+}
+
+// recordTransport is an [http.RoundTripper] recording the URL and the Authorization header of every request,
+// responding with an empty JSON object.
+type recordTransport struct {
+	mu    sync.Mutex
+	urls  []string
+	auths []string
+}
+
+func (rt *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.urls = append(rt.urls, req.URL.String())
+	rt.auths = append(rt.auths, req.Header.Get("Authorization"))
+	rt.mu.Unlock()
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestNewRouter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		cfg         npm.ClientConfig
+		expectedErr string
+	}{
+		{
+			name: "invalid registry url configuration",
+			cfg: npm.ClientConfig{
+				RegistryURL: "\x7f",
+			},
+			expectedErr: "registry URL configuration: parse \"\\x7f\": net/url: invalid control character in URL",
+		},
+		{
+			name: "invalid scope registry url configuration",
+			cfg: npm.ClientConfig{
+				RegistryURL: "https://registry.npmjs.org",
+				Scopes: map[string]npm.ClientConfig{
+					"@acme": {RegistryURL: "\x7f"},
+				},
+			},
+			expectedErr: "scope @acme registry URL configuration: parse \"\\x7f\": net/url: invalid control character in URL",
+		},
+		{
+			name: "missing scope registry url configuration",
+			cfg: npm.ClientConfig{
+				RegistryURL: "https://registry.npmjs.org",
+				Scopes: map[string]npm.ClientConfig{
+					"@acme": {Timeout: time.Second},
+				},
+			},
+			expectedErr: "scope @acme registry URL configuration: missing registry URL",
+		},
+		{
+			name: "conflicting scope rate limit configuration",
+			cfg: npm.ClientConfig{
+				RegistryURL: "https://registry.npmjs.org",
+				RateLimit:   npm.RateLimitConfig{RequestsPerSecond: 100, Burst: 100},
+				Scopes: map[string]npm.ClientConfig{
+					"@acme": {
+						RegistryURL: "https://registry.npmjs.org/acme",
+						RateLimit:   npm.RateLimitConfig{RequestsPerSecond: 10, Burst: 10},
+					},
+				},
+			},
+			expectedErr: "scope @acme rate limit configuration: rate limit of registry.npmjs.org conflicts with the one of another registry of the host",
+		},
+		{
+			name: "valid configuration",
+			cfg:  routerConfig,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := npm.NewRouter(tc.cfg, npm.CircuitBreakerConfig{})
+
+			if tc.expectedErr == "" {
+				assert.NotNil(t, r)
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestRouter_Fetch(t *testing.T) {
+	testCases := []struct {
+		name          string
+		fetch         func(context.Context, *npm.Router) error
+		expectedURL   string
+		expectedToken string
+	}{
+		{
+			name: "unscoped package meta",
+			fetch: func(ctx context.Context, r *npm.Router) error {
+				_, err := r.FetchPackageMeta(ctx, "react")
+				return err
+			},
+			expectedURL: "https://registry.npmjs.org/react",
+		},
+		{
+			name: "scoped package meta",
+			fetch: func(ctx context.Context, r *npm.Router) error {
+				_, err := r.FetchPackageMeta(ctx, "@acme/ui")
+				return err
+			},
+			expectedURL:   "https://npm.acme.internal/@acme/ui",
+			expectedToken: "Bearer acme-token",
+		},
+		{
+			name: "scoped package",
+			fetch: func(ctx context.Context, r *npm.Router) error {
+				_, err := r.FetchPackage(ctx, "@acme/ui", "1.2.3")
+				return err
+			},
+			expectedURL:   "https://npm.acme.internal/@acme/ui/1.2.3",
+			expectedToken: "Bearer acme-token",
+		},
+		{
+			name: "scope configured without @",
+			fetch: func(ctx context.Context, r *npm.Router) error {
+				_, err := r.FetchPackage(ctx, "@corp/tools", "2.0.0")
+				return err
+			},
+			expectedURL: "https://npm.corp.internal/registry/@corp/tools/2.0.0",
+		},
+		{
+			name: "unconfigured scope",
+			fetch: func(ctx context.Context, r *npm.Router) error {
+				_, err := r.FetchPackage(ctx, "@types/node", "20.0.0")
+				return err
+			},
+			expectedURL: "https://registry.npmjs.org/@types/node/20.0.0",
+		},
+		{
+			name: "scope as unscoped package name prefix",
+			fetch: func(ctx context.Context, r *npm.Router) error {
+				_, err := r.FetchPackageMeta(ctx, "acme")
+				return err
+			},
+			expectedURL: "https://registry.npmjs.org/acme",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := &recordTransport{}
+			r, err := npm.NewRouter(routerConfig, npm.CircuitBreakerConfig{}, npm.ClientOptionHTTPTransport(transport))
+			require.NoError(t, err)
+
+			require.NoError(t, tc.fetch(context.Background(), r))
+			assert.Equal(t, []string{tc.expectedURL}, transport.urls)
+			assert.Equal(t, []string{tc.expectedToken}, transport.auths)
+		})
+	}
+}
+
+// hostFaultTransport is an [http.RoundTripper] responding with a 502 error to the requests to the failing host,
+// and with an empty JSON object to the other requests.
+type hostFaultTransport struct {
+	failingHost string
+}
+
+func (rt *hostFaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == rt.failingHost {
+		return &http.Response{
+			StatusCode: http.StatusBadGateway,
+			Body:       io.NopCloser(strings.NewReader("")),
+			Request:    req,
+		}, nil
+	}
+	return (&recordTransport{}).RoundTrip(req)
+}
+
+func TestRouter_CircuitBreaker(t *testing.T) {
+	breakerCfg := npm.CircuitBreakerConfig{FailureRate: 0.5, WindowSize: 2, MinRequests: 2, OpenTimeout: time.Minute}
+	r, err := npm.NewRouter(routerConfig, breakerCfg,
+		npm.ClientOptionHTTPTransport(&hostFaultTransport{failingHost: "npm.acme.internal"}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	assert.Equal(t, npm.CircuitBreakerStatus{State: npm.CircuitClosed}, r.Status())
+
+	_, err = r.FetchPackageMeta(ctx, "react")
+	require.NoError(t, err)
+	for range 2 {
+		_, err = r.FetchPackageMeta(ctx, "@acme/ui")
+		require.ErrorIs(t, err, npm.ErrRegistryUnavailable)
+	}
+
+	_, err = r.FetchPackageMeta(ctx, "@acme/ui")
+	assert.ErrorIs(t, err, npm.ErrCircuitOpen, "the circuit of the failing scope registry is open")
+	_, err = r.FetchPackageMeta(ctx, "lodash")
+	assert.NoError(t, err, "the circuit of the default registry stays closed")
+
+	status := r.Status()
+	assert.Equal(t, npm.CircuitOpen, status.State)
+	assert.Equal(t, 4, status.Requests)
+	assert.Equal(t, 2, status.Failures)
+	assert.NotNil(t, status.OpenedAt)
+}
+
+func TestRouter_RateLimitStats(t *testing.T) {
+	r, err := npm.NewRouter(routerConfig, npm.CircuitBreakerConfig{}, npm.ClientOptionHTTPTransport(&recordTransport{}))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = r.FetchPackageMeta(ctx, "react")
+	require.NoError(t, err)
+	_, err = r.FetchPackageMeta(ctx, "@acme/ui")
+	require.NoError(t, err)
+	_, err = r.FetchPackage(ctx, "@acme/ui", "1.2.3")
+	require.NoError(t, err)
+
+	stats := r.RateLimitStats()
+	assert.Equal(t, int64(1), stats["registry.npmjs.org"].Requests)
+	assert.Equal(t, int64(2), stats["npm.acme.internal"].Requests, "the scope inherits the default rate limit")
+}
+
+func TestRouter_RateLimitSharedByHost(t *testing.T) {
+	cfg := npm.ClientConfig{
+		RegistryURL: "https://registry.npmjs.org",
+		RateLimit:   npm.RateLimitConfig{RequestsPerSecond: 0.01, Burst: 1},
+		Scopes: map[string]npm.ClientConfig{
+			"@mirror": {RegistryURL: "https://registry.npmjs.org/mirror"},
+		},
+	}
+	r, err := npm.NewRouter(cfg, npm.CircuitBreakerConfig{}, npm.ClientOptionHTTPTransport(&recordTransport{}))
+	require.NoError(t, err)
+
+	_, err = r.FetchPackageMeta(context.Background(), "react")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = r.FetchPackageMeta(ctx, "@mirror/ui")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the scope registry of the same host waits for the same bucket")
+
+	assert.Equal(t, map[string]npm.RateLimitStats{
+		"registry.npmjs.org": {Requests: 1, Rejected: 1},
+	}, r.RateLimitStats())
+}
@@ -0,0 +1,66 @@
+package npm
+
+import "context"
+
+type (
+	// ResolveTrace is a set of hooks to follow the progress of the graph resolutions of a [Resolver],
+	// like [net/http/httptrace.ClientTrace] for HTTP requests. Any hook may be nil, and the hooks
+	// may be called concurrently.
+	ResolveTrace struct {
+		// FetchStart is called when the registry fetch of a package starts, before waiting for a fetch slot.
+		FetchStart func(name string)
+		// FetchDone is called when the registry fetch of a package is done, with its error if it failed.
+		FetchDone func(name string, err error)
+		// NodeResolved is called when the root node, or a dependency of a node, is resolved.
+		NodeResolved func(NodeResolution)
+	}
+
+	// NodeResolution is the resolution of a dependency to a node of a [Graph].
+	NodeResolution struct {
+		// Name is the name of the resolved package.
+		Name string `json:"name"`
+		// Version is the resolved version of the package.
+		Version string `json:"version"`
+		// Parent is the ID of the node depending on the package, empty for the root node.
+		Parent string `json:"parent,omitempty"`
+		// Constraint is the version constraint declared by the parent node.
+		Constraint string `json:"constraint,omitempty"`
+		// Deduplicated reports whether the node was already resolved for another dependent.
+		Deduplicated bool `json:"deduplicated,omitempty"`
+	}
+
+	// resolveTraceKey is the context key of the [ResolveTrace].
+	resolveTraceKey struct{}
+)
+
+// WithResolveTrace returns a context based on the provided parent context, with the hooks of the trace
+// called by the graph resolutions using it.
+func WithResolveTrace(ctx context.Context, trace *ResolveTrace) context.Context {
+	return context.WithValue(ctx, resolveTraceKey{}, trace)
+}
+
+// contextResolveTrace returns the [ResolveTrace] of the context, or an empty one.
+func contextResolveTrace(ctx context.Context) *ResolveTrace {
+	if trace, ok := ctx.Value(resolveTraceKey{}).(*ResolveTrace); ok && trace != nil {
+		return trace
+	}
+	return &ResolveTrace{}
+}
+
+func (t *ResolveTrace) fetchStart(name string) {
+	if t.FetchStart != nil {
+		t.FetchStart(name)
+	}
+}
+
+func (t *ResolveTrace) fetchDone(name string, err error) {
+	if t.FetchDone != nil {
+		t.FetchDone(name, err)
+	}
+}
+
+func (t *ResolveTrace) nodeResolved(resolution NodeResolution) {
+	if t.NodeResolved != nil {
+		t.NodeResolved(resolution)
+	}
+}
@@ -0,0 +1,73 @@
+package npm
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// VersionInfo describes a published version of an NPM package.
+type VersionInfo struct {
+	// Version is the version of the NPM package.
+	Version string `json:"version"`
+	// Published is the publish time of the version, nil when the registry does not report it.
+	Published *time.Time `json:"published,omitempty"`
+	// Deprecated is the deprecation message of the version, empty when it is not deprecated.
+	Deprecated string `json:"deprecated,omitempty"`
+	// DistTags are the distribution tags pointing to the version, like "latest".
+	DistTags []string `json:"distTags,omitempty"`
+}
+
+// ListVersions returns the versions of the package sorted by semver precedence, followed by the versions
+// that are not valid semver, sorted lexically. When the constraint is not nil, only the versions it matches
+// are returned.
+func (m *PackageMeta) ListVersions(constraint *semver.Constraints) []VersionInfo {
+	tags := map[string][]string{}
+	for _, tag := range slices.Sorted(maps.Keys(m.DistTags)) {
+		tags[m.DistTags[tag]] = append(tags[m.DistTags[tag]], tag)
+	}
+
+	type parsedVersion struct {
+		VersionInfo
+		semver *semver.Version
+	}
+
+	var parsed []parsedVersion
+	for version, pkg := range m.Versions {
+		v, err := semver.NewVersion(version)
+		if err != nil {
+			v = nil
+		}
+		if constraint != nil && (v == nil || !constraint.Check(v)) {
+			continue
+		}
+
+		info := VersionInfo{Version: version, Deprecated: string(pkg.Deprecated), DistTags: tags[version]}
+		if published, ok := m.Time[version]; ok {
+			info.Published = &published
+		}
+		parsed = append(parsed, parsedVersion{VersionInfo: info, semver: v})
+	}
+
+	slices.SortFunc(parsed, func(a, b parsedVersion) int {
+		switch {
+		case a.semver != nil && b.semver != nil:
+			return cmp.Or(a.semver.Compare(b.semver), cmp.Compare(a.Version, b.Version))
+		case a.semver != nil:
+			return -1
+		case b.semver != nil:
+			return 1
+		default:
+			return cmp.Compare(a.Version, b.Version)
+		}
+	})
+
+	versions := make([]VersionInfo, 0, len(parsed))
+	for _, p := range parsed {
+		versions = append(versions, p.VersionInfo)
+	}
+	return versions
+}
@@ -0,0 +1,71 @@
+package npm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestPackageMeta_ListVersions(t *testing.T) {
+	published := time.Date(2021, 2, 20, 15, 42, 16, 0, time.UTC)
+	meta := &npm.PackageMeta{
+		Name:     "foo",
+		DistTags: map[string]string{"latest": "1.10.0", "stable": "1.10.0", "next": "2.0.0-beta.1"},
+		Versions: map[string]npm.Package{
+			"1.10.0":       {Name: "foo", Version: "1.10.0"},
+			"1.2.0":        {Name: "foo", Version: "1.2.0", Deprecated: "critical bug, use 1.10.0"},
+			"2.0.0-beta.1": {Name: "foo", Version: "2.0.0-beta.1"},
+			"0.1":          {Name: "foo", Version: "0.1"},
+			"next-gen":     {Name: "foo", Version: "next-gen"},
+		},
+		Time: npm.PublishTimes{"created": published, "1.10.0": published},
+	}
+
+	testCases := []struct {
+		name             string
+		rng              string
+		expectedVersions []npm.VersionInfo
+	}{
+		{
+			name: "all versions",
+			expectedVersions: []npm.VersionInfo{
+				{Version: "0.1"},
+				{Version: "1.2.0", Deprecated: "critical bug, use 1.10.0"},
+				{Version: "1.10.0", Published: &published, DistTags: []string{"latest", "stable"}},
+				{Version: "2.0.0-beta.1", DistTags: []string{"next"}},
+				{Version: "next-gen"},
+			},
+		},
+		{
+			name: "versions in range",
+			rng:  "^1.0.0",
+			expectedVersions: []npm.VersionInfo{
+				{Version: "1.2.0", Deprecated: "critical bug, use 1.10.0"},
+				{Version: "1.10.0", Published: &published, DistTags: []string{"latest", "stable"}},
+			},
+		},
+		{
+			name:             "no versions in range",
+			rng:              ">=3",
+			expectedVersions: []npm.VersionInfo{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var constraint *semver.Constraints
+			if tc.rng != "" {
+				var err error
+				constraint, err = semver.NewConstraint(tc.rng)
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.expectedVersions, meta.ListVersions(constraint))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package npm
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// maxPathVisits caps the number of nodes visited while searching dependency paths, since the number of paths,
+// and of the dead ends explored to find them, grows exponentially in dense graphs with cycles.
+const maxPathVisits = 100_000
+
+// PathStep is a node of a dependency path, along with the version constraint of the edge leading to it.
+type PathStep struct {
+	// Name is the name of the NPM package.
+	Name string `json:"name"`
+	// Version is the resolved version of the NPM package.
+	Version string `json:"version"`
+	// Constraint is the version constraint declared by the previous package of the path,
+	// empty for the root node.
+	Constraint string `json:"constraint,omitempty"`
+}
+
+// Paths explains why a package is part of the graph, like the npm explain command. It returns the dependency paths
+// from the root node to every node of the target package, with a version satisfying the constraint when provided.
+// At most limit paths are returned, along with whether more paths exist. Paths do not go through cycles.
+// The search stops after visiting maxPathVisits nodes, in which case the paths are reported truncated too.
+func (g *Graph) Paths(target string, constraint *semver.Constraints, limit int) (paths [][]PathStep, truncated bool) {
+	isTarget := func(n *Node) bool {
+		if n.Name != target {
+			return false
+		}
+		if constraint == nil {
+			return true
+		}
+		v, err := semver.NewVersion(n.Version)
+		return err == nil && constraint.Check(v)
+	}
+
+	reaches := g.reachingNodes(isTarget)
+	if !reaches[g.Root] {
+		return [][]PathStep{}, false
+	}
+
+	paths = [][]PathStep{}
+	onPath := map[string]bool{}
+	path := []PathStep{}
+	visits := 0
+
+	var walk func(id, constraint string) bool
+	walk = func(id, constraint string) bool {
+		if visits++; visits > maxPathVisits {
+			truncated = true
+			return false
+		}
+
+		node := g.Nodes[id]
+		onPath[id] = true
+		path = append(path, PathStep{Name: node.Name, Version: node.Version, Constraint: constraint})
+		defer func() {
+			onPath[id] = false
+			path = path[:len(path)-1]
+		}()
+
+		if isTarget(node) {
+			if len(paths) == limit {
+				truncated = true
+				return false
+			}
+			paths = append(paths, slices.Clone(path))
+		}
+
+		for _, depName := range slices.Sorted(maps.Keys(node.Dependencies)) {
+			edge := node.Dependencies[depName]
+			if onPath[edge.Node] || !reaches[edge.Node] {
+				continue
+			}
+			if !walk(edge.Node, edge.Constraint) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(g.Root, "")
+
+	return paths, truncated
+}
+
+// reachingNodes returns the set of the IDs of the nodes from which a node matching the predicate can be reached,
+// including the matching nodes themselves.
+func (g *Graph) reachingNodes(match func(*Node) bool) map[string]bool {
+	dependents := map[string][]string{}
+	var queue []string
+	for id, node := range g.Nodes {
+		for _, edge := range node.Dependencies {
+			dependents[edge.Node] = append(dependents[edge.Node], id)
+		}
+		if match(node) {
+			queue = append(queue, id)
+		}
+	}
+
+	reaches := map[string]bool{}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if reaches[id] {
+			continue
+		}
+		reaches[id] = true
+		queue = append(queue, dependents[id]...)
+	}
+
+	return reaches
+}
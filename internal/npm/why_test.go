@@ -0,0 +1,147 @@
+package npm_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestGraph_Paths(t *testing.T) {
+	graph := &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{
+					"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"},
+					"bar": {Constraint: "^2.0.0", Node: "bar@2.0.0"},
+				},
+			},
+			"foo@1.2.0": {
+				Name: "foo", Version: "1.2.0",
+				Dependencies: map[string]npm.Edge{"baz": {Constraint: "^3.0.0", Node: "baz@3.1.0"}},
+			},
+			"bar@2.0.0": {
+				Name: "bar", Version: "2.0.0",
+				Dependencies: map[string]npm.Edge{
+					"baz": {Constraint: "~3.0.0", Node: "baz@3.0.5"},
+					"foo": {Constraint: "1.2.0", Node: "foo@1.2.0"},
+				},
+			},
+			"baz@3.1.0": {
+				Name: "baz", Version: "3.1.0",
+				Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.2.0"}},
+			},
+			"baz@3.0.5": {Name: "baz", Version: "3.0.5"},
+		},
+	}
+	constraint, err := semver.NewConstraint("^3.1.0")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name              string
+		target            string
+		constraint        *semver.Constraints
+		limit             int
+		expectedPaths     [][]npm.PathStep
+		expectedTruncated bool
+	}{
+		{
+			name:          "unknown package",
+			target:        "qux",
+			limit:         10,
+			expectedPaths: [][]npm.PathStep{},
+		},
+		{
+			name:   "every version of the package",
+			target: "baz",
+			limit:  10,
+			expectedPaths: [][]npm.PathStep{
+				{
+					{Name: "app", Version: "1.0.0"},
+					{Name: "bar", Version: "2.0.0", Constraint: "^2.0.0"},
+					{Name: "baz", Version: "3.0.5", Constraint: "~3.0.0"},
+				},
+				{
+					{Name: "app", Version: "1.0.0"},
+					{Name: "bar", Version: "2.0.0", Constraint: "^2.0.0"},
+					{Name: "foo", Version: "1.2.0", Constraint: "1.2.0"},
+					{Name: "baz", Version: "3.1.0", Constraint: "^3.0.0"},
+				},
+				{
+					{Name: "app", Version: "1.0.0"},
+					{Name: "foo", Version: "1.2.0", Constraint: "^1.0.0"},
+					{Name: "baz", Version: "3.1.0", Constraint: "^3.0.0"},
+				},
+			},
+		},
+		{
+			name:       "package versions in range with truncation",
+			target:     "baz",
+			constraint: constraint,
+			limit:      1,
+			expectedPaths: [][]npm.PathStep{
+				{
+					{Name: "app", Version: "1.0.0"},
+					{Name: "bar", Version: "2.0.0", Constraint: "^2.0.0"},
+					{Name: "foo", Version: "1.2.0", Constraint: "1.2.0"},
+					{Name: "baz", Version: "3.1.0", Constraint: "^3.0.0"},
+				},
+			},
+			expectedTruncated: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths, truncated := graph.Paths(tc.target, tc.constraint, tc.limit)
+
+			assert.Equal(t, tc.expectedPaths, paths)
+			assert.Equal(t, tc.expectedTruncated, truncated)
+		})
+	}
+}
+
+func TestGraph_Paths_DenseCyclicGraph(t *testing.T) {
+	// The 12 clique nodes depend on each other and back on foo, which is already on every path through them,
+	// so the search explores the billions of simple paths of the clique without finding any other path to bar.
+	const cliqueSize = 12
+
+	foo := &npm.Node{Name: "foo", Version: "1.0.0", Dependencies: map[string]npm.Edge{"bar": {Constraint: "^1.0.0", Node: "bar@1.0.0"}}}
+	graph := &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {Name: "app", Version: "1.0.0", Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"}}},
+			"foo@1.0.0": foo,
+			"bar@1.0.0": {Name: "bar", Version: "1.0.0"},
+		},
+	}
+	for i := range cliqueSize {
+		name := fmt.Sprintf("clique-%02d", i)
+		node := &npm.Node{Name: name, Version: "1.0.0", Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"}}}
+		for j := range cliqueSize {
+			if j != i {
+				dep := fmt.Sprintf("clique-%02d", j)
+				node.Dependencies[dep] = npm.Edge{Constraint: "^1.0.0", Node: dep + "@1.0.0"}
+			}
+		}
+		graph.Nodes[name+"@1.0.0"] = node
+		foo.Dependencies[name] = npm.Edge{Constraint: "^1.0.0", Node: name + "@1.0.0"}
+	}
+
+	paths, truncated := graph.Paths("bar", nil, 100)
+
+	assert.Equal(t, [][]npm.PathStep{
+		{
+			{Name: "app", Version: "1.0.0"},
+			{Name: "foo", Version: "1.0.0", Constraint: "^1.0.0"},
+			{Name: "bar", Version: "1.0.0", Constraint: "^1.0.0"},
+		},
+	}, paths)
+	assert.True(t, truncated)
+}
@@ -0,0 +1,379 @@
+package npm
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// yarnClassicHeader is the header line of yarn v1 lockfiles.
+	yarnClassicHeader = "# yarn lockfile v1"
+	// yarnBerryMetadataKey is the key of the metadata entry of yarn berry (v2+) lockfiles.
+	yarnBerryMetadataKey = "__metadata"
+)
+
+type (
+	// yarnEntry is a package entry of a yarn.lock file, shared by all its descriptors.
+	yarnEntry struct {
+		Version              string            `yaml:"version"`
+		Resolved             string            `yaml:"resolved"`
+		Integrity            string            `yaml:"integrity"`
+		Resolution           string            `yaml:"resolution"`
+		Checksum             string            `yaml:"checksum"`
+		Dependencies         map[string]string `yaml:"dependencies"`
+		OptionalDependencies map[string]string `yaml:"optionalDependencies"`
+		PeerDependencies     map[string]string `yaml:"peerDependencies"`
+	}
+
+	// yarnLock maps every descriptor, in the name@range form, to its package entry.
+	yarnLock map[string]*yarnEntry
+)
+
+// ParseYarnLock reads a yarn.lock file, either of yarn classic (v1) or yarn berry (v2+), into a [Graph].
+func ParseYarnLock(r io.Reader) (*Graph, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading yarn.lock: %w", err)
+	}
+
+	if isYarnBerryLock(content) {
+		return parseYarnBerryLock(content)
+	}
+
+	return parseYarnClassicLock(content)
+}
+
+// isYarnClassicLock reports whether the content is a yarn v1 lockfile.
+func isYarnClassicLock(content []byte) bool {
+	return bytes.Contains(content, []byte(yarnClassicHeader))
+}
+
+// isYarnBerryLock reports whether the content is a yarn berry lockfile.
+func isYarnBerryLock(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(yarnBerryMetadataKey+":")) ||
+		bytes.Contains(content, []byte("\n"+yarnBerryMetadataKey+":"))
+}
+
+// parseYarnClassicLock reads the custom syntax of yarn v1 lockfiles. Yarn v1 lockfiles do not record
+// the direct dependencies of the project, so the root node depends on the packages that no other
+// package depends on.
+func parseYarnClassicLock(content []byte) (*Graph, error) {
+	lock := yarnLock{}
+
+	var (
+		descriptors []string
+		entry       *yarnEntry
+		nested      map[string]string
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		switch indent := len(line) - len(trimmed); indent {
+		case 0:
+			key, ok := strings.CutSuffix(trimmed, ":")
+			if !ok {
+				return nil, fmt.Errorf("%w: yarn.lock line %d: expected an entry key", ErrInvalidLockfile, lineNum)
+			}
+
+			descriptors = descriptors[:0]
+			for descriptor := range strings.SplitSeq(key, ",") {
+				descriptor, err := unquoteYarnToken(strings.TrimSpace(descriptor))
+				if err != nil {
+					return nil, fmt.Errorf("%w: yarn.lock line %d: %w", ErrInvalidLockfile, lineNum, err)
+				}
+				descriptors = append(descriptors, descriptor)
+			}
+
+			entry, nested = &yarnEntry{}, nil
+			for _, descriptor := range descriptors {
+				lock[descriptor] = entry
+			}
+		case 2:
+			if entry == nil {
+				return nil, fmt.Errorf("%w: yarn.lock line %d: field outside of an entry", ErrInvalidLockfile, lineNum)
+			}
+
+			if field, ok := strings.CutSuffix(trimmed, ":"); ok {
+				nested = map[string]string{}
+				switch field {
+				case "dependencies":
+					entry.Dependencies = nested
+				case "optionalDependencies":
+					entry.OptionalDependencies = nested
+				case "peerDependencies":
+					entry.PeerDependencies = nested
+				}
+				continue
+			}
+
+			field, value, err := splitYarnField(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("%w: yarn.lock line %d: %w", ErrInvalidLockfile, lineNum, err)
+			}
+			nested = nil
+
+			switch field {
+			case "version":
+				entry.Version = value
+			case "resolved":
+				entry.Resolved = value
+			case "integrity":
+				entry.Integrity = value
+			}
+		case 4:
+			if nested == nil {
+				return nil, fmt.Errorf("%w: yarn.lock line %d: unexpected indentation", ErrInvalidLockfile, lineNum)
+			}
+
+			name, constraint, err := splitYarnField(trimmed)
+			if err != nil {
+				return nil, fmt.Errorf("%w: yarn.lock line %d: %w", ErrInvalidLockfile, lineNum, err)
+			}
+			nested[name] = constraint
+		default:
+			return nil, fmt.Errorf("%w: yarn.lock line %d: unexpected indentation", ErrInvalidLockfile, lineNum)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: yarn.lock: %w", ErrInvalidLockfile, err)
+	}
+
+	lg := newLockfileGraph("", "")
+	nodes := map[*yarnEntry]*Node{}
+	for _, descriptor := range slices.Sorted(maps.Keys(lock)) {
+		entry := lock[descriptor]
+		if _, ok := nodes[entry]; ok {
+			continue
+		}
+
+		name, _, err := splitDescriptor(descriptor)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Version == "" {
+			return nil, fmt.Errorf("%w: yarn.lock entry %q has no version", ErrInvalidLockfile, descriptor)
+		}
+
+		resolved, _, _ := strings.Cut(entry.Resolved, "#")
+		nodes[entry] = lg.node(name, entry.Version, resolved, entry.Integrity)
+	}
+
+	dependedOn, err := lg.linkYarnDependencies(lock, nodes, func(name, constraint string) string {
+		return name + "@" + constraint
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lg.linkRootless(lock, nodes, dependedOn)
+
+	return lg.graph, nil
+}
+
+// parseYarnBerryLock reads the YAML lockfiles of yarn berry. The root node is the workspace
+// located at the root of the project.
+func parseYarnBerryLock(content []byte) (*Graph, error) {
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("%w: decoding yarn.lock: %w", ErrInvalidLockfile, err)
+	}
+
+	lock := yarnLock{}
+	var root *yarnEntry
+	for key, value := range raw {
+		if key == yarnBerryMetadataKey {
+			continue
+		}
+
+		var entry yarnEntry
+		if err := value.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("%w: decoding yarn.lock entry %q: %w", ErrInvalidLockfile, key, err)
+		}
+
+		for descriptor := range strings.SplitSeq(key, ",") {
+			lock[strings.TrimSpace(descriptor)] = &entry
+		}
+		if strings.HasSuffix(entry.Resolution, "@workspace:.") {
+			root = &entry
+		}
+	}
+
+	nodes := map[*yarnEntry]*Node{}
+	lg := newLockfileGraph("", "")
+	if root != nil {
+		name, _, err := splitDescriptor(root.Resolution)
+		if err != nil {
+			return nil, err
+		}
+		lg = newLockfileGraph(name, root.Version)
+		nodes[root] = lg.graph.RootNode()
+	}
+
+	for _, descriptor := range slices.Sorted(maps.Keys(lock)) {
+		entry := lock[descriptor]
+		if _, ok := nodes[entry]; ok {
+			continue
+		}
+
+		if entry.Resolution == "" || entry.Version == "" {
+			return nil, fmt.Errorf("%w: yarn.lock entry %q has no resolution or version", ErrInvalidLockfile, descriptor)
+		}
+
+		name, rng, err := splitDescriptor(entry.Resolution)
+		if err != nil {
+			return nil, err
+		}
+		node := lg.node(name, entry.Version, "", "")
+		// A patched package shares the node of the original package, which keeps the checksum of the original tarball.
+		if !strings.HasPrefix(rng, "patch:") {
+			node.Checksum = entry.Checksum
+		}
+		nodes[entry] = node
+	}
+
+	dependedOn, err := lg.linkYarnDependencies(lock, nodes, func(name, constraint string) string {
+		// Dependencies without a protocol, like "^1.0.0", are implicitly resolved with the npm protocol.
+		if !strings.Contains(constraint, ":") {
+			constraint = "npm:" + constraint
+		}
+		return name + "@" + constraint
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lg.linkRootless(lock, nodes, dependedOn)
+
+	return lg.graph, nil
+}
+
+// linkYarnDependencies links every entry to its dependencies, found in the lockfile by their descriptor.
+// It returns the set of the IDs of the nodes that are depended on.
+func (lg *lockfileGraph) linkYarnDependencies(
+	lock yarnLock, nodes map[*yarnEntry]*Node, descriptor func(name, constraint string) string,
+) (map[string]bool, error) {
+	dependedOn := map[string]bool{}
+	linked := map[*yarnEntry]bool{}
+
+	for _, key := range slices.Sorted(maps.Keys(lock)) {
+		entry := lock[key]
+		if linked[entry] {
+			continue
+		}
+		linked[entry] = true
+
+		for _, d := range []declaredDependencies{
+			{deps: entry.Dependencies, required: true},
+			{deps: entry.OptionalDependencies},
+			{deps: entry.PeerDependencies},
+		} {
+			for _, depName := range slices.Sorted(maps.Keys(d.deps)) {
+				dep, ok := lock[descriptor(depName, d.deps[depName])]
+				if !ok {
+					if d.required {
+						return nil, fmt.Errorf("%w: dependency %s@%s of %s is not locked", ErrInvalidLockfile, depName, d.deps[depName], nodes[entry].ID())
+					}
+					continue
+				}
+				lg.link(nodes[entry], depName, d.deps[depName], nodes[dep])
+				dependedOn[nodes[dep].ID()] = true
+			}
+		}
+	}
+
+	return dependedOn, nil
+}
+
+// linkRootless links the root node to the entries that are not reachable from it,
+// starting with the ones no other package depends on.
+func (lg *lockfileGraph) linkRootless(lock yarnLock, nodes map[*yarnEntry]*Node, dependedOn map[string]bool) {
+	root := lg.graph.RootNode()
+	descriptors := slices.Sorted(maps.Keys(lock))
+
+	linkRoot := func(descriptor string, node *Node) {
+		if _, linked := root.Dependencies[node.Name]; linked {
+			return
+		}
+		name, constraint, _ := splitDescriptor(descriptor)
+		lg.link(root, name, constraint, node)
+	}
+
+	for _, descriptor := range descriptors {
+		if node := nodes[lock[descriptor]]; node != root && !dependedOn[node.ID()] {
+			linkRoot(descriptor, node)
+		}
+	}
+
+	// Packages only depended on by each other, through a cycle, are still unreachable.
+	unreachable := lg.unreachable()
+	for _, descriptor := range descriptors {
+		if node := nodes[lock[descriptor]]; unreachable[node.ID()] {
+			linkRoot(descriptor, node)
+			unreachable = lg.unreachable()
+		}
+	}
+}
+
+// splitDescriptor splits a yarn descriptor or resolution, like "@scope/name@npm:^1.0.0",
+// into its package name and range. The package name of npm aliases, like "alias@npm:name@^1.0.0",
+// is the aliased package name.
+func splitDescriptor(descriptor string) (name, constraint string, err error) {
+	at := strings.Index(descriptor[min(1, len(descriptor)):], "@") + 1
+	if at <= 0 {
+		return "", "", fmt.Errorf("%w: invalid yarn.lock descriptor %q", ErrInvalidLockfile, descriptor)
+	}
+
+	name, constraint = descriptor[:at], descriptor[at+1:]
+	if aliasName, aliasConstraint, ok := parseAliasSpec(constraint); ok {
+		return aliasName, aliasConstraint, nil
+	}
+
+	return name, constraint, nil
+}
+
+// splitYarnField splits a yarn v1 "key value" line, where both can be double-quoted.
+func splitYarnField(line string) (key, value string, err error) {
+	if strings.HasPrefix(line, `"`) {
+		end := strings.Index(line[1:], `"`) + 1
+		if end <= 0 {
+			return "", "", fmt.Errorf("unterminated quoted key in %q", line)
+		}
+		key, value = line[1:end], strings.TrimSpace(line[end+1:])
+	} else {
+		var ok bool
+		if key, value, ok = strings.Cut(line, " "); !ok {
+			return "", "", fmt.Errorf("missing value in %q", line)
+		}
+	}
+
+	value, err = unquoteYarnToken(strings.TrimSpace(value))
+	return key, value, err
+}
+
+func unquoteYarnToken(token string) (string, error) {
+	if !strings.HasPrefix(token, `"`) {
+		return token, nil
+	}
+
+	unquoted, err := strconv.Unquote(token)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted string %s: %w", token, err)
+	}
+	return unquoted, nil
+}
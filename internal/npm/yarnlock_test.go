@@ -0,0 +1,150 @@
+package npm_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+func TestParseYarnLock(t *testing.T) {
+	testCases := []struct {
+		name          string
+		file          string
+		expectedGraph *npm.Graph
+	}{
+		{
+			name: "yarn classic lockfile with alias and scoped package",
+			file: "testdata/yarn_classic.lock",
+			expectedGraph: &npm.Graph{
+				Root: "root@",
+				Nodes: map[string]*npm.Node{
+					"root@": {
+						Name: "root",
+						Dependencies: map[string]npm.Edge{
+							"@scope/bar": {Constraint: "^2.0.0", Node: "@scope/bar@2.1.0"},
+							"baz":        {Constraint: "~3.1.0", Node: "baz@3.1.4"},
+						},
+					},
+					"@scope/bar@2.1.0": {
+						Name: "@scope/bar", Version: "2.1.0",
+						Resolved: "https://registry.yarnpkg.com/@scope/bar/-/bar-2.1.0.tgz", Integrity: "sha512-bar",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.0.0", Node: "foo@1.4.0"}},
+					},
+					"baz@3.1.4": {
+						Name: "baz", Version: "3.1.4",
+						Resolved: "https://registry.yarnpkg.com/baz/-/baz-3.1.4.tgz", Integrity: "sha512-baz3",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^2.0.0", Node: "foo@2.0.0"}},
+					},
+					"foo@1.4.0": {
+						Name: "foo", Version: "1.4.0",
+						Resolved: "https://registry.yarnpkg.com/foo/-/foo-1.4.0.tgz", Integrity: "sha512-foo1",
+					},
+					"foo@2.0.0": {
+						Name: "foo", Version: "2.0.0",
+						Resolved: "https://registry.yarnpkg.com/foo/-/foo-2.0.0.tgz", Integrity: "sha512-foo2",
+					},
+				},
+			},
+		},
+		{
+			name: "yarn berry lockfile with alias, scoped package, patch and workspace",
+			file: "testdata/yarn_berry.lock",
+			expectedGraph: &npm.Graph{
+				Root: "app@0.0.0-use.local",
+				Nodes: map[string]*npm.Node{
+					"app@0.0.0-use.local": {
+						Name:    "app",
+						Version: "0.0.0-use.local",
+						Dependencies: map[string]npm.Edge{
+							"@scope/bar": {Constraint: "npm:^2.0.0", Node: "@scope/bar@2.1.0"},
+							"baz-alias":  {Constraint: "npm:baz@~3.1.0", Node: "baz@3.1.4"},
+							"ui":         {Constraint: "workspace:*", Node: "ui@0.0.0-use.local"},
+						},
+					},
+					"@scope/bar@2.1.0": {
+						Name: "@scope/bar", Version: "2.1.0", Checksum: "10c0/bar",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "npm:^1.0.0", Node: "foo@1.4.0"}},
+					},
+					"baz@3.1.4": {
+						Name: "baz", Version: "3.1.4", Checksum: "10c0/baz",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^2.0.0", Node: "foo@2.0.0"}},
+					},
+					"foo@1.4.0": {Name: "foo", Version: "1.4.0", Checksum: "10c0/foo1"},
+					"foo@2.0.0": {Name: "foo", Version: "2.0.0", Checksum: "10c0/foo2"},
+					"ui@0.0.0-use.local": {
+						Name: "ui", Version: "0.0.0-use.local",
+						Dependencies: map[string]npm.Edge{"foo": {Constraint: "^1.2.0", Node: "foo@1.4.0"}},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, err := os.ReadFile(tc.file)
+			require.NoError(t, err)
+
+			graph, err := npm.ParseYarnLock(strings.NewReader(string(content)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedGraph, graph)
+
+			graph, err = npm.ParseLockfile(strings.NewReader(string(content)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedGraph, graph)
+		})
+	}
+}
+
+func TestParseYarnLock_Errors(t *testing.T) {
+	testCases := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{
+			name:        "classic entry without key",
+			content:     "# yarn lockfile v1\nfoo@^1.0.0\n  version \"1.0.0\"\n",
+			expectedErr: "invalid lockfile: yarn.lock line 2: expected an entry key",
+		},
+		{
+			name:        "classic unexpected indentation",
+			content:     "# yarn lockfile v1\nfoo@^1.0.0:\n    version \"1.0.0\"\n",
+			expectedErr: "invalid lockfile: yarn.lock line 3: unexpected indentation",
+		},
+		{
+			name:        "classic entry without version",
+			content:     "# yarn lockfile v1\nfoo@^1.0.0:\n  resolved \"https://registry.yarnpkg.com/foo/-/foo-1.0.0.tgz\"\n",
+			expectedErr: "invalid lockfile: yarn.lock entry \"foo@^1.0.0\" has no version",
+		},
+		{
+			name:        "classic dependency not locked",
+			content:     "# yarn lockfile v1\nfoo@^1.0.0:\n  version \"1.0.0\"\n  dependencies:\n    bar \"^2.0.0\"\n",
+			expectedErr: "invalid lockfile: dependency bar@^2.0.0 of foo@1.0.0 is not locked",
+		},
+		{
+			name:        "berry decoding error",
+			content:     "__metadata:\n  version: 8\nfoo@npm:^1.0.0: [\n",
+			expectedErr: "invalid lockfile: decoding yarn.lock: yaml: line 3: did not find expected node content",
+		},
+		{
+			name:        "berry entry without resolution",
+			content:     "__metadata:\n  version: 8\n\"foo@npm:^1.0.0\":\n  version: 1.0.0\n",
+			expectedErr: "invalid lockfile: yarn.lock entry \"foo@npm:^1.0.0\" has no resolution or version",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			graph, err := npm.ParseYarnLock(strings.NewReader(tc.content))
+
+			assert.Nil(t, graph)
+			assert.EqualError(t, err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// dotEscaper escapes the content of Graphviz DOT quoted strings.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// DOT renders the graph in the Graphviz DOT language. The root node is bold, the collapsed nodes are dashed,
+// the edges are labeled with their constraint and the edges of dependency cycles are red.
+func DOT(w io.Writer, g *npm.Graph, opts Options) error {
+	v := newView(g, opts)
+
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, id := range v.nodes {
+		attrs := fmt.Sprintf(`label="%s"`, dotEscaper.Replace(v.label(g, id)))
+		switch _, collapsed := v.collapsed[id]; {
+		case id == g.Root:
+			attrs += ", style=bold"
+		case collapsed:
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  \"%s\" [%s];\n", dotEscaper.Replace(id), attrs)
+	}
+
+	for _, e := range v.edges {
+		attrs := fmt.Sprintf(`label="%s"`, dotEscaper.Replace(e.constraint))
+		if e.cycle {
+			attrs += ", color=red, fontcolor=red"
+		}
+		fmt.Fprintf(&b, "  \"%s\" -> \"%s\" [%s];\n", dotEscaper.Replace(e.from), dotEscaper.Replace(e.to), attrs)
+	}
+
+	b.WriteString("}\n")
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("writing DOT graph: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+type (
+	// graphML is the root element of a GraphML document.
+	graphML struct {
+		XMLName xml.Name     `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+		Keys    []graphMLKey `xml:"key"`
+		Graph   graphMLGraph `xml:"graph"`
+	}
+
+	// graphMLKey declares a data attribute of the nodes or edges.
+	graphMLKey struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+
+	graphMLGraph struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	}
+
+	graphMLNode struct {
+		ID   string        `xml:"id,attr"`
+		Data []graphMLData `xml:"data"`
+	}
+
+	graphMLEdge struct {
+		ID     string        `xml:"id,attr"`
+		Source string        `xml:"source,attr"`
+		Target string        `xml:"target,attr"`
+		Data   []graphMLData `xml:"data"`
+	}
+
+	graphMLData struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+)
+
+// graphMLKeys are the data attributes of the rendered nodes and edges.
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+	{ID: "name", For: "node", AttrName: "name", AttrType: "string"},
+	{ID: "version", For: "node", AttrName: "version", AttrType: "string"},
+	{ID: "root", For: "node", AttrName: "root", AttrType: "boolean"},
+	{ID: "collapsed", For: "node", AttrName: "collapsed", AttrType: "int"},
+	{ID: "constraint", For: "edge", AttrName: "constraint", AttrType: "string"},
+	{ID: "cycle", For: "edge", AttrName: "cycle", AttrType: "boolean"},
+}
+
+// GraphML renders the graph as a GraphML document, with the label, name and version of the nodes, the number
+// of collapsed nodes they lead to, and the constraint of the edges and whether they are part of a dependency cycle.
+func GraphML(w io.Writer, g *npm.Graph, opts Options) error {
+	v := newView(g, opts)
+
+	doc := graphML{
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: "dependencies", EdgeDefault: "directed"},
+	}
+
+	for _, id := range v.nodes {
+		node := g.Nodes[id]
+		data := []graphMLData{
+			{Key: "label", Value: v.label(g, id)},
+			{Key: "name", Value: node.Name},
+			{Key: "version", Value: node.Version},
+			{Key: "root", Value: strconv.FormatBool(id == g.Root)},
+		}
+		if hidden, ok := v.collapsed[id]; ok {
+			data = append(data, graphMLData{Key: "collapsed", Value: strconv.Itoa(hidden)})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: id, Data: data})
+	}
+
+	for i, e := range v.edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     "e" + strconv.Itoa(i),
+			Source: e.from,
+			Target: e.to,
+			Data: []graphMLData{
+				{Key: "constraint", Value: e.constraint},
+				{Key: "cycle", Value: strconv.FormatBool(e.cycle)},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("writing GraphML header: %w", err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("encoding GraphML graph: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("writing GraphML graph: %w", err)
+	}
+	return nil
+}
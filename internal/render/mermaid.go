@@ -0,0 +1,56 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// mermaidEscaper escapes the content of Mermaid quoted labels with entity codes.
+var mermaidEscaper = strings.NewReplacer("#", "#35;", `"`, "#quot;")
+
+// Mermaid renders the graph as a Mermaid top-down flowchart. The root node is bold, the collapsed nodes are dashed,
+// the edges are labeled with their constraint and the edges of dependency cycles are red.
+func Mermaid(w io.Writer, g *npm.Graph, opts Options) error {
+	v := newView(g, opts)
+
+	var b strings.Builder
+	b.WriteString("graph TD\n")
+
+	ids := make(map[string]string, len(v.nodes))
+	for i, id := range v.nodes {
+		ids[id] = "n" + strconv.Itoa(i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[id], mermaidEscaper.Replace(v.label(g, id)))
+	}
+
+	var cycleLinks []string
+	for i, e := range v.edges {
+		fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", ids[e.from], mermaidEscaper.Replace(e.constraint), ids[e.to])
+		if e.cycle {
+			cycleLinks = append(cycleLinks, strconv.Itoa(i))
+		}
+	}
+
+	if len(cycleLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:red,color:red\n", strings.Join(cycleLinks, ","))
+	}
+
+	b.WriteString("  classDef root font-weight:bold\n")
+	fmt.Fprintf(&b, "  class %s root\n", ids[g.Root])
+	if len(v.collapsed) > 0 {
+		b.WriteString("  classDef collapsed stroke-dasharray:5 5\n")
+		for _, id := range v.nodes {
+			if _, ok := v.collapsed[id]; ok {
+				fmt.Fprintf(&b, "  class %s collapsed\n", ids[id])
+			}
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("writing Mermaid graph: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package render draws resolved NPM dependency graphs in graph visualization formats.
+package render
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+type (
+	// Options configures the rendering of a graph.
+	Options struct {
+		// MaxDepth collapses the nodes deeper than the given distance from the root node, when positive.
+		// The nodes at the maximum depth are annotated with the number of collapsed nodes they lead to.
+		MaxDepth int
+	}
+
+	// view is the visible part of a graph, prepared for rendering.
+	view struct {
+		// nodes are the IDs of the visible nodes, sorted.
+		nodes []string
+		// edges are the edges between visible nodes, sorted by source node and dependency name.
+		edges []edge
+		// collapsed maps the IDs of the nodes at the maximum depth to the number of collapsed nodes they lead to.
+		collapsed map[string]int
+	}
+
+	// edge is a dependency edge between two visible nodes.
+	edge struct {
+		from, to   string
+		constraint string
+		// cycle reports whether the edge is part of a dependency cycle.
+		cycle bool
+	}
+)
+
+// newView computes the visible nodes and edges of the graph.
+func newView(g *npm.Graph, opts Options) *view {
+	depths := nodeDepths(g)
+	visible := func(id string) bool {
+		depth, ok := depths[id]
+		return ok && (opts.MaxDepth <= 0 || depth <= opts.MaxDepth)
+	}
+
+	v := &view{collapsed: map[string]int{}}
+	components := stronglyConnectedComponents(g)
+	componentSizes := map[int]int{}
+	for _, component := range components {
+		componentSizes[component]++
+	}
+
+	for _, id := range g.SortedIDs() {
+		if !visible(id) {
+			continue
+		}
+		v.nodes = append(v.nodes, id)
+
+		node := g.Nodes[id]
+		for _, depName := range slices.Sorted(maps.Keys(node.Dependencies)) {
+			e := node.Dependencies[depName]
+			if !visible(e.Node) {
+				continue
+			}
+			v.edges = append(v.edges, edge{
+				from:       id,
+				to:         e.Node,
+				constraint: e.Constraint,
+				cycle:      components[id] == components[e.Node] && (id == e.Node || componentSizes[components[id]] > 1),
+			})
+		}
+
+		if opts.MaxDepth > 0 && depths[id] == opts.MaxDepth {
+			if hidden := countHidden(g, id, visible); hidden > 0 {
+				v.collapsed[id] = hidden
+			}
+		}
+	}
+
+	return v
+}
+
+// nodeDepths returns the shortest distance from the root node of every reachable node.
+func nodeDepths(g *npm.Graph) map[string]int {
+	depths := map[string]int{g.Root: 0}
+	queue := []string{g.Root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		node := g.Nodes[id]
+		for _, depName := range slices.Sorted(maps.Keys(node.Dependencies)) {
+			dep := node.Dependencies[depName].Node
+			if _, seen := depths[dep]; !seen {
+				depths[dep] = depths[id] + 1
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return depths
+}
+
+// countHidden returns the number of hidden nodes reachable from the node through hidden nodes only.
+func countHidden(g *npm.Graph, id string, visible func(string) bool) int {
+	seen := map[string]bool{}
+	stack := []string{id}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, e := range g.Nodes[current].Dependencies {
+			if !visible(e.Node) && !seen[e.Node] {
+				seen[e.Node] = true
+				stack = append(stack, e.Node)
+			}
+		}
+	}
+	return len(seen)
+}
+
+// stronglyConnectedComponents maps every node ID to the index of its strongly connected component,
+// with Tarjan's algorithm. Nodes of the same component with more than one node are part of a cycle.
+func stronglyConnectedComponents(g *npm.Graph) map[string]int {
+	var (
+		index      int
+		component  int
+		stack      []string
+		onStack    = map[string]bool{}
+		indexes    = map[string]int{}
+		lowLinks   = map[string]int{}
+		components = map[string]int{}
+		strongly   func(id string)
+	)
+
+	strongly = func(id string) {
+		indexes[id], lowLinks[id] = index, index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, e := range g.Nodes[id].Dependencies {
+			if _, visited := indexes[e.Node]; !visited {
+				strongly(e.Node)
+				lowLinks[id] = min(lowLinks[id], lowLinks[e.Node])
+			} else if onStack[e.Node] {
+				lowLinks[id] = min(lowLinks[id], indexes[e.Node])
+			}
+		}
+
+		if lowLinks[id] == indexes[id] {
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				components[top] = component
+				if top == id {
+					break
+				}
+			}
+			component++
+		}
+	}
+
+	for _, id := range g.SortedIDs() {
+		if _, visited := indexes[id]; !visited {
+			strongly(id)
+		}
+	}
+
+	return components
+}
+
+// label returns the display label of a node, with the number of collapsed nodes it leads to.
+func (v *view) label(g *npm.Graph, id string) string {
+	node := g.Nodes[id]
+	label := node.Name
+	if node.Version != "" {
+		label += "@" + node.Version
+	}
+	if hidden, ok := v.collapsed[id]; ok {
+		label += " (+" + strconv.Itoa(hidden) + " collapsed)"
+	}
+	return label
+}
@@ -0,0 +1,186 @@
+package render_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	"github.com/snyk/npmjs-deps-fetcher/internal/render"
+)
+
+// testGraph returns a graph with a cycle between foo and baz, and a package three levels deep.
+func testGraph() *npm.Graph {
+	return &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{
+					"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"},
+					"bar": {Constraint: `~2.0.0 || "2.1"`, Node: "bar@2.0.0"},
+				},
+			},
+			"foo@1.0.0": {
+				Name: "foo", Version: "1.0.0",
+				Dependencies: map[string]npm.Edge{"baz": {Constraint: "3.x", Node: "baz@3.0.0"}},
+			},
+			"bar@2.0.0": {Name: "bar", Version: "2.0.0"},
+			"baz@3.0.0": {
+				Name: "baz", Version: "3.0.0",
+				Dependencies: map[string]npm.Edge{
+					"foo": {Constraint: "^1.0.0", Node: "foo@1.0.0"},
+					"qux": {Constraint: "*", Node: "qux@1.0.0"},
+				},
+			},
+			"qux@1.0.0": {Name: "qux", Version: "1.0.0"},
+		},
+	}
+}
+
+func TestDOT(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     render.Options
+		expected string
+	}{
+		{
+			name: "whole graph with cycle",
+			expected: `digraph dependencies {
+  node [shape=box];
+  "app@1.0.0" [label="app@1.0.0", style=bold];
+  "bar@2.0.0" [label="bar@2.0.0"];
+  "baz@3.0.0" [label="baz@3.0.0"];
+  "foo@1.0.0" [label="foo@1.0.0"];
+  "qux@1.0.0" [label="qux@1.0.0"];
+  "app@1.0.0" -> "bar@2.0.0" [label="~2.0.0 || \"2.1\""];
+  "app@1.0.0" -> "foo@1.0.0" [label="^1.0.0"];
+  "baz@3.0.0" -> "foo@1.0.0" [label="^1.0.0", color=red, fontcolor=red];
+  "baz@3.0.0" -> "qux@1.0.0" [label="*"];
+  "foo@1.0.0" -> "baz@3.0.0" [label="3.x", color=red, fontcolor=red];
+}
+`,
+		},
+		{
+			name: "collapsed below depth",
+			opts: render.Options{MaxDepth: 1},
+			expected: `digraph dependencies {
+  node [shape=box];
+  "app@1.0.0" [label="app@1.0.0", style=bold];
+  "bar@2.0.0" [label="bar@2.0.0"];
+  "foo@1.0.0" [label="foo@1.0.0 (+2 collapsed)", style=dashed];
+  "app@1.0.0" -> "bar@2.0.0" [label="~2.0.0 || \"2.1\""];
+  "app@1.0.0" -> "foo@1.0.0" [label="^1.0.0"];
+}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			require.NoError(t, render.DOT(&b, testGraph(), tc.opts))
+
+			assert.Equal(t, tc.expected, b.String())
+		})
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     render.Options
+		expected string
+	}{
+		{
+			name: "whole graph with cycle",
+			expected: `graph TD
+  n0["app@1.0.0"]
+  n1["bar@2.0.0"]
+  n2["baz@3.0.0"]
+  n3["foo@1.0.0"]
+  n4["qux@1.0.0"]
+  n0 -->|"~2.0.0 || #quot;2.1#quot;"| n1
+  n0 -->|"^1.0.0"| n3
+  n2 -->|"^1.0.0"| n3
+  n2 -->|"*"| n4
+  n3 -->|"3.x"| n2
+  linkStyle 2,4 stroke:red,color:red
+  classDef root font-weight:bold
+  class n0 root
+`,
+		},
+		{
+			name: "collapsed below depth",
+			opts: render.Options{MaxDepth: 1},
+			expected: `graph TD
+  n0["app@1.0.0"]
+  n1["bar@2.0.0"]
+  n2["foo@1.0.0 (+2 collapsed)"]
+  n0 -->|"~2.0.0 || #quot;2.1#quot;"| n1
+  n0 -->|"^1.0.0"| n2
+  classDef root font-weight:bold
+  class n0 root
+  classDef collapsed stroke-dasharray:5 5
+  class n2 collapsed
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var b bytes.Buffer
+			require.NoError(t, render.Mermaid(&b, testGraph(), tc.opts))
+
+			assert.Equal(t, tc.expected, b.String())
+		})
+	}
+}
+
+func TestGraphML(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, render.GraphML(&b, testGraph(), render.Options{MaxDepth: 1}))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="label" for="node" attr.name="label" attr.type="string"></key>
+  <key id="name" for="node" attr.name="name" attr.type="string"></key>
+  <key id="version" for="node" attr.name="version" attr.type="string"></key>
+  <key id="root" for="node" attr.name="root" attr.type="boolean"></key>
+  <key id="collapsed" for="node" attr.name="collapsed" attr.type="int"></key>
+  <key id="constraint" for="edge" attr.name="constraint" attr.type="string"></key>
+  <key id="cycle" for="edge" attr.name="cycle" attr.type="boolean"></key>
+  <graph id="dependencies" edgedefault="directed">
+    <node id="app@1.0.0">
+      <data key="label">app@1.0.0</data>
+      <data key="name">app</data>
+      <data key="version">1.0.0</data>
+      <data key="root">true</data>
+    </node>
+    <node id="bar@2.0.0">
+      <data key="label">bar@2.0.0</data>
+      <data key="name">bar</data>
+      <data key="version">2.0.0</data>
+      <data key="root">false</data>
+    </node>
+    <node id="foo@1.0.0">
+      <data key="label">foo@1.0.0 (+2 collapsed)</data>
+      <data key="name">foo</data>
+      <data key="version">1.0.0</data>
+      <data key="root">false</data>
+      <data key="collapsed">2</data>
+    </node>
+    <edge id="e0" source="app@1.0.0" target="bar@2.0.0">
+      <data key="constraint">~2.0.0 || &#34;2.1&#34;</data>
+      <data key="cycle">false</data>
+    </edge>
+    <edge id="e1" source="app@1.0.0" target="foo@1.0.0">
+      <data key="constraint">^1.0.0</data>
+      <data key="cycle">false</data>
+    </edge>
+  </graph>
+</graphml>
+`, b.String())
+}
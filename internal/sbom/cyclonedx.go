@@ -0,0 +1,157 @@
+package sbom
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// cycloneDXHashAlgorithms maps the Subresource Integrity algorithms to the CycloneDX ones.
+var cycloneDXHashAlgorithms = map[string]string{
+	"sha1":   "SHA-1",
+	"sha256": "SHA-256",
+	"sha384": "SHA-384",
+	"sha512": "SHA-512",
+}
+
+type (
+	// CycloneDXBOM is a CycloneDX 1.5 Bill of Materials, in its JSON format.
+	CycloneDXBOM struct {
+		BOMFormat    string                `json:"bomFormat"`
+		SpecVersion  string                `json:"specVersion"`
+		SerialNumber string                `json:"serialNumber"`
+		Version      int                   `json:"version"`
+		Metadata     CycloneDXMetadata     `json:"metadata"`
+		Components   []CycloneDXComponent  `json:"components"`
+		Dependencies []CycloneDXDependency `json:"dependencies"`
+	}
+
+	// CycloneDXMetadata describes the BOM, the tool that generated it, and its root component.
+	CycloneDXMetadata struct {
+		Timestamp string             `json:"timestamp"`
+		Tools     CycloneDXTools     `json:"tools"`
+		Component CycloneDXComponent `json:"component"`
+	}
+
+	// CycloneDXTools lists the tools that generated the BOM.
+	CycloneDXTools struct {
+		Components []CycloneDXComponent `json:"components"`
+	}
+
+	// CycloneDXComponent is a software component, an NPM package version.
+	CycloneDXComponent struct {
+		Type               string                       `json:"type"`
+		BOMRef             string                       `json:"bom-ref,omitempty"` //nolint:tagliatelle // CycloneDX field name.
+		Group              string                       `json:"group,omitempty"`
+		Name               string                       `json:"name"`
+		Version            string                       `json:"version,omitempty"`
+		PURL               string                       `json:"purl,omitempty"`
+		Hashes             []CycloneDXHash              `json:"hashes,omitempty"`
+		Licenses           []CycloneDXLicenseChoice     `json:"licenses,omitempty"`
+		ExternalReferences []CycloneDXExternalReference `json:"externalReferences,omitempty"`
+	}
+
+	// CycloneDXHash is a checksum of a component.
+	CycloneDXHash struct {
+		Alg     string `json:"alg"`
+		Content string `json:"content"`
+	}
+
+	// CycloneDXLicenseChoice is either a named license or an SPDX license expression.
+	CycloneDXLicenseChoice struct {
+		License    *CycloneDXLicense `json:"license,omitempty"`
+		Expression string            `json:"expression,omitempty"`
+	}
+
+	// CycloneDXLicense is a license that is not an SPDX license expression.
+	CycloneDXLicense struct {
+		Name string `json:"name"`
+	}
+
+	// CycloneDXExternalReference is a reference to a resource of a component, like its distribution tarball.
+	CycloneDXExternalReference struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	}
+
+	// CycloneDXDependency lists the direct dependencies of a component, by reference.
+	CycloneDXDependency struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	}
+)
+
+// NewCycloneDX generates the CycloneDX BOM of a resolved graph. The root node is the metadata component,
+// every other node is a component, and the dependencies mirror the graph edges. The serial number is derived
+// from the graph nodes, so the BOMs of the same graph only differ by their timestamp.
+func NewCycloneDX(g *npm.Graph, timestamp time.Time) *CycloneDXBOM {
+	bom := &CycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + graphUUID(g),
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: timestamp.UTC().Format(time.RFC3339),
+			Tools: CycloneDXTools{
+				Components: []CycloneDXComponent{{Type: "application", Name: toolName}},
+			},
+			Component: cycloneDXComponent(g.RootNode()),
+		},
+		Components:   []CycloneDXComponent{},
+		Dependencies: []CycloneDXDependency{},
+	}
+
+	for _, id := range g.SortedIDs() {
+		node := g.Nodes[id]
+		if id != g.Root {
+			bom.Components = append(bom.Components, cycloneDXComponent(node))
+		}
+
+		dependsOn := map[string]bool{}
+		for _, edge := range node.Dependencies {
+			dep := g.Nodes[edge.Node]
+			dependsOn[PackageURL(dep.Name, dep.Version)] = true
+		}
+		bom.Dependencies = append(bom.Dependencies, CycloneDXDependency{
+			Ref:       PackageURL(node.Name, node.Version),
+			DependsOn: append([]string{}, slices.Sorted(maps.Keys(dependsOn))...),
+		})
+	}
+
+	return bom
+}
+
+func cycloneDXComponent(node *npm.Node) CycloneDXComponent {
+	purl := PackageURL(node.Name, node.Version)
+	component := CycloneDXComponent{
+		Type:    "library",
+		BOMRef:  purl,
+		Name:    node.Name,
+		Version: node.Version,
+		PURL:    purl,
+	}
+	if scope, name, ok := strings.Cut(node.Name, "/"); ok {
+		component.Group, component.Name = scope, name
+	}
+
+	for _, h := range integrityHashes(node.Integrity) {
+		component.Hashes = append(component.Hashes, CycloneDXHash{Alg: cycloneDXHashAlgorithms[h.algorithm], Content: h.hex})
+	}
+
+	switch {
+	case node.License == "":
+	case isLicenseExpression(node.License):
+		component.Licenses = []CycloneDXLicenseChoice{{Expression: node.License}}
+	default:
+		component.Licenses = []CycloneDXLicenseChoice{{License: &CycloneDXLicense{Name: node.License}}}
+	}
+
+	if node.Resolved != "" {
+		component.ExternalReferences = []CycloneDXExternalReference{{Type: "distribution", URL: node.Resolved}}
+	}
+
+	return component
+}
@@ -0,0 +1,75 @@
+package sbom_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	"github.com/snyk/npmjs-deps-fetcher/internal/sbom"
+)
+
+func TestNewCycloneDX(t *testing.T) {
+	schema := compileSchema(t, "http://cyclonedx.org/schema/bom-1.5.schema.json")
+	timestamp := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("resolved graph", func(t *testing.T) {
+		bom := sbom.NewCycloneDX(testGraph(), timestamp)
+
+		validate(t, schema, bom)
+		assert.Equal(t, &sbom.CycloneDXBOM{
+			BOMFormat:    "CycloneDX",
+			SpecVersion:  "1.5",
+			SerialNumber: bom.SerialNumber,
+			Version:      1,
+			Metadata: sbom.CycloneDXMetadata{
+				Timestamp: "2024-03-01T12:00:00Z",
+				Tools: sbom.CycloneDXTools{
+					Components: []sbom.CycloneDXComponent{{Type: "application", Name: "npmjs-deps-fetcher"}},
+				},
+				Component: sbom.CycloneDXComponent{
+					Type: "library", BOMRef: "pkg:npm/app@1.0.0", Name: "app", Version: "1.0.0", PURL: "pkg:npm/app@1.0.0",
+					Licenses: []sbom.CycloneDXLicenseChoice{{Expression: "MIT"}},
+				},
+			},
+			Components: []sbom.CycloneDXComponent{
+				{
+					Type: "library", BOMRef: "pkg:npm/%40babel/core@7.24.0", Group: "@babel", Name: "core", Version: "7.24.0",
+					PURL: "pkg:npm/%40babel/core@7.24.0",
+					Hashes: []sbom.CycloneDXHash{{
+						Alg: "SHA-512",
+						Content: "7d07e48341a39336b79dfd1ceffc3a5dfdf8056e18bf335f00244b9a66fb5d12" +
+							"dae971dd47e2bd025265c677857d661feae84eceecd95538c5ffc29f9dd64037",
+					}},
+					Licenses: []sbom.CycloneDXLicenseChoice{{Expression: "(MIT OR Apache-2.0)"}},
+					ExternalReferences: []sbom.CycloneDXExternalReference{
+						{Type: "distribution", URL: "https://registry.npmjs.org/@babel/core/-/core-7.24.0.tgz"},
+					},
+				},
+				{
+					Type: "library", BOMRef: "pkg:npm/foo@1.2.3", Name: "foo", Version: "1.2.3", PURL: "pkg:npm/foo@1.2.3",
+					Hashes:   []sbom.CycloneDXHash{{Alg: "SHA-1", Content: "da39a3ee5e6b4b0d3255bfef95601890afd80709"}},
+					Licenses: []sbom.CycloneDXLicenseChoice{{License: &sbom.CycloneDXLicense{Name: "SEE LICENSE IN LICENSE.md"}}},
+					ExternalReferences: []sbom.CycloneDXExternalReference{
+						{Type: "distribution", URL: "https://registry.npmjs.org/foo/-/foo-1.2.3.tgz"},
+					},
+				},
+			},
+			Dependencies: []sbom.CycloneDXDependency{
+				{Ref: "pkg:npm/%40babel/core@7.24.0", DependsOn: []string{"pkg:npm/foo@1.2.3"}},
+				{Ref: "pkg:npm/app@1.0.0", DependsOn: []string{"pkg:npm/%40babel/core@7.24.0", "pkg:npm/foo@1.2.3"}},
+				{Ref: "pkg:npm/foo@1.2.3", DependsOn: []string{"pkg:npm/%40babel/core@7.24.0"}},
+			},
+		}, bom)
+		assert.Equal(t, bom.SerialNumber, sbom.NewCycloneDX(testGraph(), time.Now()).SerialNumber)
+		assert.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, bom.SerialNumber)
+	})
+
+	t.Run("manifest graph without versions", func(t *testing.T) {
+		bom := sbom.NewCycloneDX(npm.NewGraph(&npm.Node{Name: "root"}), timestamp)
+
+		validate(t, schema, bom)
+		assert.Empty(t, bom.Components)
+	})
+}
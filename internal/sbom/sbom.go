@@ -0,0 +1,100 @@
+// Package sbom generates Software Bill of Materials documents from resolved NPM dependency graphs.
+package sbom
+
+import (
+	"crypto/sha1" //nolint:gosec // Name-based UUIDs are defined with SHA-1.
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+// toolName is the name of the tool generating the documents.
+const toolName = "npmjs-deps-fetcher"
+
+// licenseIDRegexp matches an SPDX license identifier, like "Apache-2.0" or "GPL-2.0+".
+var licenseIDRegexp = regexp.MustCompile(`^[A-Za-z0-9.\-]+\+?$`)
+
+// PackageURL returns the package URL of an NPM package version, like "pkg:npm/%40scope/name@1.0.0".
+func PackageURL(name, version string) string {
+	purl := "pkg:npm/" + escapePURLSegment(name)
+	if scope, pkgName, ok := strings.Cut(name, "/"); ok {
+		purl = "pkg:npm/" + escapePURLSegment(scope) + "/" + escapePURLSegment(pkgName)
+	}
+	if version != "" {
+		purl += "@" + escapePURLSegment(version)
+	}
+	return purl
+}
+
+// escapePURLSegment percent-encodes a package URL segment, including the "@" character of scopes.
+func escapePURLSegment(segment string) string {
+	return strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+}
+
+// hash is a checksum of a package tarball.
+type hash struct {
+	// algorithm is the hash algorithm, like "sha512".
+	algorithm string
+	// hex is the hex-encoded checksum.
+	hex string
+}
+
+// integrityHashes decodes the hashes of a Subresource Integrity string, like "sha512-<base64>".
+// Hashes with unknown algorithms or invalid encodings are ignored.
+func integrityHashes(integrity string) []hash {
+	var hashes []hash
+	for entry := range strings.FieldsSeq(integrity) {
+		algorithm, digest, ok := strings.Cut(entry, "-")
+		if !ok {
+			continue
+		}
+		digest, _, _ = strings.Cut(digest, "?")
+
+		switch algorithm {
+		case "sha1", "sha256", "sha384", "sha512":
+		default:
+			continue
+		}
+
+		sum, err := base64.StdEncoding.DecodeString(digest)
+		if err != nil {
+			continue
+		}
+		hashes = append(hashes, hash{algorithm: algorithm, hex: hex.EncodeToString(sum)})
+	}
+	return hashes
+}
+
+// isLicenseExpression reports whether the license is an SPDX license expression, like "MIT" or
+// "(MIT OR Apache-2.0)", rather than a free text like "SEE LICENSE IN LICENSE.md".
+func isLicenseExpression(license string) bool {
+	tokens := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(license))
+	if len(tokens)%2 == 0 || license == "UNLICENSED" {
+		return false
+	}
+
+	for i, token := range tokens {
+		isOperator := token == "AND" || token == "OR" || token == "WITH"
+		if (i%2 == 1) != isOperator || (!isOperator && !licenseIDRegexp.MatchString(token)) {
+			return false
+		}
+	}
+	return true
+}
+
+// graphUUID returns a name-based UUID identifying the graph nodes, so documents of the same graph share their UUID.
+func graphUUID(g *npm.Graph) string {
+	h := sha1.New() //nolint:gosec // Name-based UUIDs are defined with SHA-1.
+	for _, id := range g.SortedIDs() {
+		fmt.Fprintln(h, id)
+	}
+	sum := h.Sum(nil)
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,88 @@
+package sbom_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	"github.com/snyk/npmjs-deps-fetcher/internal/sbom"
+)
+
+// testGraph returns a resolved graph with a scoped package, several license declarations and a cycle.
+func testGraph() *npm.Graph {
+	return &npm.Graph{
+		Root: "app@1.0.0",
+		Nodes: map[string]*npm.Node{
+			"app@1.0.0": {
+				Name: "app", Version: "1.0.0", License: "MIT",
+				Dependencies: map[string]npm.Edge{
+					"@babel/core": {Constraint: "^7.0.0", Node: "@babel/core@7.24.0"},
+					"foo":         {Constraint: "~1.2.0", Node: "foo@1.2.3"},
+				},
+			},
+			"@babel/core@7.24.0": {
+				Name: "@babel/core", Version: "7.24.0", License: "(MIT OR Apache-2.0)",
+				Resolved:  "https://registry.npmjs.org/@babel/core/-/core-7.24.0.tgz",
+				Integrity: "sha512-fQfkg0Gjkza3nf0c7/w6Xf34BW4YvzNfACRLmmb7XRLa6XHdR+K9AlJlxneFfWYf6uhOzuzZVTjF/8KfndZANw==",
+				Dependencies: map[string]npm.Edge{
+					"foo": {Constraint: "^1.0.0", Node: "foo@1.2.3"},
+				},
+			},
+			"foo@1.2.3": {
+				Name: "foo", Version: "1.2.3", License: "SEE LICENSE IN LICENSE.md",
+				Resolved:  "https://registry.npmjs.org/foo/-/foo-1.2.3.tgz",
+				Integrity: "sha1-2jmj7l5rSw0yVb/vlWAYkK/YBwk=",
+				Dependencies: map[string]npm.Edge{
+					"@babel/core": {Constraint: "7.x", Node: "@babel/core@7.24.0"},
+				},
+			},
+		},
+	}
+}
+
+// compileSchema compiles the official JSON schema of the testdata/schema folder, with the schemas it references.
+func compileSchema(tb testing.TB, id string) *jsonschema.Schema {
+	tb.Helper()
+
+	files, err := filepath.Glob(filepath.Join("testdata", "schema", "*.json"))
+	require.NoError(tb, err)
+
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(tb, err)
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+		require.NoError(tb, err)
+		require.NoError(tb, c.AddResource("http://cyclonedx.org/schema/"+filepath.Base(file), doc))
+	}
+
+	schema, err := c.Compile(id)
+	require.NoError(tb, err)
+	return schema
+}
+
+// validate encodes the document and validates it against the schema.
+func validate(tb testing.TB, schema *jsonschema.Schema, doc any) {
+	tb.Helper()
+
+	content, err := json.Marshal(doc)
+	require.NoError(tb, err)
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+	require.NoError(tb, err)
+
+	assert.NoError(tb, schema.Validate(inst))
+}
+
+func TestPackageURL(t *testing.T) {
+	assert.Equal(t, "pkg:npm/foo@1.2.3", sbom.PackageURL("foo", "1.2.3"))
+	assert.Equal(t, "pkg:npm/%40babel/core@7.24.0-rc.1", sbom.PackageURL("@babel/core", "7.24.0-rc.1"))
+	assert.Equal(t, "pkg:npm/root", sbom.PackageURL("root", ""))
+}
@@ -0,0 +1,180 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+)
+
+const (
+	// spdxNoAssertion is the SPDX value of unknown or undetermined fields.
+	spdxNoAssertion = "NOASSERTION"
+	// spdxDocumentID is the SPDX identifier of the document itself.
+	spdxDocumentID = "SPDXRef-DOCUMENT"
+)
+
+// spdxInvalidIDCharsRegexp matches the characters not allowed in SPDX identifiers.
+var spdxInvalidIDCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9.\-]+`)
+
+// spdxChecksumAlgorithms maps the Subresource Integrity algorithms to the SPDX ones.
+var spdxChecksumAlgorithms = map[string]string{
+	"sha1":   "SHA1",
+	"sha256": "SHA256",
+	"sha384": "SHA384",
+	"sha512": "SHA512",
+}
+
+type (
+	// SPDXDocument is an SPDX 2.3 document, in its JSON format.
+	SPDXDocument struct {
+		SPDXVersion       string             `json:"spdxVersion"`
+		DataLicense       string             `json:"dataLicense"`
+		SPDXID            string             `json:"SPDXID"` //nolint:tagliatelle // SPDX field name.
+		Name              string             `json:"name"`
+		DocumentNamespace string             `json:"documentNamespace"`
+		CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+		Packages          []SPDXPackage      `json:"packages"`
+		Relationships     []SPDXRelationship `json:"relationships"`
+	}
+
+	// SPDXCreationInfo describes when and by which tool the document was created.
+	SPDXCreationInfo struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	}
+
+	// SPDXPackage is a package element, an NPM package version.
+	SPDXPackage struct {
+		Name                  string            `json:"name"`
+		SPDXID                string            `json:"SPDXID"` //nolint:tagliatelle // SPDX field name.
+		VersionInfo           string            `json:"versionInfo,omitempty"`
+		DownloadLocation      string            `json:"downloadLocation"`
+		FilesAnalyzed         bool              `json:"filesAnalyzed"`
+		Checksums             []SPDXChecksum    `json:"checksums,omitempty"`
+		LicenseConcluded      string            `json:"licenseConcluded"`
+		LicenseDeclared       string            `json:"licenseDeclared"`
+		CopyrightText         string            `json:"copyrightText"`
+		ExternalRefs          []SPDXExternalRef `json:"externalRefs,omitempty"`
+		PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+	}
+
+	// SPDXChecksum is a checksum of a package.
+	SPDXChecksum struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	}
+
+	// SPDXExternalRef is a reference to an external resource describing a package, like its package URL.
+	SPDXExternalRef struct {
+		ReferenceCategory string `json:"referenceCategory"`
+		ReferenceType     string `json:"referenceType"`
+		ReferenceLocator  string `json:"referenceLocator"`
+	}
+
+	// SPDXRelationship is a relationship between two elements of the document.
+	SPDXRelationship struct {
+		SPDXElementID      string `json:"spdxElementId"`
+		RelationshipType   string `json:"relationshipType"`
+		RelatedSPDXElement string `json:"relatedSpdxElement"`
+	}
+)
+
+// NewSPDX generates the SPDX document of a resolved graph. Every node is a package element, the document
+// describes the root one, and the DEPENDS_ON relationships mirror the graph edges. The package identifiers
+// and the document namespace are derived from the graph nodes, so the documents of the same graph only differ
+// by their creation time.
+func NewSPDX(g *npm.Graph, created time.Time) *SPDXDocument {
+	ids := spdxPackageIDs(g)
+	root := g.RootNode()
+
+	doc := &SPDXDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              strings.TrimSuffix(g.Root, "@"),
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + spdxInvalidIDCharsRegexp.ReplaceAllString(root.Name, "-") + "-" + graphUUID(g),
+		CreationInfo: SPDXCreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + toolName},
+		},
+		Packages: []SPDXPackage{},
+		Relationships: []SPDXRelationship{
+			{SPDXElementID: spdxDocumentID, RelationshipType: "DESCRIBES", RelatedSPDXElement: ids[g.Root]},
+		},
+	}
+
+	for _, id := range g.SortedIDs() {
+		node := g.Nodes[id]
+		doc.Packages = append(doc.Packages, spdxPackage(node, ids[id]))
+
+		dependsOn := map[string]bool{}
+		for _, edge := range node.Dependencies {
+			dependsOn[ids[edge.Node]] = true
+		}
+		for _, depID := range slices.Sorted(maps.Keys(dependsOn)) {
+			doc.Relationships = append(doc.Relationships, SPDXRelationship{
+				SPDXElementID:      ids[id],
+				RelationshipType:   "DEPENDS_ON",
+				RelatedSPDXElement: depID,
+			})
+		}
+	}
+
+	return doc
+}
+
+// spdxPackageIDs assigns an SPDX identifier to every node of the graph, like "SPDXRef-Package-babel-core-7.24.0".
+// Identifiers of nodes whose sanitized name and version collide are suffixed with a hash of the node ID.
+func spdxPackageIDs(g *npm.Graph) map[string]string {
+	ids := map[string]string{}
+	taken := map[string]bool{}
+	for _, id := range g.SortedIDs() {
+		node := g.Nodes[id]
+		spdxID := "SPDXRef-Package-" + strings.Trim(spdxInvalidIDCharsRegexp.ReplaceAllString(node.Name+"-"+node.Version, "-"), "-")
+		if taken[spdxID] {
+			sum := sha256.Sum256([]byte(id))
+			spdxID += "-" + hex.EncodeToString(sum[:4])
+		}
+		ids[id], taken[spdxID] = spdxID, true
+	}
+	return ids
+}
+
+func spdxPackage(node *npm.Node, spdxID string) SPDXPackage {
+	pkg := SPDXPackage{
+		Name:                  node.Name,
+		SPDXID:                spdxID,
+		VersionInfo:           node.Version,
+		DownloadLocation:      spdxNoAssertion,
+		LicenseConcluded:      spdxNoAssertion,
+		LicenseDeclared:       spdxNoAssertion,
+		CopyrightText:         spdxNoAssertion,
+		PrimaryPackagePurpose: "LIBRARY",
+	}
+	if node.Resolved != "" {
+		pkg.DownloadLocation = node.Resolved
+	}
+	if isLicenseExpression(node.License) {
+		pkg.LicenseDeclared = node.License
+	}
+
+	for _, h := range integrityHashes(node.Integrity) {
+		pkg.Checksums = append(pkg.Checksums, SPDXChecksum{Algorithm: spdxChecksumAlgorithms[h.algorithm], ChecksumValue: h.hex})
+	}
+
+	if node.Version != "" {
+		pkg.ExternalRefs = []SPDXExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  PackageURL(node.Name, node.Version),
+		}}
+	}
+
+	return pkg
+}
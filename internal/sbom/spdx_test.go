@@ -0,0 +1,121 @@
+package sbom_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/snyk/npmjs-deps-fetcher/internal/npm"
+	"github.com/snyk/npmjs-deps-fetcher/internal/sbom"
+)
+
+func TestNewSPDX(t *testing.T) {
+	created := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	doc := sbom.NewSPDX(testGraph(), created)
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "app@1.0.0", doc.Name)
+	assert.Equal(t, sbom.SPDXCreationInfo{Created: "2024-03-01T12:00:00Z", Creators: []string{"Tool: npmjs-deps-fetcher"}}, doc.CreationInfo)
+	assert.Regexp(t, `^https://spdx.org/spdxdocs/app-[0-9a-f-]{36}$`, doc.DocumentNamespace)
+	assert.Equal(t, []sbom.SPDXPackage{
+		{
+			Name: "@babel/core", SPDXID: "SPDXRef-Package-babel-core-7.24.0", VersionInfo: "7.24.0",
+			DownloadLocation: "https://registry.npmjs.org/@babel/core/-/core-7.24.0.tgz",
+			Checksums: []sbom.SPDXChecksum{{
+				Algorithm: "SHA512",
+				ChecksumValue: "7d07e48341a39336b79dfd1ceffc3a5dfdf8056e18bf335f00244b9a66fb5d12" +
+					"dae971dd47e2bd025265c677857d661feae84eceecd95538c5ffc29f9dd64037",
+			}},
+			LicenseConcluded: "NOASSERTION", LicenseDeclared: "(MIT OR Apache-2.0)", CopyrightText: "NOASSERTION",
+			ExternalRefs: []sbom.SPDXExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:npm/%40babel/core@7.24.0"},
+			},
+			PrimaryPackagePurpose: "LIBRARY",
+		},
+		{
+			Name: "app", SPDXID: "SPDXRef-Package-app-1.0.0", VersionInfo: "1.0.0", DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION", LicenseDeclared: "MIT", CopyrightText: "NOASSERTION",
+			ExternalRefs: []sbom.SPDXExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:npm/app@1.0.0"},
+			},
+			PrimaryPackagePurpose: "LIBRARY",
+		},
+		{
+			Name: "foo", SPDXID: "SPDXRef-Package-foo-1.2.3", VersionInfo: "1.2.3",
+			DownloadLocation: "https://registry.npmjs.org/foo/-/foo-1.2.3.tgz",
+			Checksums:        []sbom.SPDXChecksum{{Algorithm: "SHA1", ChecksumValue: "da39a3ee5e6b4b0d3255bfef95601890afd80709"}},
+			LicenseConcluded: "NOASSERTION", LicenseDeclared: "NOASSERTION", CopyrightText: "NOASSERTION",
+			ExternalRefs: []sbom.SPDXExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: "pkg:npm/foo@1.2.3"},
+			},
+			PrimaryPackagePurpose: "LIBRARY",
+		},
+	}, doc.Packages)
+	assert.Equal(t, []sbom.SPDXRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Package-app-1.0.0"},
+		{SPDXElementID: "SPDXRef-Package-babel-core-7.24.0", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-foo-1.2.3"},
+		{SPDXElementID: "SPDXRef-Package-app-1.0.0", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-babel-core-7.24.0"},
+		{SPDXElementID: "SPDXRef-Package-app-1.0.0", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-foo-1.2.3"},
+		{SPDXElementID: "SPDXRef-Package-foo-1.2.3", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-babel-core-7.24.0"},
+	}, doc.Relationships)
+}
+
+func TestNewSPDX_RequiredFields(t *testing.T) {
+	content, err := json.Marshal(sbom.NewSPDX(testGraph(), time.Now()))
+	require.NoError(t, err)
+
+	var doc struct {
+		Document      map[string]any   `json:"-"`
+		Packages      []map[string]any `json:"packages"`
+		Relationships []map[string]any `json:"relationships"`
+	}
+	require.NoError(t, json.Unmarshal(content, &doc))
+	require.NoError(t, json.Unmarshal(content, &doc.Document))
+
+	for _, field := range []string{"spdxVersion", "dataLicense", "SPDXID", "name", "documentNamespace", "creationInfo"} {
+		assert.NotEmpty(t, doc.Document[field], "document field %s", field)
+	}
+	for _, pkg := range doc.Packages {
+		for _, field := range []string{"name", "SPDXID", "downloadLocation", "licenseConcluded", "licenseDeclared", "copyrightText"} {
+			assert.NotEmpty(t, pkg[field], "package %v field %s", pkg["SPDXID"], field)
+		}
+		assert.Contains(t, pkg, "filesAnalyzed")
+		assert.Regexp(t, `^SPDXRef-[a-zA-Z0-9.\-]+$`, pkg["SPDXID"])
+	}
+	for _, relationship := range doc.Relationships {
+		for _, field := range []string{"spdxElementId", "relationshipType", "relatedSpdxElement"} {
+			assert.NotEmpty(t, relationship[field], "relationship field %s", field)
+		}
+	}
+}
+
+func TestNewSPDX_DeterministicIDs(t *testing.T) {
+	graph := &npm.Graph{
+		Root: "root@",
+		Nodes: map[string]*npm.Node{
+			"root@": {
+				Name: "root",
+				Dependencies: map[string]npm.Edge{
+					"@a/b": {Constraint: "^1.0.0", Node: "@a/b@1.0.0"},
+					"a-b":  {Constraint: "^1.0.0", Node: "a-b@1.0.0"},
+				},
+			},
+			"@a/b@1.0.0": {Name: "@a/b", Version: "1.0.0"},
+			"a-b@1.0.0":  {Name: "a-b", Version: "1.0.0"},
+		},
+	}
+
+	doc := sbom.NewSPDX(graph, time.Now())
+	ids := make([]string, 0, len(doc.Packages))
+	for _, pkg := range doc.Packages {
+		ids = append(ids, pkg.SPDXID)
+	}
+
+	assert.Equal(t, []string{"SPDXRef-Package-a-b-1.0.0", "SPDXRef-Package-a-b-1.0.0-62924221", "SPDXRef-Package-root"}, ids)
+	assert.Equal(t, doc.Packages, sbom.NewSPDX(graph, time.Now()).Packages)
+	assert.Equal(t, doc.DocumentNamespace, sbom.NewSPDX(graph, time.Now()).DocumentNamespace)
+	assert.Equal(t, "root", doc.Name)
+}
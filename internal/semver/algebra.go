@@ -0,0 +1,227 @@
+package semver
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// coerceRegexp matches the first version-like number sequence of a string, like "1.2" in "v1.2-final".
+var coerceRegexp = regexp.MustCompile(`(^|[^\d])(\d{1,16})(?:\.(\d{1,16}))?(?:\.(\d{1,16}))?(?:$|[^\d])`)
+
+// IsSubsetOf reports whether every version matched by the range is also matched by the other range.
+// Like [Range.Intersects], versions are compared by precedence, without the NPM prerelease rule.
+func (r Range) IsSubsetOf(other Range) bool {
+	merged := other.merge()
+	for _, in := range r {
+		if !slices.ContainsFunc(merged, func(m interval) bool { return m.covers(in) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// MinVersion returns the lowest version matched by the range, or nil when the range does not match any version.
+func (r Range) MinVersion() *semver.Version {
+	var lowest *semver.Version
+	for _, in := range r {
+		v := in.minVersion()
+		if v != nil && (lowest == nil || v.LessThan(lowest)) {
+			lowest = v
+		}
+	}
+	return lowest
+}
+
+// Coerce converts a loose version string, like "v1.2" or "release-42", into the version made of its first
+// numbers, like "1.2.0" or "42.0.0". The prerelease and build metadata are dropped. An error is returned
+// when the string does not contain any number.
+func Coerce(s string) (*semver.Version, error) {
+	m := coerceRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("coerce %q: no version found", s)
+	}
+
+	var parts [3]uint64
+	for i, part := range m[2:] {
+		if part == "" {
+			break
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("coerce %q: %w", s, err)
+		}
+		parts[i] = n
+	}
+	return semver.New(parts[0], parts[1], parts[2], "", ""), nil
+}
+
+// SimplifyRange returns the shortest range matching the same published versions as the provided range,
+// like "^1.2.0 || ^1.3.0 || ^1.4.0" simplified into ">=1.2.0" when 1.4.x are the highest published versions.
+// The versions that are not valid semver are ignored. The original range is returned when it is the shortest,
+// or when no shorter range matches the same versions.
+func SimplifyRange(rng string, versions []string) (string, error) {
+	r, err := ParseRange(rng)
+	if err != nil {
+		return "", err
+	}
+
+	parsed := make([]*semver.Version, 0, len(versions))
+	for _, version := range versions {
+		if v, err := semver.StrictNewVersion(version); err == nil {
+			parsed = append(parsed, v)
+		}
+	}
+	slices.SortFunc(parsed, func(a, b *semver.Version) int { return a.Compare(b) })
+
+	// runs are the contiguous sequences of sorted versions matched by the range.
+	var runs [][2]*semver.Version
+	for i, v := range parsed {
+		if !r.Contains(v) {
+			continue
+		}
+		if len(runs) > 0 && i > 0 && runs[len(runs)-1][1] == parsed[i-1] {
+			runs[len(runs)-1][1] = v
+		} else {
+			runs = append(runs, [2]*semver.Version{v, v})
+		}
+	}
+
+	sets := make([]string, 0, len(runs))
+	for _, run := range runs {
+		first, last := run[0], run[1]
+		switch {
+		case first == parsed[0] && last == parsed[len(parsed)-1]:
+			sets = append(sets, "*")
+		case first.Equal(last):
+			sets = append(sets, first.String())
+		case first == parsed[0]:
+			sets = append(sets, "<="+last.String())
+		case last == parsed[len(parsed)-1]:
+			sets = append(sets, ">="+first.String())
+		default:
+			sets = append(sets, first.String()+" - "+last.String())
+		}
+	}
+
+	simplified := strings.Join(sets, " || ")
+	if len(sets) == 0 {
+		simplified = Range{}.String()
+	}
+	if len(simplified) >= len(rng) {
+		return rng, nil
+	}
+
+	// A prerelease version matched by the range could be excluded from a simplified range without
+	// a prerelease bound on its tuple, in which case the range is kept as is.
+	s, err := ParseRange(simplified)
+	if err != nil {
+		return "", fmt.Errorf("simplified range %s: %w", simplified, err)
+	}
+	for _, v := range parsed {
+		if s.Contains(v) != r.Contains(v) {
+			return rng, nil
+		}
+	}
+	return simplified, nil
+}
+
+// merge returns the union of the range intervals as sorted intervals that neither overlap nor touch.
+func (r Range) merge() []interval {
+	sorted := slices.Clone(r)
+	slices.SortFunc(sorted, func(a, b interval) int { return compareLower(a.lower, b.lower) })
+
+	var merged []interval
+	for _, in := range sorted {
+		if n := len(merged); n > 0 && merged[n-1].touches(in) {
+			merged[n-1].upper = higherUpper(merged[n-1].upper, in.upper)
+			continue
+		}
+		merged = append(merged, in)
+	}
+	return merged
+}
+
+// covers reports whether all the versions of the other interval are contained in the interval.
+func (in interval) covers(other interval) bool {
+	return compareLower(in.lower, other.lower) <= 0 && compareUpper(other.upper, in.upper) <= 0
+}
+
+// touches reports whether the interval, with the lowest lower bound, overlaps or is adjacent to the other one.
+func (in interval) touches(other interval) bool {
+	if in.upper.version == nil || other.lower.version == nil {
+		return true
+	}
+	c := in.upper.version.Compare(other.lower.version)
+	return c > 0 || (c == 0 && (in.upper.inclusive || other.lower.inclusive))
+}
+
+// minVersion returns the lowest version of the interval, or nil when it is empty.
+func (in interval) minVersion() *semver.Version {
+	var v *semver.Version
+	switch {
+	case in.lower.version == nil:
+		v = semver.New(0, 0, 0, "", "")
+	case in.lower.inclusive:
+		v = in.lower.version
+	case in.lower.version.Prerelease() != "":
+		v = semver.New(in.lower.version.Major(), in.lower.version.Minor(), in.lower.version.Patch(),
+			in.lower.version.Prerelease()+".0", "")
+	default:
+		v = semver.New(in.lower.version.Major(), in.lower.version.Minor(), in.lower.version.Patch()+1, "", "")
+	}
+
+	if !in.contains(v) {
+		return nil
+	}
+	return v
+}
+
+// compareLower compares lower bounds, an unbounded one being the lowest and an inclusive one lower than an exclusive one.
+func compareLower(a, b bound) int {
+	switch {
+	case a.version == nil && b.version == nil:
+		return 0
+	case a.version == nil:
+		return -1
+	case b.version == nil:
+		return 1
+	}
+	return cmp.Or(a.version.Compare(b.version), compareInclusive(b.inclusive, a.inclusive))
+}
+
+// compareUpper compares upper bounds, an unbounded one being the highest and an inclusive one higher than an exclusive one.
+func compareUpper(a, b bound) int {
+	switch {
+	case a.version == nil && b.version == nil:
+		return 0
+	case a.version == nil:
+		return 1
+	case b.version == nil:
+		return -1
+	}
+	return cmp.Or(a.version.Compare(b.version), compareInclusive(a.inclusive, b.inclusive))
+}
+
+func compareInclusive(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func higherUpper(a, b bound) bound {
+	if compareUpper(a, b) >= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,360 @@
+package semver_test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+// testRange is a random NPM version range, over small version numbers so the generated ranges often overlap.
+type testRange string
+
+// Generate implements [quick.Generator].
+func (testRange) Generate(rnd *rand.Rand, _ int) reflect.Value {
+	sets := make([]string, 1+rnd.Intn(3))
+	for i := range sets {
+		if rnd.Intn(5) == 0 {
+			sets[i] = randomPartialVersion(rnd) + " - " + randomPartialVersion(rnd)
+			continue
+		}
+		comparators := make([]string, 1+rnd.Intn(2))
+		for j := range comparators {
+			ops := []string{"", "=", "^", "~", ">", ">=", "<", "<="}
+			comparators[j] = ops[rnd.Intn(len(ops))] + randomPartialVersion(rnd)
+		}
+		sets[i] = strings.Join(comparators, " ")
+	}
+	return reflect.ValueOf(testRange(strings.Join(sets, " || ")))
+}
+
+// testVersions is a random list of versions, with a few prerelease ones.
+type testVersions []string
+
+// Generate implements [quick.Generator].
+func (testVersions) Generate(rnd *rand.Rand, size int) reflect.Value {
+	versions := make(testVersions, rnd.Intn(size+1))
+	for i := range versions {
+		versions[i] = randomVersion(rnd).String()
+	}
+	return reflect.ValueOf(versions)
+}
+
+func randomPartialVersion(rnd *rand.Rand) string {
+	switch rnd.Intn(6) {
+	case 0:
+		return "*"
+	case 1:
+		return fmt.Sprintf("%d", rnd.Intn(4))
+	case 2:
+		return fmt.Sprintf("%d.%d", rnd.Intn(4), rnd.Intn(4))
+	default:
+		return randomVersion(rnd).String()
+	}
+}
+
+func randomVersion(rnd *rand.Rand) *semver.Version {
+	prerelease := ""
+	if rnd.Intn(5) == 0 {
+		prerelease = []string{"alpha", "beta.1", "rc.0"}[rnd.Intn(3)]
+	}
+	return semver.New(uint64(rnd.Intn(4)), uint64(rnd.Intn(4)), uint64(rnd.Intn(4)), prerelease, "")
+}
+
+// allVersions returns the release versions up to 4.4.4, followed by a few prerelease versions.
+func allVersions() []*semver.Version {
+	var versions []*semver.Version
+	for major := range uint64(5) {
+		for minor := range uint64(5) {
+			for patch := range uint64(5) {
+				versions = append(versions, semver.New(major, minor, patch, "", ""))
+			}
+		}
+	}
+	for _, prerelease := range []string{"1.0.0-alpha", "1.2.3-beta.1", "2.0.0-rc.0"} {
+		versions = append(versions, semver.MustParse(prerelease))
+	}
+	return versions
+}
+
+func mustParseRange(tb testing.TB, r testRange) semverutil.Range {
+	tb.Helper()
+	rng, err := semverutil.ParseRange(string(r))
+	require.NoError(tb, err)
+	return rng
+}
+
+func TestRange_Properties(t *testing.T) {
+	cfg := &quick.Config{MaxCount: 2000}
+	versions := allVersions()
+
+	t.Run("intersection is symmetric", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(a, b testRange) bool {
+			ra, rb := mustParseRange(t, a), mustParseRange(t, b)
+			return ra.Intersects(rb) == rb.Intersects(ra)
+		}, cfg))
+	})
+
+	t.Run("a range intersects with a range sharing a version", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(a, b testRange) bool {
+			ra, rb := mustParseRange(t, a), mustParseRange(t, b)
+			for _, v := range versions {
+				if ra.Contains(v) && rb.Contains(v) && !ra.Intersects(rb) {
+					return false
+				}
+			}
+			return true
+		}, cfg))
+	})
+
+	t.Run("a range is a subset of itself and of its unions", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(a, b testRange) bool {
+			ra := mustParseRange(t, a)
+			union := mustParseRange(t, a+" || "+b)
+			return ra.IsSubsetOf(ra) && ra.IsSubsetOf(union)
+		}, cfg))
+	})
+
+	t.Run("the versions of a subset are in the superset", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(a, b testRange) bool {
+			ra, rb := mustParseRange(t, a), mustParseRange(t, b)
+			if !ra.IsSubsetOf(rb) {
+				return true
+			}
+			for _, v := range versions {
+				if v.Prerelease() == "" && ra.Contains(v) && !rb.Contains(v) {
+					return false
+				}
+			}
+			return true
+		}, cfg))
+	})
+
+	t.Run("a subset intersects with its superset", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(a, b testRange) bool {
+			ra, rb := mustParseRange(t, a), mustParseRange(t, b)
+			return len(ra) == 0 || !ra.IsSubsetOf(rb) || ra.Intersects(rb)
+		}, cfg))
+	})
+
+	t.Run("the min version is the lowest version of the range", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(a testRange) bool {
+			ra := mustParseRange(t, a)
+			minVersion := ra.MinVersion()
+			if minVersion != nil && !ra.Contains(minVersion) {
+				return false
+			}
+			for _, v := range versions {
+				if ra.Contains(v) && (minVersion == nil || v.LessThan(minVersion)) {
+					return false
+				}
+			}
+			return true
+		}, cfg))
+	})
+
+	t.Run("coercion keeps the first numbers", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(major, minor, patch uint16, prefix bool) bool {
+			s := fmt.Sprintf("%d.%d.%d-final+build.7", major, minor, patch)
+			if prefix {
+				s = "v" + s
+			}
+			v, err := semverutil.Coerce(s)
+			return err == nil && v.Equal(semver.New(uint64(major), uint64(minor), uint64(patch), "", ""))
+		}, cfg))
+	})
+
+	t.Run("a simplified range matches the same versions", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(a testRange, published testVersions) bool {
+			simplified, err := semverutil.SimplifyRange(string(a), published)
+			if err != nil || len(simplified) > len(a) {
+				return false
+			}
+			ra, rs := mustParseRange(t, a), mustParseRange(t, testRange(simplified))
+			for _, version := range published {
+				v := semver.MustParse(version)
+				if ra.Contains(v) != rs.Contains(v) {
+					return false
+				}
+			}
+			return true
+		}, cfg))
+	})
+}
+
+// knownDeviationRegexp matches the ranges whose Masterminds constraint is known to match other versions than
+// the NPM semantics of [semverutil.Range]: the wildcards with an operator or as a hyphen range bound, like ">*",
+// "~0.0.0", that Masterminds matches with any version, and the prerelease comparators, Masterminds matching
+// the prereleases of any version, instead of the ones of the same tuple, when a comparator has a prerelease.
+var knownDeviationRegexp = regexp.MustCompile(`[<>=^~]\*|\* -|- \*|~0\.0\.0|-(alpha|beta|rc)`)
+
+func TestRange_MatchesConstraints(t *testing.T) {
+	versions := allVersions()
+
+	t.Run("same versions as the Masterminds constraints", func(t *testing.T) {
+		require.NoError(t, quick.Check(func(r testRange) bool {
+			if knownDeviationRegexp.MatchString(string(r)) {
+				return true
+			}
+			constraint, err := semver.NewConstraint(string(r))
+			if err != nil {
+				return true
+			}
+			rng := mustParseRange(t, r)
+			for _, v := range versions {
+				if rng.Contains(v) != constraint.Check(v) {
+					t.Logf("range %s, version %s: contains %t, constraint check %t", r, v, rng.Contains(v), constraint.Check(v))
+					return false
+				}
+			}
+			return true
+		}, &quick.Config{MaxCount: 5000}))
+	})
+
+	knownDeviations := []struct {
+		rng, version          string
+		contains, constraints bool
+	}{
+		{rng: ">*", version: "1.0.0", contains: false, constraints: true},
+		{rng: "1.0.0 - *", version: "1.0.0", contains: true, constraints: false},
+		{rng: "~0.0.0", version: "0.1.0", contains: false, constraints: true},
+		{rng: "<1.3.1-alpha", version: "1.0.0-alpha", contains: false, constraints: true},
+		{rng: "1.2.3-alpha - 3", version: "1.2.3-beta.1", contains: true, constraints: false},
+	}
+	for _, tc := range knownDeviations {
+		t.Run("known deviation "+tc.rng+" "+tc.version, func(t *testing.T) {
+			require.Regexp(t, knownDeviationRegexp, tc.rng)
+			constraint, err := semver.NewConstraint(tc.rng)
+			require.NoError(t, err)
+			v := semver.MustParse(tc.version)
+
+			assert.Equal(t, tc.contains, mustParseRange(t, testRange(tc.rng)).Contains(v))
+			assert.Equal(t, tc.constraints, constraint.Check(v))
+		})
+	}
+}
+
+func TestRange_IsSubsetOf(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "~1.2.3", b: "^1.0.0", expected: true},
+		{a: "^1.0.0", b: "~1.2.3", expected: false},
+		{a: "1.x", b: "<1.5.0 || >=1.5.0 <2", expected: true},
+		{a: "1.x", b: "<1.5.0 || >1.5.0 <2", expected: false},
+		{a: "1.2.3", b: ">=1.2.3 <=1.2.3", expected: true},
+		{a: ">=1.0.0", b: "*", expected: true},
+		{a: "*", b: ">=0.0.0", expected: false},
+		{a: ">=2.0.0 <1.0.0", b: "1.2.3", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+" in "+tc.b, func(t *testing.T) {
+			a, err := semverutil.ParseRange(tc.a)
+			require.NoError(t, err)
+			b, err := semverutil.ParseRange(tc.b)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, a.IsSubsetOf(b))
+		})
+	}
+}
+
+func TestRange_MinVersion(t *testing.T) {
+	testCases := []struct {
+		rng, expected string
+	}{
+		{rng: "*", expected: "0.0.0"},
+		{rng: "^1.2", expected: "1.2.0"},
+		{rng: ">1.2.3", expected: "1.2.4"},
+		{rng: ">1.2.3-beta.1", expected: "1.2.3-beta.1.0"},
+		{rng: ">=2 || 1.x", expected: "1.0.0"},
+		{rng: ">1.2.3 <1.2.4"},
+		{rng: ">=2.0.0 <1.0.0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rng, func(t *testing.T) {
+			rng, err := semverutil.ParseRange(tc.rng)
+			require.NoError(t, err)
+
+			minVersion := rng.MinVersion()
+			if tc.expected == "" {
+				assert.Nil(t, minVersion)
+			} else {
+				require.NotNil(t, minVersion)
+				assert.Equal(t, tc.expected, minVersion.String())
+			}
+		})
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	testCases := []struct {
+		s, expected, expectedErr string
+	}{
+		{s: "v1.2", expected: "1.2.0"},
+		{s: "1.2.3.4", expected: "1.2.3"},
+		{s: "release-42", expected: "42.0.0"},
+		{s: "=1.2.3-beta+build", expected: "1.2.3"},
+		{s: "12345678901234567.1", expected: "1.0.0"},
+		{s: "latest", expectedErr: `coerce "latest": no version found`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			v, err := semverutil.Coerce(tc.s)
+
+			if tc.expectedErr != "" {
+				assert.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, v.String())
+		})
+	}
+}
+
+func TestSimplifyRange(t *testing.T) {
+	published := []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0", "2.0.0-beta.1", "2.0.0", "2.1.0", "3.0.0", "latest"}
+
+	testCases := []struct {
+		name     string
+		rng      string
+		versions []string
+		expected string
+	}{
+		{name: "contiguous versions", rng: "^1.2.0 || ^1.3.0 || ^1.4.0", versions: published, expected: "1.2.0 - 1.4.0"},
+		{name: "all versions", rng: "^1.0.0 || ^2.0.0 || ^3.0.0", versions: published[:5], expected: "*"},
+		{name: "lowest versions", rng: "1.0.0 || 1.1.0 || 1.2.0", versions: published, expected: "<=1.2.0"},
+		{name: "excluded prerelease", rng: "^1.0.0 || ^2.0.0 || ^3.0.0", versions: published, expected: "<=1.4.0 || >=2.0.0"},
+		{name: "included prerelease", rng: "^2.0.0-beta.1 || ^3.0.0 || ^3.0.0", versions: published, expected: ">=2.0.0-beta.1"},
+		{name: "no versions", rng: "^4.0.0 || ^5.0.0", versions: published, expected: "<0.0.0-0"},
+		{name: "shortest range", rng: "^1.2.0", versions: published, expected: "^1.2.0"},
+		{
+			name:     "prerelease excluded by the simplified range",
+			rng:      ">=1.0.0-alpha <1.0.0 || 0.9.0 || 1.0.0 || 1.1.0",
+			versions: []string{"0.9.0", "1.0.0-alpha", "1.0.0-beta", "1.0.0", "1.1.0", "2.0.0"},
+			expected: ">=1.0.0-alpha <1.0.0 || 0.9.0 || 1.0.0 || 1.1.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			simplified, err := semverutil.SimplifyRange(tc.rng, tc.versions)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, simplified)
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// operatorSpaceRegexp matches the spaces between a comparison operator and its version, like in ">= 1.2.3".
+var operatorSpaceRegexp = regexp.MustCompile(`(>=|<=|>|<|=|~>|~|\^)\s+`)
+
+type (
+	// Range is the set of versions matched by an NPM version range, as a union of intervals.
+	// Prerelease versions are ordered like any other version when intersecting ranges, the NPM rule
+	// excluding them from ranges without a prerelease comparator on the same tuple is only applied by [Range.Contains].
+	Range []interval
+
+	// interval is a contiguous set of versions between two bounds.
+	interval struct {
+		lower, upper bound
+	}
+
+	// bound is an interval bound, unbounded when its version is nil.
+	bound struct {
+		version   *semver.Version
+		inclusive bool
+	}
+
+	// partialVersion is a version with optional minor and patch numbers, like "1.x" or "1.2".
+	partialVersion struct {
+		major, minor, patch uint64
+		// parts is the number of numeric parts, from 0 for "*" to 3 for a full version.
+		parts      int
+		prerelease string
+	}
+)
+
+// ParseRange parses an NPM version range, like "^1.2.0 || >=2.1.0 <3", into a [Range].
+func ParseRange(s string) (Range, error) {
+	var r Range
+	for set := range strings.SplitSeq(s, "||") {
+		in, err := parseComparatorSet(set)
+		if err != nil {
+			return nil, fmt.Errorf("range %s: %w", s, err)
+		}
+		if !in.empty() {
+			r = append(r, in)
+		}
+	}
+	if r == nil {
+		r = Range{}
+	}
+	return r, nil
+}
+
+// Intersects reports whether at least one version is matched by both ranges.
+func (r Range) Intersects(other Range) bool {
+	for _, a := range r {
+		for _, b := range other {
+			if !a.intersect(b).empty() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String returns the normalized form of the range, with the comparator sets of its intervals, like ">=1.2.0 <2.0.0"
+// for "^1.2". A range not matching any version is normalized to "<0.0.0-0".
+func (r Range) String() string {
+	if len(r) == 0 {
+		return "<0.0.0-0"
+	}
+
+	sets := make([]string, 0, len(r))
+	for _, in := range r {
+		sets = append(sets, in.String())
+	}
+	return strings.Join(sets, " || ")
+}
+
+// Contains reports whether the version is matched by the range. Like NPM, a prerelease version is only matched
+// by an interval with a prerelease bound on the same major, minor and patch tuple, so "^1.0.0" does not match "2.0.0-beta.1".
+func (r Range) Contains(v *semver.Version) bool {
+	for _, in := range r {
+		if in.contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseComparatorSet parses space separated comparators, or a hyphen range, into the interval they all match.
+func parseComparatorSet(set string) (interval, error) {
+	set = strings.TrimSpace(operatorSpaceRegexp.ReplaceAllString(strings.ReplaceAll(set, ",", " "), "$1"))
+
+	if from, to, ok := strings.Cut(set, " - "); ok {
+		return parseHyphenRange(strings.TrimSpace(from), strings.TrimSpace(to))
+	}
+
+	in := interval{}
+	for comparator := range strings.FieldsSeq(set) {
+		c, err := parseComparator(comparator)
+		if err != nil {
+			return interval{}, err
+		}
+		in = in.intersect(c)
+	}
+	return in, nil
+}
+
+// parseHyphenRange parses the "from - to" inclusive range.
+func parseHyphenRange(from, to string) (interval, error) {
+	pvFrom, err := parsePartialVersion(from)
+	if err != nil {
+		return interval{}, err
+	}
+	pvTo, err := parsePartialVersion(to)
+	if err != nil {
+		return interval{}, err
+	}
+
+	in := interval{}
+	if pvFrom.parts > 0 {
+		in.lower = bound{version: pvFrom.floor(), inclusive: true}
+	}
+	switch {
+	case pvTo.parts == 3:
+		in.upper = bound{version: pvTo.floor(), inclusive: true}
+	case pvTo.parts > 0:
+		in.upper = bound{version: pvTo.next()}
+	}
+	return in, nil
+}
+
+// parseComparator parses a single comparator, like ">=1.2.0", "^1.2" or "1.x", into the interval it matches.
+func parseComparator(comparator string) (interval, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", "~>", ">", "<", "=", "~", "^"} {
+		if rest, ok := strings.CutPrefix(comparator, candidate); ok {
+			op, comparator = candidate, rest
+			break
+		}
+	}
+
+	pv, err := parsePartialVersion(comparator)
+	if err != nil {
+		return interval{}, err
+	}
+
+	if pv.parts == 0 {
+		switch op {
+		case ">", "<":
+			return emptyInterval(), nil
+		default:
+			return interval{}, nil
+		}
+	}
+
+	floor := bound{version: pv.floor(), inclusive: true}
+	switch op {
+	case ">=":
+		return interval{lower: floor}, nil
+	case ">":
+		if pv.parts == 3 {
+			return interval{lower: bound{version: pv.floor()}}, nil
+		}
+		return interval{lower: bound{version: pv.next(), inclusive: true}}, nil
+	case "<":
+		return interval{upper: bound{version: pv.floor()}}, nil
+	case "<=":
+		if pv.parts == 3 {
+			return interval{upper: floor}, nil
+		}
+		return interval{upper: bound{version: pv.next()}}, nil
+	case "~", "~>":
+		if pv.parts == 1 {
+			return interval{lower: floor, upper: bound{version: semver.New(pv.major+1, 0, 0, "", "")}}, nil
+		}
+		return interval{lower: floor, upper: bound{version: semver.New(pv.major, pv.minor+1, 0, "", "")}}, nil
+	case "^":
+		return interval{lower: floor, upper: bound{version: pv.caretCeiling()}}, nil
+	default:
+		if pv.parts == 3 {
+			return interval{lower: floor, upper: floor}, nil
+		}
+		return interval{lower: floor, upper: bound{version: pv.next()}}, nil
+	}
+}
+
+// parsePartialVersion parses a version with optional "x", "X" or "*" wildcards and missing parts.
+func parsePartialVersion(s string) (partialVersion, error) {
+	s = strings.TrimPrefix(s, "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, prerelease, _ := strings.Cut(s, "-")
+
+	var pv partialVersion
+	numbers := []*uint64{&pv.major, &pv.minor, &pv.patch}
+	parts := strings.Split(s, ".")
+	if len(parts) > len(numbers) {
+		return partialVersion{}, fmt.Errorf("invalid version %q", s)
+	}
+
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" || (part == "" && i == 0 && len(parts) == 1) {
+			break
+		}
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return partialVersion{}, fmt.Errorf("invalid version %q: %w", s, err)
+		}
+		*numbers[i] = n
+		pv.parts++
+	}
+
+	if pv.parts == 3 {
+		pv.prerelease = prerelease
+	}
+	return pv, nil
+}
+
+// floor returns the lowest version matched by the partial version.
+func (pv partialVersion) floor() *semver.Version {
+	return semver.New(pv.major, pv.minor, pv.patch, pv.prerelease, "")
+}
+
+// next returns the lowest version above the ones matched by the partial version.
+func (pv partialVersion) next() *semver.Version {
+	switch pv.parts {
+	case 1:
+		return semver.New(pv.major+1, 0, 0, "", "")
+	case 2:
+		return semver.New(pv.major, pv.minor+1, 0, "", "")
+	default:
+		return semver.New(pv.major, pv.minor, pv.patch+1, "", "")
+	}
+}
+
+// caretCeiling returns the exclusive upper bound of a caret range, which allows changes
+// that do not modify the left-most non-zero part of the partial version.
+func (pv partialVersion) caretCeiling() *semver.Version {
+	switch {
+	case pv.major > 0 || pv.parts == 1:
+		return semver.New(pv.major+1, 0, 0, "", "")
+	case pv.minor > 0 || pv.parts == 2:
+		return semver.New(0, pv.minor+1, 0, "", "")
+	default:
+		return semver.New(0, 0, pv.patch+1, "", "")
+	}
+}
+
+// emptyInterval returns an interval that does not contain any version.
+func emptyInterval() interval {
+	zero := semver.New(0, 0, 0, "", "")
+	return interval{lower: bound{version: zero}, upper: bound{version: zero}}
+}
+
+// intersect returns the versions contained in both intervals.
+func (in interval) intersect(other interval) interval {
+	return interval{
+		lower: higherLower(in.lower, other.lower),
+		upper: lowerUpper(in.upper, other.upper),
+	}
+}
+
+// String returns the comparators of the interval bounds, "*" for an unbounded interval.
+func (in interval) String() string {
+	var comparators []string
+	switch {
+	case in.lower.version != nil && in.upper.version != nil && in.lower.inclusive && in.upper.inclusive &&
+		in.lower.version.Equal(in.upper.version):
+		return in.lower.version.String()
+	case in.lower.version != nil && in.lower.inclusive:
+		comparators = append(comparators, ">="+in.lower.version.String())
+	case in.lower.version != nil:
+		comparators = append(comparators, ">"+in.lower.version.String())
+	}
+	switch {
+	case in.upper.version != nil && in.upper.inclusive:
+		comparators = append(comparators, "<="+in.upper.version.String())
+	case in.upper.version != nil:
+		comparators = append(comparators, "<"+in.upper.version.String())
+	}
+
+	if len(comparators) == 0 {
+		return "*"
+	}
+	return strings.Join(comparators, " ")
+}
+
+// contains reports whether the version is between the interval bounds, and is not a prerelease
+// excluded from the interval.
+func (in interval) contains(v *semver.Version) bool {
+	if v.Prerelease() != "" && !in.lower.allowsPrerelease(v) && !in.upper.allowsPrerelease(v) {
+		return false
+	}
+	if in.lower.version != nil {
+		if c := v.Compare(in.lower.version); c < 0 || (c == 0 && !in.lower.inclusive) {
+			return false
+		}
+	}
+	if in.upper.version != nil {
+		if c := v.Compare(in.upper.version); c > 0 || (c == 0 && !in.upper.inclusive) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowsPrerelease reports whether the bound is a prerelease version on the same tuple as the version.
+func (b bound) allowsPrerelease(v *semver.Version) bool {
+	return b.version != nil && b.version.Prerelease() != "" &&
+		b.version.Major() == v.Major() && b.version.Minor() == v.Minor() && b.version.Patch() == v.Patch()
+}
+
+// empty reports whether the interval does not contain any version.
+func (in interval) empty() bool {
+	if in.lower.version == nil || in.upper.version == nil {
+		return false
+	}
+	c := in.lower.version.Compare(in.upper.version)
+	return c > 0 || (c == 0 && !(in.lower.inclusive && in.upper.inclusive))
+}
+
+func higherLower(a, b bound) bound {
+	switch {
+	case a.version == nil:
+		return b
+	case b.version == nil:
+		return a
+	}
+	switch c := a.version.Compare(b.version); {
+	case c > 0:
+		return a
+	case c < 0:
+		return b
+	default:
+		return bound{version: a.version, inclusive: a.inclusive && b.inclusive}
+	}
+}
+
+func lowerUpper(a, b bound) bound {
+	switch {
+	case a.version == nil:
+		return b
+	case b.version == nil:
+		return a
+	}
+	switch c := a.version.Compare(b.version); {
+	case c < 0:
+		return a
+	case c > 0:
+		return b
+	default:
+		return bound{version: a.version, inclusive: a.inclusive && b.inclusive}
+	}
+}
@@ -0,0 +1,145 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	semverutil "github.com/snyk/npmjs-deps-fetcher/internal/semver"
+)
+
+func TestParseRange(t *testing.T) {
+	testCases := []struct {
+		name        string
+		rng         string
+		expectedErr string
+	}{
+		{
+			name:        "dist-tag",
+			rng:         "latest",
+			expectedErr: `range latest: invalid version "latest": strconv.ParseUint: parsing "latest": invalid syntax`,
+		},
+		{
+			name:        "too many version parts",
+			rng:         "^1.2.3.4",
+			expectedErr: `range ^1.2.3.4: invalid version "1.2.3.4"`,
+		},
+		{
+			name: "complex range",
+			rng:  ">= 1.2.0 <2 || 3.x || 4.0.0 - 4.2 || ~5.1.0-beta.1 || *",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := semverutil.ParseRange(tc.rng)
+
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestRange_Intersects(t *testing.T) {
+	testCases := []struct {
+		a, b     string
+		expected bool
+	}{
+		{a: "^4.17.0", b: "4.17.20", expected: true},
+		{a: "~4.16.0", b: "4.17.20", expected: false},
+		{a: "^0.2.3", b: "0.3.0", expected: false},
+		{a: "^0.0.3", b: "0.0.3", expected: true},
+		{a: "^0.0.3", b: "0.0.4", expected: false},
+		{a: "^1.2", b: "1.9.0", expected: true},
+		{a: "~1", b: "1.9.0", expected: true},
+		{a: "1.x", b: "2.0.0", expected: false},
+		{a: "*", b: "2.0.0", expected: true},
+		{a: "", b: "2.0.0", expected: true},
+		{a: ">1.2", b: "1.2.9", expected: false},
+		{a: ">1.2", b: "1.3.0", expected: true},
+		{a: ">1.2.3", b: "<=1.2.3", expected: false},
+		{a: ">=1.2.3", b: "<=1.2.3", expected: true},
+		{a: "<=1.2", b: "1.2.9", expected: true},
+		{a: "<1.2", b: "1.2.0", expected: false},
+		{a: "1.0.0 - 2.1", b: "2.1.5", expected: true},
+		{a: "1.0.0 - 2.1", b: "2.2.0", expected: false},
+		{a: "1.0.0 - 2.1.0", b: ">2.1.0", expected: false},
+		{a: ">=1.0.0 <2.0.0 || >=3.0.0", b: "~2.5.0", expected: false},
+		{a: ">=1.0.0 <2.0.0 || >=3.0.0", b: "^2.5.0 || 3.0.1", expected: true},
+		{a: ">=2.0.0 <1.0.0", b: "*", expected: false},
+		{a: "<*", b: "*", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.a+" and "+tc.b, func(t *testing.T) {
+			a, err := semverutil.ParseRange(tc.a)
+			require.NoError(t, err)
+			b, err := semverutil.ParseRange(tc.b)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, a.Intersects(b))
+			assert.Equal(t, tc.expected, b.Intersects(a))
+		})
+	}
+}
+
+func TestRange_Contains(t *testing.T) {
+	testCases := []struct {
+		rng, version string
+		expected     bool
+	}{
+		{rng: "^4.17.0", version: "4.17.21", expected: true},
+		{rng: "^4.17.0", version: "5.0.0", expected: false},
+		{rng: "~4.16.0", version: "4.16.9", expected: true},
+		{rng: ">1.2.3", version: "1.2.3", expected: false},
+		{rng: "<=1.2", version: "1.2.9", expected: true},
+		{rng: "1.0.0 - 1.2", version: "1.2.99", expected: true},
+		{rng: "1.0.0 - 1.2", version: "1.3.0", expected: false},
+		{rng: "1.x || >=3", version: "2.0.0", expected: false},
+		{rng: "1.x || >=3", version: "3.1.0", expected: true},
+		{rng: "*", version: "0.0.1", expected: true},
+		{rng: "^1.0.0", version: "2.0.0-beta.1", expected: false},
+		{rng: "^1.0.0", version: "1.5.0-beta.1", expected: false},
+		{rng: "^1.5.0-beta.0", version: "1.5.0-beta.1", expected: true},
+		{rng: "^1.5.0-beta.0", version: "1.6.0-beta.1", expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rng+" "+tc.version, func(t *testing.T) {
+			rng, err := semverutil.ParseRange(tc.rng)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, rng.Contains(semver.MustParse(tc.version)))
+		})
+	}
+}
+
+func TestRange_String(t *testing.T) {
+	testCases := []struct {
+		rng, expected string
+	}{
+		{rng: "^1.2", expected: ">=1.2.0 <2.0.0"},
+		{rng: "~0.3.1", expected: ">=0.3.1 <0.4.0"},
+		{rng: "1.2.3", expected: "1.2.3"},
+		{rng: "=v1.2.3", expected: "1.2.3"},
+		{rng: "> 1.2.3 <= 2", expected: ">1.2.3 <3.0.0"},
+		{rng: "1.0.0 - 1.2.3", expected: ">=1.0.0 <=1.2.3"},
+		{rng: "1.x || >=3.0.0-beta.1", expected: ">=1.0.0 <2.0.0 || >=3.0.0-beta.1"},
+		{rng: "", expected: "*"},
+		{rng: ">=2.0.0 <1.0.0", expected: "<0.0.0-0"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.rng, func(t *testing.T) {
+			rng, err := semverutil.ParseRange(tc.rng)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expected, rng.String())
+		})
+	}
+}
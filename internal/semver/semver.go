@@ -4,17 +4,56 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"slices"
 
 	"github.com/Masterminds/semver/v3"
 )
 
+// NoMatchingVersionError indicates that none of the candidate versions satisfies a version constraint.
+type NoMatchingVersionError struct {
+	// Package is the name of the package whose versions are the candidates, when known.
+	Package string
+	// Range is the version range of the package as declared, when known.
+	Range string
+	// Constraint is the version constraint that is not satisfied.
+	Constraint string
+	// Candidates is the number of candidate versions.
+	Candidates int
+}
+
+// Error implements the error interface.
+func (e *NoMatchingVersionError) Error() string {
+	return fmt.Sprintf("no compatible versions found for %s among %d versions", e.Constraint, e.Candidates)
+}
+
 // ResolveHighestVersion resolves the highest version, from the versions list, that satisfies the constraint.
-// If if there is no such version, an error is returned.
+// If if there is no such version, a [NoMatchingVersionError] is returned.
 func ResolveHighestVersion(constraint *semver.Constraints, versions iter.Seq[string]) (string, error) {
-	var (
-		errs    error
-		highest *semver.Version
-	)
+	candidates := 0
+	_, highest, err := SatisfyingRange(constraint, func(yield func(string) bool) {
+		for version := range versions {
+			candidates++
+			if !yield(version) {
+				return
+			}
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if highest == "" {
+		return "", &NoMatchingVersionError{Constraint: constraint.String(), Candidates: candidates}
+	}
+
+	return highest, nil
+}
+
+// SatisfyingRange returns the lowest and the highest versions, from the versions list, that satisfy the constraint,
+// or empty strings if there is no such version. An error is returned if a version is not a valid semver version.
+func SatisfyingRange(constraint *semver.Constraints, versions iter.Seq[string]) (lowest, highest string, err error) {
+	var errs error
+	var vLowest, vHighest *semver.Version
 
 	for version := range versions {
 		v, err := semver.StrictNewVersion(version)
@@ -22,18 +61,111 @@ func ResolveHighestVersion(constraint *semver.Constraints, versions iter.Seq[str
 			errs = errors.Join(errs, fmt.Errorf("version %s: %w", version, err))
 			continue
 		}
-		if constraint.Check(v) && (highest == nil || v.GreaterThan(highest)) {
-			highest = v
+		if !constraint.Check(v) {
+			continue
+		}
+		if vHighest == nil || v.GreaterThan(vHighest) {
+			vHighest = v
+		}
+		if vLowest == nil || v.LessThan(vLowest) {
+			vLowest = v
+		}
+	}
+
+	if errs != nil {
+		return "", "", errs
+	}
+
+	if vHighest == nil {
+		return "", "", nil
+	}
+
+	return vLowest.String(), vHighest.String(), nil
+}
+
+// CompareVersions compares two versions by semver precedence, returning -1, 0 or 1 when the first one
+// is lower, equal or higher than the second one.
+func CompareVersions(a, b string) (int, error) {
+	va, err := semver.StrictNewVersion(a)
+	if err != nil {
+		return 0, fmt.Errorf("version %s: %w", a, err)
+	}
+	vb, err := semver.StrictNewVersion(b)
+	if err != nil {
+		return 0, fmt.Errorf("version %s: %w", b, err)
+	}
+	return va.Compare(vb), nil
+}
+
+// SortVersions sorts the versions in place by increasing semver precedence.
+// An error is returned, and the versions are left unchanged, if a version is not a valid semver version.
+func SortVersions(versions []string) error {
+	var errs error
+	parsed := make([]*semver.Version, len(versions))
+	for i, version := range versions {
+		v, err := semver.StrictNewVersion(version)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("version %s: %w", version, err))
+			continue
 		}
+		parsed[i] = v
 	}
 
 	if errs != nil {
-		return "", errs
+		return errs
 	}
 
-	if highest == nil {
-		return "", errors.New("no compatible versions found")
+	slices.SortStableFunc(parsed, func(a, b *semver.Version) int { return a.Compare(b) })
+	for i, v := range parsed {
+		versions[i] = v.Original()
+	}
+	return nil
+}
+
+// Gap is the most significant difference between two versions.
+type Gap string
+
+const (
+	// GapNone indicates equal versions.
+	GapNone Gap = ""
+	// GapMajor indicates versions with different major numbers.
+	GapMajor Gap = "major"
+	// GapMinor indicates versions with different minor numbers.
+	GapMinor Gap = "minor"
+	// GapPatch indicates versions with different patch numbers.
+	GapPatch Gap = "patch"
+	// GapPrerelease indicates versions only differing by their prerelease.
+	GapPrerelease Gap = "prerelease"
+	// GapAhead indicates a from version higher than the to version, like a prerelease ahead of the latest version.
+	GapAhead Gap = "ahead"
+	// GapUnknown indicates versions that cannot be compared, like the ones of git, file or tarball dependencies.
+	GapUnknown Gap = "unknown"
+)
+
+// VersionGap classifies the most significant difference between the from and to versions,
+// or reports a from version ahead of the to version.
+func VersionGap(from, to string) (Gap, error) {
+	vFrom, err := semver.StrictNewVersion(from)
+	if err != nil {
+		return GapNone, fmt.Errorf("version %s: %w", from, err)
+	}
+	vTo, err := semver.StrictNewVersion(to)
+	if err != nil {
+		return GapNone, fmt.Errorf("version %s: %w", to, err)
 	}
 
-	return highest.String(), nil
+	switch {
+	case vFrom.GreaterThan(vTo):
+		return GapAhead, nil
+	case vFrom.Major() != vTo.Major():
+		return GapMajor, nil
+	case vFrom.Minor() != vTo.Minor():
+		return GapMinor, nil
+	case vFrom.Patch() != vTo.Patch():
+		return GapPatch, nil
+	case vFrom.Prerelease() != vTo.Prerelease():
+		return GapPrerelease, nil
+	default:
+		return GapNone, nil
+	}
 }
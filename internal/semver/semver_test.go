@@ -1,6 +1,7 @@
 package semver_test
 
 import (
+	"errors"
 	"slices"
 	"testing"
 
@@ -20,6 +21,7 @@ func TestResolveHighestVersion(t *testing.T) {
 		versions        []string
 		expectedVersion string
 		expectedErr     string
+		expectedNoMatch bool
 	}{
 		{
 			name:        "strict parsing error",
@@ -27,14 +29,16 @@ func TestResolveHighestVersion(t *testing.T) {
 			expectedErr: "version ^1.0.2: Invalid characters in version\nversion 1.x: Invalid Semantic Version",
 		},
 		{
-			name:        "empty version list",
-			versions:    []string{},
-			expectedErr: "no compatible versions found",
+			name:            "empty version list",
+			versions:        []string{},
+			expectedErr:     "no compatible versions found for ^1.0.5 among 0 versions",
+			expectedNoMatch: true,
 		},
 		{
-			name:        "no compatible versions",
-			versions:    []string{"0.0.1", "0.0.2", "1.0.0", "1.0.1"},
-			expectedErr: "no compatible versions found",
+			name:            "no compatible versions",
+			versions:        []string{"0.0.1", "0.0.2", "1.0.0", "1.0.1"},
+			expectedErr:     "no compatible versions found for ^1.0.5 among 4 versions",
+			expectedNoMatch: true,
 		},
 		{
 			name:            "compatible version",
@@ -53,6 +57,121 @@ func TestResolveHighestVersion(t *testing.T) {
 			} else {
 				assert.EqualError(t, err, tc.expectedErr)
 			}
+			var noMatchErr *semverutil.NoMatchingVersionError
+			assert.Equal(t, tc.expectedNoMatch, errors.As(err, &noMatchErr))
+		})
+	}
+}
+
+func TestSatisfyingRange(t *testing.T) {
+	constraint, err := semver.NewConstraint("^1.0.5")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name            string
+		versions        []string
+		expectedLowest  string
+		expectedHighest string
+		expectedErr     string
+	}{
+		{
+			name:        "strict parsing error",
+			versions:    []string{"1.0.5", "v1.0.6"},
+			expectedErr: "version v1.0.6: Invalid characters in version",
+		},
+		{
+			name:     "no compatible versions",
+			versions: []string{"0.0.1", "1.0.4", "2.0.0"},
+		},
+		{
+			name:            "compatible versions",
+			versions:        []string{"2.0.7", "1.0.6", "1.0.1", "1.0.5", "1.2.0-beta.1", "1.1.0"},
+			expectedLowest:  "1.0.5",
+			expectedHighest: "1.1.0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lowest, highest, err := semverutil.SatisfyingRange(constraint, slices.Values(tc.versions))
+
+			assert.Equal(t, tc.expectedLowest, lowest)
+			assert.Equal(t, tc.expectedHighest, highest)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		name        string
+		a, b        string
+		expected    int
+		expectedErr string
+	}{
+		{name: "invalid version", a: "1.x", b: "1.0.0", expectedErr: "version 1.x: Invalid Semantic Version"},
+		{name: "lower version", a: "1.2.3", b: "1.10.0", expected: -1},
+		{name: "equal versions", a: "1.2.3+build.1", b: "1.2.3", expected: 0},
+		{name: "prerelease version", a: "1.2.3", b: "1.2.3-rc.1", expected: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := semverutil.CompareVersions(tc.a, tc.b)
+
+			assert.Equal(t, tc.expected, c)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	versions := []string{"1.10.0", "1.2.0", "1.2.0-rc.1", "0.9.0", "1.2.0-beta.2"}
+	require.NoError(t, semverutil.SortVersions(versions))
+	assert.Equal(t, []string{"0.9.0", "1.2.0-beta.2", "1.2.0-rc.1", "1.2.0", "1.10.0"}, versions)
+
+	invalid := []string{"1.10.0", "latest", "1.2.0"}
+	require.EqualError(t, semverutil.SortVersions(invalid), "version latest: Invalid Semantic Version")
+	assert.Equal(t, []string{"1.10.0", "latest", "1.2.0"}, invalid)
+}
+
+func TestVersionGap(t *testing.T) {
+	testCases := []struct {
+		name        string
+		from, to    string
+		expectedGap semverutil.Gap
+		expectedErr string
+	}{
+		{name: "invalid from version", from: "1.x", to: "1.0.0", expectedErr: "version 1.x: Invalid Semantic Version"},
+		{name: "invalid to version", from: "1.0.0", to: "latest", expectedErr: "version latest: Invalid Semantic Version"},
+		{name: "equal versions", from: "1.2.3", to: "1.2.3", expectedGap: semverutil.GapNone},
+		{name: "major gap", from: "1.2.3", to: "2.0.0", expectedGap: semverutil.GapMajor},
+		{name: "minor gap", from: "1.2.3", to: "1.3.0", expectedGap: semverutil.GapMinor},
+		{name: "patch gap", from: "1.2.3", to: "1.2.4", expectedGap: semverutil.GapPatch},
+		{name: "prerelease gap", from: "1.2.3-beta.1", to: "1.2.3", expectedGap: semverutil.GapPrerelease},
+		{name: "prerelease ahead", from: "2.0.0-rc.1", to: "1.9.0", expectedGap: semverutil.GapAhead},
+		{name: "yanked latest", from: "1.2.4", to: "1.2.3", expectedGap: semverutil.GapAhead},
+		{name: "equal versions but build", from: "1.2.3+build.2", to: "1.2.3", expectedGap: semverutil.GapNone},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gap, err := semverutil.VersionGap(tc.from, tc.to)
+
+			assert.Equal(t, tc.expectedGap, gap)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tc.expectedErr)
+			}
 		})
 	}
 }
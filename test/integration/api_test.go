@@ -45,7 +45,17 @@ func TestHealthcheckEndpoint(t *testing.T) {
 	require.NoError(t, err)
 	defer resp.Body.Close()
 
-	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var content struct {
+		Status   string `json:"status"`
+		Registry struct {
+			State string `json:"state"`
+		} `json:"registry"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&content))
+	assert.Equal(t, "ok", content.Status)
+	assert.Equal(t, "closed", content.Registry.State)
 }
 
 func TestPackageNameVersionEndpoint(t *testing.T) {